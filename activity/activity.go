@@ -47,6 +47,10 @@ type (
 var ErrResultPending = internal.ErrActivityResultPending
 
 // Register - calls RegisterWithOptions with default registration options.
+// A pointer to a struct can be registered as well, all its exported methods which comply with the activity format
+// are registered as activities named after the method. workflow.ExecuteActivity accepts a method value, like
+// a.Charge, and runs the activity of the registered instance. As a method value is resolved by its struct type, only
+// one instance of a struct type can be registered.
 func Register(activityFunc interface{}) {
 	internal.RegisterActivity(activityFunc)
 }
@@ -56,6 +60,7 @@ func Register(activityFunc interface{}) {
 // external name is required. This can be used as
 //  client.Register(barActivity, RegisterOptions{})
 //  client.Register(barActivity, RegisterOptions{Name: "barExternal"})
+//  client.Register(&barActivities{}, RegisterOptions{Prefix: "bar_"})
 // An activity takes a context and input and returns a (result, error) or just error.
 // Examples:
 //	func sampleActivity(ctx context.Context, input []byte) (result []byte, err error)
@@ -25,6 +25,7 @@ package client
 
 import (
 	"context"
+	"io"
 
 	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
 	s "go.uber.org/cadence/.gen/go/shared"
@@ -37,6 +38,10 @@ import (
 // stack of the workflow. The result will be a string encoded in the encoded.Value.
 const QueryTypeStackTrace string = internal.QueryTypeStackTrace
 
+// QueryTypeQueryTypes is the build in query type for Client.QueryWorkflow() call. Use this query type to get the query
+// types supported by the workflow, the result is a []QueryTypeInfo ordered by query type.
+const QueryTypeQueryTypes string = internal.QueryTypeQueryTypes
+
 type (
 	// Options are optional parameters for Client creation.
 	Options = internal.ClientOptions
@@ -50,9 +55,129 @@ type (
 	// WorkflowRun represents a started non child workflow
 	WorkflowRun = internal.WorkflowRun
 
+	// HistoryFormat is the encoding of the events written by a HistoryWriter.
+	HistoryFormat = internal.HistoryFormat
+
+	// HistoryHeader is written at the beginning of a history by a HistoryWriter, to identify the workflow
+	// execution the history belongs to.
+	HistoryHeader = internal.HistoryHeader
+
+	// HistoryWriterOptions are optional parameters of a HistoryWriter.
+	HistoryWriterOptions = internal.HistoryWriterOptions
+
+	// HistoryWriter writes the events of a workflow history to an io.Writer, one at a time so that histories which
+	// do not fit in memory can be written. The written history is read back with a HistoryReader.
+	HistoryWriter = internal.HistoryWriter
+
+	// HistoryReader reads a history written by a HistoryWriter. The format of the events and the compression are
+	// detected from the history. HistoryReader is a HistoryEventIterator which reads the events one at a time.
+	HistoryReader = internal.HistoryReader
+
+	// WorkflowRunGetOptions are the options of WorkflowRun.GetWithOptions.
+	WorkflowRunGetOptions = internal.WorkflowRunGetOptions
+
+	// WorkflowContinuedAsNewError returned by WorkflowRun.GetWithOptions when the run continued as new and following
+	// the runs is disabled.
+	WorkflowContinuedAsNewError = internal.WorkflowContinuedAsNewError
+
+	// WorkflowExecutionIterator is a iterator which can return workflow executions
+	WorkflowExecutionIterator = internal.WorkflowExecutionIterator
+
+	// ListWorkflowsFilter selects the workflow executions returned by Client.ListWorkflows.
+	ListWorkflowsFilter = internal.ListWorkflowsFilter
+
+	// WorkflowExecutionInfo describes a workflow execution.
+	WorkflowExecutionInfo = internal.WorkflowExecutionInfo
+
+	// WorkflowExecutionDescription describes a workflow execution, see Client.DescribeWorkflow.
+	WorkflowExecutionDescription = internal.WorkflowExecutionDescription
+
+	// DescribeWorkflowOptions configuration parameters for describing a workflow execution.
+	DescribeWorkflowOptions = internal.DescribeWorkflowOptions
+
+	// PendingActivityInfo describes an activity which is not closed yet. The attempt of the activity is not exposed by
+	// the Cadence service, it is only known by the activity itself through ActivityInfo.Attempt.
+	PendingActivityInfo = internal.PendingActivityInfo
+
+	// PendingActivityState is the state of a pending activity.
+	PendingActivityState = internal.PendingActivityState
+
+	// PendingChildWorkflowInfo describes a child workflow which is not closed yet.
+	PendingChildWorkflowInfo = internal.PendingChildWorkflowInfo
+
+	// WorkflowExecutionStatus is the status of a workflow execution.
+	WorkflowExecutionStatus = internal.WorkflowExecutionStatus
+
+	// BatchOptions configuration parameters for running a batch operation.
+	BatchOptions = internal.BatchOptions
+
+	// BatchOperation is the handle of a batch operation running in the background.
+	BatchOperation = internal.BatchOperation
+
+	// BatchProgress is the progress of a batch operation.
+	BatchProgress = internal.BatchProgress
+
+	// BatchFailure is a workflow execution which a batch operation failed to process.
+	BatchFailure = internal.BatchFailure
+
+	// DomainIterator is a iterator which can return domains
+	DomainIterator = internal.DomainIterator
+
+	// DomainDescription describes a domain.
+	DomainDescription = internal.DomainDescription
+
+	// DomainInfo is the information of a domain.
+	DomainInfo = internal.DomainInfo
+
+	// DomainConfig is the configuration of a domain.
+	DomainConfig = internal.DomainConfig
+
+	// DomainReplicationConfig is the replication configuration of a domain.
+	DomainReplicationConfig = internal.DomainReplicationConfig
+
+	// DomainUpdate is the set of changes applied by DomainClient.UpdateDomain. Create it with NewDomainUpdate and
+	// chain the setters of the changes to make, the other properties of the domain are left unchanged:
+	//	update := NewDomainUpdate().SetOwnerEmail("payments@example.com").SetRetentionPeriodInDays(7)
+	DomainUpdate = internal.DomainUpdate
+
+	// DomainStatus is the status of a domain.
+	DomainStatus = internal.DomainStatus
+
 	// WorkflowIDReusePolicy defines workflow ID reuse behavior.
 	WorkflowIDReusePolicy = internal.WorkflowIDReusePolicy
 
+	// QueryWorkflowWithOptionsRequest is the request to Client.QueryWorkflowWithOptions.
+	QueryWorkflowWithOptionsRequest = internal.QueryWorkflowWithOptionsRequest
+
+	// QueryWorkflowWithOptionsResponse is the response of Client.QueryWorkflowWithOptions.
+	QueryWorkflowWithOptionsResponse = internal.QueryWorkflowWithOptionsResponse
+
+	// QueryRejected describes why a query was rejected.
+	QueryRejected = internal.QueryRejected
+
+	// QueryTypeInfo describes a query type supported by a workflow, it is the element of the QueryTypeQueryTypes result.
+	QueryTypeInfo = internal.QueryTypeInfo
+
+	// QueryRejectCondition selects the executions a query is rejected for.
+	QueryRejectCondition = internal.QueryRejectCondition
+
+	// QueryConsistencyLevel selects the consistency of a query result.
+	QueryConsistencyLevel = internal.QueryConsistencyLevel
+
+	// QueryFailedError returned by Client.QueryWorkflowWithOptions when the query handler of the workflow failed.
+	// When the handler returned a *CustomError, its reason and details are available on this error.
+	QueryFailedError = internal.QueryFailedError
+
+	// Interceptor is used to wrap the calls made through a Client. See Options.Interceptors.
+	Interceptor = internal.ClientInterceptor
+
+	// OutboundInterceptor intercepts the calls made through a Client before their arguments are encoded.
+	OutboundInterceptor = internal.ClientOutboundInterceptor
+
+	// OutboundInterceptorBase is an OutboundInterceptor that forwards all calls to Next. Embed it to implement only
+	// the methods of interest.
+	OutboundInterceptorBase = internal.ClientOutboundInterceptorBase
+
 	// Client is the client for starting and getting information about a workflow executions as well as
 	// completing activities asynchronously.
 	Client interface {
@@ -81,11 +206,13 @@ type (
 		//	- WorkflowExecutionAlreadyStartedError
 		//	- InternalServiceError
 		//
-		// WorkflowRun has 2 methods:
+		// WorkflowRun has these methods:
 		//  - GetRunID() string: which return the first started workflow run ID (please see below)
 		//  - Get(ctx context.Context, valuePtr interface{}) error: which will fill the workflow
 		//    execution result to valuePtr, if workflow execution is a success, or return corresponding
 		//    error. This is a blocking API.
+		// It also has methods to signal, cancel, terminate, query and describe the workflow, and to read its history,
+		// see WorkflowRun.
 		// NOTE: if the started workflow return ContinueAsNewError during the workflow execution, the
 		// return result of GetRunID() will be the started workflow run ID, not the new run ID caused by ContinueAsNewError,
 		// however, Get(ctx context.Context, valuePtr interface{}) will return result from the run which did not return ContinueAsNewError.
@@ -95,6 +222,12 @@ type (
 		// NOTE: DO NOT USE THIS API INSIDE A WORKFLOW, USE workflow.ExecuteChildWorkflow instead
 		ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error)
 
+		// GetWorkflow returns the WorkflowRun of an existing workflow execution, to get its result, signal, cancel,
+		// terminate, query or describe it, or read its history. The workflow execution is not checked, the methods of
+		// the WorkflowRun return its errors instead.
+		// - runID can be default(empty string). if empty string then the WorkflowRun acts on the current run of the workflow.
+		GetWorkflow(ctx context.Context, workflowID string, runID string) WorkflowRun
+
 		// SignalWorkflow sends a signals to a workflow in execution
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
@@ -116,6 +249,21 @@ type (
 		SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
 			options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*workflow.Execution, error)
 
+		// SignalWithStartWorkflowRun sends a signal to a running workflow. If the workflow is not running or not found,
+		// it starts the workflow and then sends the signal in transaction. It returns the WorkflowRun of the signaled
+		// execution, to wait for its result.
+		// - workflowID, signalName, signalArg are same as SignalWorkflow's parameters, workflowID defaults to options.ID
+		// - workflow, workflowArgs are same as StartWorkflow's parameters
+		// - options.WorkflowIDReusePolicy is used when the workflow is started
+		// - options.ID must be empty or match workflowID
+		// - options.RequestID makes retried calls idempotent
+		// The errors it can return:
+		//  - EntityNotExistsError, if domain does not exist
+		//  - BadRequestError
+		//	- InternalServiceError
+		SignalWithStartWorkflowRun(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+			options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (WorkflowRun, error)
+
 		// CancelWorkflow cancels a workflow in execution
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
@@ -213,6 +361,46 @@ type (
 		//  - EntityNotExistError
 		ListOpenWorkflow(ctx context.Context, request *s.ListOpenWorkflowExecutionsRequest) (*s.ListOpenWorkflowExecutionsResponse, error)
 
+		// ListWorkflows lists the open or closed workflow executions matching the filter. The returned iterator pages
+		// through the results transparently; the iteration stops with the context error once ctx is done.
+		// Example:-
+		//	iter := ListWorkflows(ctx, ListWorkflowsFilter{Closed: true, WorkflowType: "MyWorkflow"})
+		//	for iter.HasNext() {
+		//		info, err := iter.Next()
+		//		if err != nil {
+		//			return err
+		//		}
+		//		...
+		//	}
+		// The errors the iterator can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		ListWorkflows(ctx context.Context, filter ListWorkflowsFilter) WorkflowExecutionIterator
+
+		// BatchCancel requests cancellation of every open workflow execution matching the filter. The batch runs in the
+		// background until all executions are processed or ctx is done; use the returned BatchOperation to follow its
+		// progress and to get a checkpoint to resume it from. Executions which closed before they were processed or
+		// whose cancellation was already requested are reported as skipped.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchCancel(ctx context.Context, filter ListWorkflowsFilter, options BatchOptions) (BatchOperation, error)
+
+		// BatchTerminate terminates every open workflow execution matching the filter with the given reason and
+		// details. See BatchCancel for how the batch runs.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchTerminate(ctx context.Context, filter ListWorkflowsFilter, reason string, details []byte, options BatchOptions) (BatchOperation, error)
+
+		// BatchSignal sends the signal to every open workflow execution matching the filter. See BatchCancel for how
+		// the batch runs.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchSignal(ctx context.Context, filter ListWorkflowsFilter, signalName string, arg interface{}, options BatchOptions) (BatchOperation, error)
+
 		// QueryWorkflow queries a given workflow's last execution and returns the query result synchronously. Parameter workflowID
 		// and queryType are required, other parameters are optional. The workflowID and runID (optional) identify the
 		// target workflow execution that this query will be send to. If runID is not specified (empty string), server will
@@ -233,6 +421,20 @@ type (
 		//  - QueryFailError
 		QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error)
 
+		// QueryWorkflowWithOptions queries a given workflow execution like QueryWorkflow, with options to reject the
+		// query depending on the state of the execution and to choose the consistency of the query result.
+		// See QueryWorkflowWithOptionsRequest for the options.
+		// - request.WorkflowID and request.QueryType are required.
+		// The status of the execution is returned in QueryWorkflowWithOptionsResponse.Status, a rejected query returns
+		// it in QueryWorkflowWithOptionsResponse.QueryRejected too and no error. The query goes through the Interceptors
+		// of the client as a QueryWorkflow call.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - *QueryFailedError
+		QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error)
+
 		// DescribeWorkflowExecution returns information about the specified workflow execution.
 		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
 		//
@@ -242,6 +444,26 @@ type (
 		//  - EntityNotExistError
 		DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*s.DescribeWorkflowExecutionResponse, error)
 
+		// DescribeWorkflow returns the description of the specified workflow execution with typed fields. The pending
+		// child workflows are not returned by the Cadence service, use DescribeWorkflowWithOptions to read them from the
+		// history of the execution. The Cadence service does not expose the attempt of a pending activity.
+		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		DescribeWorkflow(ctx context.Context, workflowID, runID string) (*WorkflowExecutionDescription, error)
+
+		// DescribeWorkflowWithOptions returns the description of the specified workflow execution like DescribeWorkflow.
+		// With options.ReadHistory the pending child workflows of a running execution are read from its history, which
+		// downloads the whole history.
+		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		DescribeWorkflowWithOptions(ctx context.Context, workflowID, runID string, options DescribeWorkflowOptions) (*WorkflowExecutionDescription, error)
+
 		// DescribeTaskList returns information about the target tasklist, right now this API returns the
 		// pollers which polled this tasklist in last few minutes.
 		// The errors it can return:
@@ -277,6 +499,42 @@ type (
 		//	- BadRequestError
 		//	- InternalServiceError
 		Update(ctx context.Context, request *s.UpdateDomainRequest) error
+
+		// List returns an iterator over all the domains, which pages through them transparently.
+		// The errors the iterator can return:
+		//	- BadRequestError
+		//	- InternalServiceError
+		List(ctx context.Context) DomainIterator
+
+		// Deprecate a domain. A deprecated domain cannot be used to start new workflow executions.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		Deprecate(ctx context.Context, name string) error
+
+		// RegisterDomain registers a domain with cadence server, like Register does without thrift types. The status of
+		// the domain info is ignored.
+		// The errors it can throw:
+		//	- DomainAlreadyExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		RegisterDomain(ctx context.Context, info DomainInfo, config DomainConfig, replicationConfig DomainReplicationConfig) error
+
+		// DescribeDomain describes a domain, like Describe does without thrift types.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		DescribeDomain(ctx context.Context, name string) (*DomainDescription, error)
+
+		// UpdateDomain applies the changes of the update to a domain, like Update does without thrift types, and
+		// returns the updated domain.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		UpdateDomain(ctx context.Context, name string, update *DomainUpdate) (*DomainDescription, error)
 	}
 )
 
@@ -294,11 +552,119 @@ const (
 	WorkflowIDReusePolicyRejectDuplicate WorkflowIDReusePolicy = internal.WorkflowIDReusePolicyRejectDuplicate
 )
 
+const (
+	// DomainStatusRegistered is the status of a registered domain.
+	DomainStatusRegistered DomainStatus = internal.DomainStatusRegistered
+
+	// DomainStatusDeprecated is the status of a deprecated domain.
+	DomainStatusDeprecated DomainStatus = internal.DomainStatusDeprecated
+
+	// DomainStatusDeleted is the status of a deleted domain.
+	DomainStatusDeleted DomainStatus = internal.DomainStatusDeleted
+
+	// DomainStatusUnknown is the status of a domain whose status is not known by this client.
+	DomainStatusUnknown DomainStatus = internal.DomainStatusUnknown
+)
+
+const (
+	// WorkflowExecutionStatusRunning is the status of an open workflow execution.
+	WorkflowExecutionStatusRunning WorkflowExecutionStatus = internal.WorkflowExecutionStatusRunning
+
+	// WorkflowExecutionStatusCompleted is the status of a workflow execution which completed successfully.
+	WorkflowExecutionStatusCompleted WorkflowExecutionStatus = internal.WorkflowExecutionStatusCompleted
+
+	// WorkflowExecutionStatusFailed is the status of a workflow execution which failed.
+	WorkflowExecutionStatusFailed WorkflowExecutionStatus = internal.WorkflowExecutionStatusFailed
+
+	// WorkflowExecutionStatusCanceled is the status of a workflow execution which was canceled.
+	WorkflowExecutionStatusCanceled WorkflowExecutionStatus = internal.WorkflowExecutionStatusCanceled
+
+	// WorkflowExecutionStatusTerminated is the status of a workflow execution which was terminated.
+	WorkflowExecutionStatusTerminated WorkflowExecutionStatus = internal.WorkflowExecutionStatusTerminated
+
+	// WorkflowExecutionStatusContinuedAsNew is the status of a workflow execution which continued as new.
+	WorkflowExecutionStatusContinuedAsNew WorkflowExecutionStatus = internal.WorkflowExecutionStatusContinuedAsNew
+
+	// WorkflowExecutionStatusTimedOut is the status of a workflow execution which timed out.
+	WorkflowExecutionStatusTimedOut WorkflowExecutionStatus = internal.WorkflowExecutionStatusTimedOut
+
+	// WorkflowExecutionStatusUnknown is the status of a closed workflow execution whose close status is not known
+	// by this client.
+	WorkflowExecutionStatusUnknown WorkflowExecutionStatus = internal.WorkflowExecutionStatusUnknown
+)
+
+const (
+	// HistoryFormatJSON writes each event as a line of JSON after the header line. It is not the JSON array of
+	// events written by the cli.
+	HistoryFormatJSON HistoryFormat = internal.HistoryFormatJSON
+
+	// HistoryFormatThriftBinary writes each event in the thrift binary encoding used by the cadence service, which is
+	// more compact and faster to read and write than JSON.
+	HistoryFormatThriftBinary HistoryFormat = internal.HistoryFormatThriftBinary
+)
+
+const (
+	// PendingActivityStateScheduled is the state of an activity which is not started yet.
+	PendingActivityStateScheduled PendingActivityState = internal.PendingActivityStateScheduled
+
+	// PendingActivityStateStarted is the state of a running activity.
+	PendingActivityStateStarted PendingActivityState = internal.PendingActivityStateStarted
+
+	// PendingActivityStateCancelRequested is the state of an activity whose cancellation was requested.
+	PendingActivityStateCancelRequested PendingActivityState = internal.PendingActivityStateCancelRequested
+)
+
+const (
+	// QueryRejectConditionNone runs the query whatever the status of the execution is.
+	QueryRejectConditionNone QueryRejectCondition = internal.QueryRejectConditionNone
+
+	// QueryRejectConditionNotOpen rejects the query if the execution is closed.
+	QueryRejectConditionNotOpen QueryRejectCondition = internal.QueryRejectConditionNotOpen
+
+	// QueryRejectConditionNotCompletedCleanly rejects the query if the execution is closed with another status than
+	// WorkflowExecutionStatusCompleted.
+	QueryRejectConditionNotCompletedCleanly QueryRejectCondition = internal.QueryRejectConditionNotCompletedCleanly
+)
+
+const (
+	// QueryConsistencyLevelEventual runs the query against the state of the workflow when the query is received, events
+	// not processed yet by the workflow, such as a signal, are not reflected in the result.
+	QueryConsistencyLevelEventual QueryConsistencyLevel = internal.QueryConsistencyLevelEventual
+
+	// QueryConsistencyLevelStrong waits for the workflow to process the events recorded before the query is sent,
+	// so that the result reflects them. The wait is bounded by the context of the query and by 30 seconds, the query
+	// fails with the context error when the workflow does not process the events in time.
+	QueryConsistencyLevelStrong QueryConsistencyLevel = internal.QueryConsistencyLevelStrong
+)
+
 // NewClient creates an instance of a workflow client
 func NewClient(service workflowserviceclient.Interface, domain string, options *Options) Client {
 	return internal.NewClient(service, domain, options)
 }
 
+// NewHistoryWriter creates a HistoryWriter which writes the header right away. Close must be called once all the
+// events are written, it does not close w. To export a history:
+//	iter := c.GetWorkflowHistory(ctx, workflowID, runID, false, shared.HistoryEventFilterTypeAllEvent)
+//	hw, err := client.NewHistoryWriter(file, client.HistoryHeader{Domain: domain, WorkflowID: workflowID, RunID: runID},
+//		client.HistoryWriterOptions{Format: client.HistoryFormatThriftBinary, Gzip: true})
+//	...
+//	err = hw.WriteHistory(iter)
+//	...
+//	err = hw.Close()
+func NewHistoryWriter(w io.Writer, header HistoryHeader, options HistoryWriterOptions) (*HistoryWriter, error) {
+	return internal.NewHistoryWriter(w, header, options)
+}
+
+// NewHistoryReader creates a HistoryReader which reads the header of the history right away.
+func NewHistoryReader(r io.Reader) (*HistoryReader, error) {
+	return internal.NewHistoryReader(r)
+}
+
+// NewDomainUpdate creates an empty DomainUpdate.
+func NewDomainUpdate() *DomainUpdate {
+	return internal.NewDomainUpdate()
+}
+
 // NewDomainClient creates an instance of a domain client, to manage lifecycle of domains.
 func NewDomainClient(service workflowserviceclient.Interface, options *Options) DomainClient {
 	return internal.NewDomainClient(service, options)
@@ -53,7 +53,12 @@ type (
 
 	// RegisterActivityOptions consists of options for registering an activity
 	RegisterActivityOptions struct {
+		// Name of the activity, only used when a function is registered.
 		Name string
+
+		// Prefix is prepended to the method names when a struct pointer is registered. The activities of the struct
+		// are named <Prefix><MethodName>.
+		Prefix string
 	}
 
 	// ActivityOptions stores all activity-specific parameters that will be stored inside of a context.
@@ -99,9 +104,14 @@ type (
 
 	// LocalActivityOptions stores local activity specific parameters that will be stored inside of a context.
 	LocalActivityOptions struct {
-		// ScheduleToCloseTimeout - The end to end timeout for the local activity.
+		// ScheduleToCloseTimeout - The end to end timeout for each attempt of the local activity.
 		// This field is required.
 		ScheduleToCloseTimeout time.Duration
+
+		// RetryPolicy specify how to retry local activity if error happens. Short backoffs are retried by the worker
+		// in process, longer ones are waited out with a workflow timer so the decision task is not held open.
+		// Optional: default is no retry
+		RetryPolicy *RetryPolicy
 	}
 )
 
@@ -115,6 +125,10 @@ type (
 //	func sampleActivity(arg1 bool) (result int, err error)
 //	func sampleActivity(arg1 bool) (err error)
 // Serialization of all primitive types, structures is supported ... except channels, functions, variadic, unsafe pointer.
+// A pointer to a struct can be registered as well, all its exported methods which comply with the format above are
+// registered as activities named after the method. ExecuteActivity accepts a method value, like a.Charge, and runs
+// the activity of the registered instance. As a method value is resolved by its struct type, only one instance of a
+// struct type can be registered.
 // This method calls panic if activityFunc doesn't comply with the expected format.
 func RegisterActivity(activityFunc interface{}) {
 	RegisterActivityWithOptions(activityFunc, RegisterActivityOptions{})
@@ -125,7 +139,9 @@ func RegisterActivity(activityFunc interface{}) {
 // external name is required. This can be used as
 //  client.RegisterActivity(barActivity, RegisterActivityOptions{})
 //  client.RegisterActivity(barActivity, RegisterActivityOptions{Name: "barExternal"})
-// An activity takes a context and input and returns a (result, error) or just error.
+//  client.RegisterActivity(&barActivities{}, RegisterActivityOptions{Prefix: "bar_"})
+// An activity takes a context and input and returns a (result, error) or just error. See RegisterActivity for the
+// registration of a struct pointer.
 // Examples:
 //	func sampleActivity(ctx context.Context, input []byte) (result []byte, err error)
 //	func sampleActivity(ctx context.Context, arg1 int, arg2 string) (result *customerStruct, err error)
@@ -281,6 +297,12 @@ func WithLocalActivityOptions(ctx Context, options LocalActivityOptions) Context
 	opts := getLocalActivityOptions(ctx1)
 
 	opts.ScheduleToCloseTimeoutSeconds = common.Int32Ceil(options.ScheduleToCloseTimeout.Seconds())
+	opts.RetryPolicy = nil
+	if options.RetryPolicy != nil {
+		// copy the policy so defaults filled in on validation do not leak back into the caller's options.
+		retryPolicy := *options.RetryPolicy
+		opts.RetryPolicy = &retryPolicy
+	}
 	return ctx1
 }
 
@@ -27,9 +27,11 @@ import (
 
 	"go.uber.org/cadence/encoded"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/uber-go/tally"
 	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
 	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
 	"go.uber.org/cadence/internal/common/metrics"
 )
 
@@ -37,6 +39,10 @@ import (
 // stack of the workflow. The result will be a string encoded in the EncodedValue.
 const QueryTypeStackTrace string = "__stack_trace"
 
+// QueryTypeQueryTypes is the build in query type for Client.QueryWorkflow() call. Use this query type to get the query
+// types supported by the workflow, the result is a []QueryTypeInfo ordered by query type.
+const QueryTypeQueryTypes string = "__query_types"
+
 type (
 	// Client is the client for starting and getting information about a workflow executions as well as
 	// completing activities asynchronously.
@@ -71,12 +77,14 @@ type (
 		// The current timeout resolution implementation is in seconds and uses math.Ceil(d.Seconds()) as the duration. But is
 		// subjected to change in the future.
 		//
-		// WorkflowRun has three methods:
+		// WorkflowRun has these methods:
 		//  - GetID() string: which return workflow ID (which is same as StartWorkflowOptions.ID if provided)
 		//  - GetRunID() string: which return the first started workflow run ID (please see below)
 		//  - Get(ctx context.Context, valuePtr interface{}) error: which will fill the workflow
 		//    execution result to valuePtr, if workflow execution is a success, or return corresponding
 		//    error. This is a blocking API.
+		// It also has methods to signal, cancel, terminate, query and describe the workflow, and to read its history,
+		// see WorkflowRun.
 		// NOTE: if the started workflow return ContinueAsNewError during the workflow execution, the
 		// return result of GetRunID() will be the started workflow run ID, not the new run ID caused by ContinueAsNewError,
 		// however, Get(ctx context.Context, valuePtr interface{}) will return result from the run which did not return ContinueAsNewError.
@@ -86,6 +94,12 @@ type (
 		// NOTE: DO NOT USE THIS API INSIDE A WORKFLOW, USE workflow.ExecuteChildWorkflow instead
 		ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error)
 
+		// GetWorkflow returns the WorkflowRun of an existing workflow execution, to get its result, signal, cancel,
+		// terminate, query or describe it, or read its history. The workflow execution is not checked, the methods of
+		// the WorkflowRun return its errors instead.
+		// - runID can be default(empty string). if empty string then the WorkflowRun acts on the current run of the workflow.
+		GetWorkflow(ctx context.Context, workflowID string, runID string) WorkflowRun
+
 		// SignalWorkflow sends a signals to a workflow in execution
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
@@ -107,6 +121,21 @@ type (
 		SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
 			options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error)
 
+		// SignalWithStartWorkflowRun sends a signal to a running workflow. If the workflow is not running or not found,
+		// it starts the workflow and then sends the signal in transaction. It returns the WorkflowRun of the signaled
+		// execution, to wait for its result.
+		// - workflowID, signalName, signalArg are same as SignalWorkflow's parameters, workflowID defaults to options.ID
+		// - workflow, workflowArgs are same as StartWorkflow's parameters
+		// - options.WorkflowIDReusePolicy is used when the workflow is started
+		// - options.ID must be empty or match workflowID
+		// - options.RequestID makes retried calls idempotent
+		// The errors it can return:
+		//  - EntityNotExistsError, if domain does not exist
+		//  - BadRequestError
+		//	- InternalServiceError
+		SignalWithStartWorkflowRun(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+			options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (WorkflowRun, error)
+
 		// CancelWorkflow cancels a workflow in execution
 		// - workflow ID of the workflow.
 		// - runID can be default(empty string). if empty string then it will pick the running execution of that workflow ID.
@@ -204,6 +233,46 @@ type (
 		//  - EntityNotExistError
 		ListOpenWorkflow(ctx context.Context, request *s.ListOpenWorkflowExecutionsRequest) (*s.ListOpenWorkflowExecutionsResponse, error)
 
+		// ListWorkflows lists the open or closed workflow executions matching the filter. The returned iterator pages
+		// through the results transparently; the iteration stops with the context error once ctx is done.
+		// Example:-
+		//	iter := ListWorkflows(ctx, ListWorkflowsFilter{Closed: true, WorkflowType: "MyWorkflow"})
+		//	for iter.HasNext() {
+		//		info, err := iter.Next()
+		//		if err != nil {
+		//			return err
+		//		}
+		//		...
+		//	}
+		// The errors the iterator can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		ListWorkflows(ctx context.Context, filter ListWorkflowsFilter) WorkflowExecutionIterator
+
+		// BatchCancel requests cancellation of every open workflow execution matching the filter. The batch runs in the
+		// background until all executions are processed or ctx is done; use the returned BatchOperation to follow its
+		// progress and to get a checkpoint to resume it from. Executions which closed before they were processed or
+		// whose cancellation was already requested are reported as skipped.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchCancel(ctx context.Context, filter ListWorkflowsFilter, options BatchOptions) (BatchOperation, error)
+
+		// BatchTerminate terminates every open workflow execution matching the filter with the given reason and
+		// details. See BatchCancel for how the batch runs.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchTerminate(ctx context.Context, filter ListWorkflowsFilter, reason string, details []byte, options BatchOptions) (BatchOperation, error)
+
+		// BatchSignal sends the signal to every open workflow execution matching the filter. See BatchCancel for how
+		// the batch runs.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		BatchSignal(ctx context.Context, filter ListWorkflowsFilter, signalName string, arg interface{}, options BatchOptions) (BatchOperation, error)
+
 		// QueryWorkflow queries a given workflow execution and returns the query result synchronously. Parameter workflowID
 		// and queryType are required, other parameters are optional. The workflowID and runID (optional) identify the
 		// target workflow execution that this query will be send to. If runID is not specified (empty string), server will
@@ -224,6 +293,20 @@ type (
 		//  - QueryFailError
 		QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error)
 
+		// QueryWorkflowWithOptions queries a given workflow execution like QueryWorkflow, with options to reject the
+		// query depending on the state of the execution and to choose the consistency of the query result.
+		// See QueryWorkflowWithOptionsRequest for the options.
+		// - request.WorkflowID and request.QueryType are required.
+		// The status of the execution is returned in QueryWorkflowWithOptionsResponse.Status, a rejected query returns
+		// it in QueryWorkflowWithOptionsResponse.QueryRejected too and no error. The query goes through the Interceptors
+		// of the client as a QueryWorkflow call.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		//  - *QueryFailedError
+		QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error)
+
 		// DescribeWorkflowExecution returns information about the specified workflow execution.
 		// The errors it can return:
 		//  - BadRequestError
@@ -231,6 +314,26 @@ type (
 		//  - EntityNotExistError
 		DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*s.DescribeWorkflowExecutionResponse, error)
 
+		// DescribeWorkflow returns the description of the specified workflow execution with typed fields. The pending
+		// child workflows are not returned by the Cadence service, use DescribeWorkflowWithOptions to read them from the
+		// history of the execution. The Cadence service does not expose the attempt of a pending activity.
+		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		DescribeWorkflow(ctx context.Context, workflowID, runID string) (*WorkflowExecutionDescription, error)
+
+		// DescribeWorkflowWithOptions returns the description of the specified workflow execution like DescribeWorkflow.
+		// With options.ReadHistory the pending child workflows of a running execution are read from its history, which
+		// downloads the whole history.
+		// - runID can be default(empty string). if empty string then it will pick the last running execution of that workflow ID.
+		// The errors it can return:
+		//  - BadRequestError
+		//  - InternalServiceError
+		//  - EntityNotExistError
+		DescribeWorkflowWithOptions(ctx context.Context, workflowID, runID string, options DescribeWorkflowOptions) (*WorkflowExecutionDescription, error)
+
 		// DescribeTaskList returns information about the target tasklist, right now this API returns the
 		// pollers which polled this tasklist in last few minutes.
 		// The errors it can return:
@@ -245,6 +348,32 @@ type (
 		MetricsScope  tally.Scope
 		Identity      string
 		DataConverter encoded.DataConverter
+
+		// Optional: Sets ContextPropagators that allows users to control the context information passed through a workflow
+		// The propagators only take effect when EnableHeaderEnvelope is set.
+		// default: nil
+		ContextPropagators []ContextPropagator
+
+		// Optional: Sets whether the header written by ContextPropagators and the Tracer is sent when starting and
+		// signaling workflows.
+		// WARNING: the cadence service has no header field for these requests, so the header is carried in an
+		// envelope in front of the encoded input. This changes the input stored by the server: workers written in
+		// other languages, the CLI and tools reading the input from the history see the envelope as part of the
+		// input. Only enable it when every worker of the workflows started or signaled by this client is a Go worker
+		// of a version which strips the envelope.
+		// default: false, no header is sent
+		EnableHeaderEnvelope bool
+
+		// Optional: Sets interceptors that wrap the calls made through the client. The first interceptor in the list is
+		// the outermost one.
+		// default: nil
+		Interceptors []ClientInterceptor
+
+		// Optional: Sets an opentracing Tracer. Starting and signaling a workflow reports a span. If
+		// EnableHeaderEnvelope is set, the span context is propagated to the workflow so that the workflow and
+		// activity spans reported by workers using the same tracer join the trace.
+		// default: nil, no spans are reported
+		Tracer opentracing.Tracer
 	}
 
 	// StartWorkflowOptions configuration parameters for starting a workflow execution.
@@ -255,6 +384,19 @@ type (
 		// Optional: defaulted to a uuid.
 		ID string
 
+		// RequestID - Identifies the request to the Cadence service, which ignores a request whose ID was already
+		// processed. Set it to a value which is stable across the retries of a call to make them idempotent: when the
+		// workflow was already started with the same RequestID, StartWorkflow returns the started execution instead of
+		// a WorkflowExecutionAlreadyStartedError.
+		// Optional: defaulted to a new uuid for each call.
+		RequestID string
+
+		// RetryStart - Retries the start request of StartWorkflow and ExecuteWorkflow with the same RequestID until it
+		// succeeds, fails with a non transient error or the context is done, instead of giving up after a minute of
+		// transient errors such as timeouts. Use a context with a deadline to bound the retries.
+		// Optional: defaulted to false.
+		RetryStart bool
+
 		// TaskList - The decisions of the workflow are scheduled on this queue.
 		// This is also the default task list on which activities are scheduled. The workflow author can choose
 		// to override this using activity options.
@@ -280,6 +422,26 @@ type (
 		// RetryPolicy - Optional retry policy for workflow. If a retry policy is specified, in case of workflow failure
 		// server will start new workflow execution if needed based on the retry policy.
 		RetryPolicy *RetryPolicy
+
+		// CronSchedule - Optional cron schedule for workflow. If a cron schedule is specified, each run of the workflow
+		// waits for the next fire time of the schedule, executes the workflow once and continues as new, regardless of
+		// the outcome of the run. A failed run does not fail the workflow, its error is logged and passed to the next
+		// run as WorkflowInfo.CronLastFailure. Cancel or terminate the workflow to stop the schedule.
+		// The wait for the next fire time is part of the run, so ExecutionStartToCloseTimeout must cover the longest
+		// interval between two fire times plus the time the workflow takes: a run whose next fire time is after its
+		// timeout fails right away and stops the schedule.
+		// The schedule is carried in the header envelope, so it requires ClientOptions.EnableHeaderEnvelope and
+		// starting the workflow fails otherwise.
+		// The schedule uses the standard five field format, evaluated in UTC:
+		// ┌───────────── minute (0 - 59)
+		// │ ┌───────────── hour (0 - 23)
+		// │ │ ┌───────────── day of the month (1 - 31)
+		// │ │ │ ┌───────────── month (1 - 12 or jan - dec)
+		// │ │ │ │ ┌───────────── day of the week (0 - 7 or sun - sat, 0 and 7 are Sunday)
+		// │ │ │ │ │
+		// * * * * *
+		// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are accepted as well.
+		CronSchedule string
 	}
 
 	// RetryPolicy defines the retry policy
@@ -312,6 +474,141 @@ type (
 		NonRetriableErrorReasons []string
 	}
 
+	// ListWorkflowsFilter selects the workflow executions returned by Client.ListWorkflows.
+	ListWorkflowsFilter struct {
+		// Closed - Lists closed workflow executions instead of open ones.
+		// Optional: defaulted to false.
+		Closed bool
+
+		// WorkflowID - Only lists the executions of the given workflow ID.
+		// Optional: no default.
+		WorkflowID string
+
+		// WorkflowType - Only lists the executions of the given workflow type.
+		// Optional: no default.
+		WorkflowType string
+
+		// Statuses - Only lists the closed executions which closed with one of the given statuses. Ignored unless
+		// Closed is set.
+		// Optional: all close statuses.
+		Statuses []WorkflowExecutionStatus
+
+		// EarliestTime, LatestTime - The time range of the listed executions. As with the Cadence service, the range
+		// applies to the start time of open executions and to the close time of closed executions.
+		// Optional: defaulted to no bounds.
+		EarliestTime time.Time
+		LatestTime   time.Time
+	}
+
+	// WorkflowExecutionInfo describes a workflow execution.
+	WorkflowExecutionInfo struct {
+		Execution     WorkflowExecution
+		WorkflowType  WorkflowType
+		StartTime     time.Time
+		CloseTime     time.Time // zero while the execution is running
+		Status        WorkflowExecutionStatus
+		HistoryLength int64
+	}
+
+	// WorkflowExecutionStatus is the status of a workflow execution.
+	WorkflowExecutionStatus int
+
+	// WorkflowExecutionDescription describes a workflow execution, see Client.DescribeWorkflow.
+	WorkflowExecutionDescription struct {
+		WorkflowExecutionInfo
+		TaskList                     string
+		ExecutionStartToCloseTimeout time.Duration
+		TaskStartToCloseTimeout      time.Duration
+		// PendingActivities are the activities scheduled by the execution which are not closed yet.
+		PendingActivities []PendingActivityInfo
+		// PendingChildren are the child workflows started by the execution which are not closed yet, only set when
+		// the history is read, see DescribeWorkflowOptions.ReadHistory.
+		PendingChildren []PendingChildWorkflowInfo
+	}
+
+	// DescribeWorkflowOptions configuration parameters for describing a workflow execution.
+	DescribeWorkflowOptions struct {
+		// ReadHistory - Reads the history of a running execution to set PendingChildren, which the Cadence service
+		// does not describe. The whole history is downloaded.
+		// Optional: default false.
+		ReadHistory bool
+	}
+
+	// PendingActivityInfo describes an activity which is not closed yet. The attempt of the activity is not exposed by
+	// the Cadence service, it is only known by the activity itself through ActivityInfo.Attempt.
+	PendingActivityInfo struct {
+		ActivityID   string
+		ActivityType ActivityType
+		State        PendingActivityState
+		// LastHeartbeatTime is zero until the activity recorded a heartbeat.
+		LastHeartbeatTime time.Time
+		// HeartbeatDetails are the details of the last heartbeat, decoded with the DataConverter of the client.
+		HeartbeatDetails encoded.Values
+	}
+
+	// PendingActivityState is the state of a pending activity.
+	PendingActivityState int
+
+	// PendingChildWorkflowInfo describes a child workflow which is not closed yet.
+	PendingChildWorkflowInfo struct {
+		WorkflowID   string
+		RunID        string // empty until the child workflow started
+		WorkflowType WorkflowType
+		// InitiatedEventID is the ID of the StartChildWorkflowExecutionInitiated event of the child workflow.
+		InitiatedEventID int64
+	}
+
+	// BatchOptions configuration parameters for running a batch operation.
+	BatchOptions struct {
+		// Concurrency - The maximum number of executions processed at the same time.
+		// Optional: defaulted to 1.
+		Concurrency int
+
+		// RateLimit - The maximum number of executions processed per second.
+		// Optional: defaulted to unlimited.
+		RateLimit float64
+
+		// Checkpoint - The token returned by BatchOperation.Checkpoint of an earlier batch over the same filter. The
+		// batch resumes from it and does not process the executions processed before the checkpoint again.
+		// Optional: the batch starts from the beginning.
+		Checkpoint string
+	}
+
+	// BatchOperation is the handle of a batch operation running in the background.
+	BatchOperation interface {
+		// Progress returns the current progress of the batch.
+		Progress() BatchProgress
+
+		// Checkpoint returns a token which resumes the batch from its current progress when passed as
+		// BatchOptions.Checkpoint.
+		Checkpoint() string
+
+		// Wait blocks until the batch stops or ctx is done, and returns the progress of the batch. The error is the
+		// one which stopped the batch, such as a failure to list the executions or the batch context being done.
+		// Failures of individual executions are reported in the progress instead.
+		Wait(ctx context.Context) (BatchProgress, error)
+	}
+
+	// BatchProgress is the progress of a batch operation.
+	BatchProgress struct {
+		// Succeeded is the number of executions processed successfully.
+		Succeeded int
+		// Failed is the number of executions which failed to be processed, see Failures.
+		Failed int
+		// Skipped is the number of executions which did not need to be processed anymore.
+		Skipped int
+		// Failures are the executions which failed to be processed, with their error.
+		Failures []BatchFailure
+		// Done is set once every execution matching the filter has been processed.
+		Done bool
+	}
+
+	// BatchFailure is a workflow execution which a batch operation failed to process.
+	BatchFailure struct {
+		Execution WorkflowExecution
+		Err       error
+	}
+
 	// DomainClient is the client for managing operations on the domain.
 	// CLI, tools, ... can use this layer to manager operations on domain.
 	DomainClient interface {
@@ -338,8 +635,157 @@ type (
 		//	- BadRequestError
 		//	- InternalServiceError
 		Update(ctx context.Context, request *s.UpdateDomainRequest) error
+
+		// List returns an iterator over all the domains, which pages through them transparently.
+		// The errors the iterator can return:
+		//	- BadRequestError
+		//	- InternalServiceError
+		List(ctx context.Context) DomainIterator
+
+		// Deprecate a domain. A deprecated domain cannot be used to start new workflow executions.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		Deprecate(ctx context.Context, name string) error
+
+		// RegisterDomain registers a domain with cadence server, like Register does without thrift types. The status of
+		// the domain info is ignored.
+		// The errors it can throw:
+		//	- DomainAlreadyExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		RegisterDomain(ctx context.Context, info DomainInfo, config DomainConfig, replicationConfig DomainReplicationConfig) error
+
+		// DescribeDomain describes a domain, like Describe does without thrift types.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		DescribeDomain(ctx context.Context, name string) (*DomainDescription, error)
+
+		// UpdateDomain applies the changes of the update to a domain, like Update does without thrift types, and
+		// returns the updated domain.
+		// The errors it can throw:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		UpdateDomain(ctx context.Context, name string, update *DomainUpdate) (*DomainDescription, error)
+	}
+
+	// DomainIterator represents the interface for
+	// domain iterator
+	DomainIterator interface {
+		// HasNext return whether this iterator has next value
+		HasNext() bool
+		// Next returns the next domain and error
+		Next() (*DomainDescription, error)
+	}
+
+	// DomainDescription describes a domain.
+	DomainDescription struct {
+		Info              DomainInfo
+		Config            DomainConfig
+		ReplicationConfig DomainReplicationConfig
+		FailoverVersion   int64
+		IsGlobalDomain    bool
+	}
+
+	// DomainInfo is the information of a domain.
+	DomainInfo struct {
+		Name        string
+		Status      DomainStatus
+		Description string
+		OwnerEmail  string
+		Data        map[string]string
+	}
+
+	// DomainConfig is the configuration of a domain.
+	DomainConfig struct {
+		// WorkflowExecutionRetentionPeriodInDays is how long the history of closed workflow executions is kept.
+		WorkflowExecutionRetentionPeriodInDays int32
+		// EmitMetric is whether metrics are emitted for the domain.
+		EmitMetric bool
+	}
+
+	// DomainReplicationConfig is the replication configuration of a domain.
+	DomainReplicationConfig struct {
+		// ActiveClusterName is the name of the cluster the domain is active in.
+		ActiveClusterName string
+		// Clusters are the names of the clusters the domain is replicated to.
+		Clusters []string
+	}
+
+	// DomainUpdate is the set of changes applied by DomainClient.UpdateDomain. Create it with NewDomainUpdate and
+	// chain the setters of the changes to make, the other properties of the domain are left unchanged:
+	//	update := NewDomainUpdate().SetOwnerEmail("payments@example.com").SetRetentionPeriodInDays(7)
+	DomainUpdate struct {
+		info              *s.UpdateDomainInfo
+		config            *s.DomainConfiguration
+		replicationConfig *s.DomainReplicationConfiguration
+	}
+
+	// QueryWorkflowWithOptionsRequest is the request to Client.QueryWorkflowWithOptions.
+	QueryWorkflowWithOptionsRequest struct {
+		// WorkflowID - The ID of the queried workflow.
+		// Mandatory: no default.
+		WorkflowID string
+
+		// RunID - The run ID of the queried execution.
+		// Optional: defaulted to the current run of the workflow.
+		RunID string
+
+		// QueryType - The type of the query, such as QueryTypeStackTrace or a type registered with SetQueryHandler.
+		// Mandatory: no default.
+		QueryType string
+
+		// Args - The arguments passed to the query handler.
+		// Optional: no default.
+		Args []interface{}
+
+		// QueryRejectCondition - Rejects the query instead of running it, depending on the status of the execution.
+		// Optional: defaulted to QueryRejectConditionNone.
+		QueryRejectCondition QueryRejectCondition
+
+		// QueryConsistencyLevel - Selects whether the query may run before the events buffered for the execution are
+		// processed by the workflow.
+		// Optional: defaulted to QueryConsistencyLevelEventual.
+		QueryConsistencyLevel QueryConsistencyLevel
+	}
+
+	// QueryWorkflowWithOptionsResponse is the response of Client.QueryWorkflowWithOptions.
+	QueryWorkflowWithOptionsResponse struct {
+		// QueryResult is the result of the query, nil if the query was rejected.
+		QueryResult encoded.Value
+		// QueryRejected is set when the query was rejected by QueryWorkflowWithOptionsRequest.QueryRejectCondition.
+		QueryRejected *QueryRejected
+		// Status is the status of the execution when the query was sent.
+		Status WorkflowExecutionStatus
+	}
+
+	// QueryRejected describes why a query was rejected.
+	QueryRejected struct {
+		// Status is the status of the execution when the query was rejected.
+		Status WorkflowExecutionStatus
+	}
+
+	// QueryTypeInfo describes a query type supported by a workflow, it is the element of the QueryTypeQueryTypes result.
+	QueryTypeInfo struct {
+		// QueryType is the query type passed to Client.QueryWorkflow().
+		QueryType string
+		// ArgTypes are the Go types of the query handler arguments.
+		ArgTypes []string
 	}
 
+	// QueryRejectCondition selects the executions a query is rejected for.
+	QueryRejectCondition int
+
+	// QueryConsistencyLevel selects the consistency of a query result.
+	QueryConsistencyLevel int
+
+	// DomainStatus is the status of a domain.
+	DomainStatus int
+
 	// WorkflowIDReusePolicy defines workflow ID reuse behavior.
 	WorkflowIDReusePolicy int
 )
@@ -358,6 +804,81 @@ const (
 	WorkflowIDReusePolicyRejectDuplicate
 )
 
+const (
+	// DomainStatusRegistered is the status of a registered domain.
+	DomainStatusRegistered DomainStatus = iota
+
+	// DomainStatusDeprecated is the status of a deprecated domain.
+	DomainStatusDeprecated
+
+	// DomainStatusDeleted is the status of a deleted domain.
+	DomainStatusDeleted
+
+	// DomainStatusUnknown is the status of a domain whose status is not known by this client.
+	DomainStatusUnknown
+)
+
+const (
+	// WorkflowExecutionStatusRunning is the status of an open workflow execution.
+	WorkflowExecutionStatusRunning WorkflowExecutionStatus = iota
+
+	// WorkflowExecutionStatusCompleted is the status of a workflow execution which completed successfully.
+	WorkflowExecutionStatusCompleted
+
+	// WorkflowExecutionStatusFailed is the status of a workflow execution which failed.
+	WorkflowExecutionStatusFailed
+
+	// WorkflowExecutionStatusCanceled is the status of a workflow execution which was canceled.
+	WorkflowExecutionStatusCanceled
+
+	// WorkflowExecutionStatusTerminated is the status of a workflow execution which was terminated.
+	WorkflowExecutionStatusTerminated
+
+	// WorkflowExecutionStatusContinuedAsNew is the status of a workflow execution which continued as new.
+	WorkflowExecutionStatusContinuedAsNew
+
+	// WorkflowExecutionStatusTimedOut is the status of a workflow execution which timed out.
+	WorkflowExecutionStatusTimedOut
+
+	// WorkflowExecutionStatusUnknown is the status of a closed workflow execution whose close status is not known
+	// by this client.
+	WorkflowExecutionStatusUnknown
+)
+
+const (
+	// PendingActivityStateScheduled is the state of an activity which is not started yet.
+	PendingActivityStateScheduled PendingActivityState = iota
+
+	// PendingActivityStateStarted is the state of a running activity.
+	PendingActivityStateStarted
+
+	// PendingActivityStateCancelRequested is the state of an activity whose cancellation was requested.
+	PendingActivityStateCancelRequested
+)
+
+const (
+	// QueryRejectConditionNone runs the query whatever the status of the execution is.
+	QueryRejectConditionNone QueryRejectCondition = iota
+
+	// QueryRejectConditionNotOpen rejects the query if the execution is closed.
+	QueryRejectConditionNotOpen
+
+	// QueryRejectConditionNotCompletedCleanly rejects the query if the execution is closed with another status than
+	// WorkflowExecutionStatusCompleted.
+	QueryRejectConditionNotCompletedCleanly
+)
+
+const (
+	// QueryConsistencyLevelEventual runs the query against the state of the workflow when the query is received, events
+	// not processed yet by the workflow, such as a signal, are not reflected in the result.
+	QueryConsistencyLevelEventual QueryConsistencyLevel = iota
+
+	// QueryConsistencyLevelStrong waits for the workflow to process the events recorded before the query is sent,
+	// so that the result reflects them. The wait is bounded by the context of the query and by 30 seconds, the query
+	// fails with the context error when the workflow does not process the events in time.
+	QueryConsistencyLevelStrong
+)
+
 // NewClient creates an instance of a workflow client
 func NewClient(service workflowserviceclient.Interface, domain string, options *ClientOptions) Client {
 	var identity string
@@ -377,13 +898,26 @@ func NewClient(service workflowserviceclient.Interface, domain string, options *
 	} else {
 		dataConverter = getDefaultDataConverter()
 	}
-	return &workflowClient{
-		workflowService: metrics.NewWorkflowServiceWrapper(service, metricScope),
-		domain:          domain,
-		metricsScope:    metrics.NewTaggedScope(metricScope),
-		identity:        identity,
-		dataConverter:   dataConverter,
+	var contextPropagators []ContextPropagator
+	var interceptors []ClientInterceptor
+	if options != nil {
+		// The client only writes headers, and they are only sent within the header envelope.
+		if options.EnableHeaderEnvelope {
+			contextPropagators = withTracingContextPropagator(options.Tracer, options.ContextPropagators)
+		}
+		interceptors = withTracingClientInterceptor(options.Tracer, options.Interceptors)
+	}
+	client := &workflowClient{
+		workflowService:      metrics.NewWorkflowServiceWrapper(service, metricScope),
+		domain:               domain,
+		metricsScope:         metrics.NewTaggedScope(metricScope),
+		identity:             identity,
+		dataConverter:        dataConverter,
+		contextPropagators:   contextPropagators,
+		enableHeaderEnvelope: options != nil && options.EnableHeaderEnvelope,
 	}
+	client.interceptor = newClientInterceptorChain(interceptors, &workflowClientInterceptor{workflowClient: client})
+	return client
 }
 
 // NewDomainClient creates an instance of a domain client, to manager lifecycle of domains.
@@ -421,6 +955,323 @@ func (p WorkflowIDReusePolicy) toThriftPtr() *s.WorkflowIdReusePolicy {
 	return &policy
 }
 
+// NewDomainUpdate creates an empty DomainUpdate.
+func NewDomainUpdate() *DomainUpdate {
+	return &DomainUpdate{}
+}
+
+// SetDescription sets the description of the domain.
+func (u *DomainUpdate) SetDescription(description string) *DomainUpdate {
+	u.getInfo().Description = common.StringPtr(description)
+	return u
+}
+
+// SetOwnerEmail sets the owner email of the domain.
+func (u *DomainUpdate) SetOwnerEmail(ownerEmail string) *DomainUpdate {
+	u.getInfo().OwnerEmail = common.StringPtr(ownerEmail)
+	return u
+}
+
+// SetData sets the value of a key of the domain data. The other keys of the domain data are left unchanged.
+func (u *DomainUpdate) SetData(key, value string) *DomainUpdate {
+	info := u.getInfo()
+	if info.Data == nil {
+		info.Data = make(map[string]string)
+	}
+	info.Data[key] = value
+	return u
+}
+
+// SetRetentionPeriodInDays sets how long the history of closed workflow executions of the domain is kept.
+func (u *DomainUpdate) SetRetentionPeriodInDays(days int32) *DomainUpdate {
+	u.getConfig().WorkflowExecutionRetentionPeriodInDays = common.Int32Ptr(days)
+	return u
+}
+
+// SetEmitMetric sets whether metrics are emitted for the domain.
+func (u *DomainUpdate) SetEmitMetric(emitMetric bool) *DomainUpdate {
+	u.getConfig().EmitMetric = common.BoolPtr(emitMetric)
+	return u
+}
+
+// SetActiveClusterName sets the cluster the domain is active in, which fails the domain over to that cluster.
+func (u *DomainUpdate) SetActiveClusterName(clusterName string) *DomainUpdate {
+	u.getReplicationConfig().ActiveClusterName = common.StringPtr(clusterName)
+	return u
+}
+
+// SetClusters sets the clusters the domain is replicated to.
+func (u *DomainUpdate) SetClusters(clusterNames ...string) *DomainUpdate {
+	u.getReplicationConfig().Clusters = convertClusterNames(clusterNames)
+	return u
+}
+
+func (u *DomainUpdate) getInfo() *s.UpdateDomainInfo {
+	if u.info == nil {
+		u.info = &s.UpdateDomainInfo{}
+	}
+	return u.info
+}
+
+func (u *DomainUpdate) getConfig() *s.DomainConfiguration {
+	if u.config == nil {
+		u.config = &s.DomainConfiguration{}
+	}
+	return u.config
+}
+
+func (u *DomainUpdate) getReplicationConfig() *s.DomainReplicationConfiguration {
+	if u.replicationConfig == nil {
+		u.replicationConfig = &s.DomainReplicationConfiguration{}
+	}
+	return u.replicationConfig
+}
+
+func (u *DomainUpdate) toThrift(name string) *s.UpdateDomainRequest {
+	return &s.UpdateDomainRequest{
+		Name:                     common.StringPtr(name),
+		UpdatedInfo:              u.info,
+		Configuration:            u.config,
+		ReplicationConfiguration: u.replicationConfig,
+	}
+}
+
+// String returns the name of the domain status.
+func (st DomainStatus) String() string {
+	switch st {
+	case DomainStatusRegistered:
+		return "Registered"
+	case DomainStatusDeprecated:
+		return "Deprecated"
+	case DomainStatusDeleted:
+		return "Deleted"
+	case DomainStatusUnknown:
+		return "Unknown"
+	default:
+		return fmt.Sprintf("DomainStatus(%d)", int(st))
+	}
+}
+
+func convertDomainStatus(status s.DomainStatus) DomainStatus {
+	switch status {
+	case s.DomainStatusRegistered:
+		return DomainStatusRegistered
+	case s.DomainStatusDeprecated:
+		return DomainStatusDeprecated
+	case s.DomainStatusDeleted:
+		return DomainStatusDeleted
+	default:
+		return DomainStatusUnknown
+	}
+}
+
+func convertClusterNames(clusterNames []string) []*s.ClusterReplicationConfiguration {
+	var clusters []*s.ClusterReplicationConfiguration
+	for _, name := range clusterNames {
+		clusters = append(clusters, &s.ClusterReplicationConfiguration{ClusterName: common.StringPtr(name)})
+	}
+	return clusters
+}
+
+func convertDomainDescription(response *s.DescribeDomainResponse) *DomainDescription {
+	info := response.DomainInfo
+	config := response.Configuration
+	replicationConfig := response.ReplicationConfiguration
+	description := &DomainDescription{
+		Info: DomainInfo{
+			Name:        info.GetName(),
+			Status:      convertDomainStatus(info.GetStatus()),
+			Description: info.GetDescription(),
+			OwnerEmail:  info.GetOwnerEmail(),
+			Data:        info.Data,
+		},
+		Config: DomainConfig{
+			WorkflowExecutionRetentionPeriodInDays: config.GetWorkflowExecutionRetentionPeriodInDays(),
+			EmitMetric:                             config.GetEmitMetric(),
+		},
+		ReplicationConfig: DomainReplicationConfig{
+			ActiveClusterName: replicationConfig.GetActiveClusterName(),
+		},
+		FailoverVersion: response.GetFailoverVersion(),
+		IsGlobalDomain:  response.GetIsGlobalDomain(),
+	}
+	for _, cluster := range replicationConfig.GetClusters() {
+		description.ReplicationConfig.Clusters = append(description.ReplicationConfig.Clusters, cluster.GetClusterName())
+	}
+	return description
+}
+
+// String returns the name of the workflow execution status.
+func (st WorkflowExecutionStatus) String() string {
+	switch st {
+	case WorkflowExecutionStatusRunning:
+		return "Running"
+	case WorkflowExecutionStatusCompleted:
+		return "Completed"
+	case WorkflowExecutionStatusFailed:
+		return "Failed"
+	case WorkflowExecutionStatusCanceled:
+		return "Canceled"
+	case WorkflowExecutionStatusTerminated:
+		return "Terminated"
+	case WorkflowExecutionStatusContinuedAsNew:
+		return "ContinuedAsNew"
+	case WorkflowExecutionStatusTimedOut:
+		return "TimedOut"
+	case WorkflowExecutionStatusUnknown:
+		return "Unknown"
+	default:
+		return fmt.Sprintf("WorkflowExecutionStatus(%d)", int(st))
+	}
+}
+
+func (st WorkflowExecutionStatus) toThriftPtr() *s.WorkflowExecutionCloseStatus {
+	var status s.WorkflowExecutionCloseStatus
+	switch st {
+	case WorkflowExecutionStatusCompleted:
+		status = s.WorkflowExecutionCloseStatusCompleted
+	case WorkflowExecutionStatusFailed:
+		status = s.WorkflowExecutionCloseStatusFailed
+	case WorkflowExecutionStatusCanceled:
+		status = s.WorkflowExecutionCloseStatusCanceled
+	case WorkflowExecutionStatusTerminated:
+		status = s.WorkflowExecutionCloseStatusTerminated
+	case WorkflowExecutionStatusContinuedAsNew:
+		status = s.WorkflowExecutionCloseStatusContinuedAsNew
+	case WorkflowExecutionStatusTimedOut:
+		status = s.WorkflowExecutionCloseStatusTimedOut
+	default:
+		return nil
+	}
+	return &status
+}
+
+// rejects returns whether a query is rejected for an execution with the given status.
+func (c QueryRejectCondition) rejects(status WorkflowExecutionStatus) bool {
+	switch c {
+	case QueryRejectConditionNotOpen:
+		return status != WorkflowExecutionStatusRunning
+	case QueryRejectConditionNotCompletedCleanly:
+		return status != WorkflowExecutionStatusRunning && status != WorkflowExecutionStatusCompleted
+	default:
+		return false
+	}
+}
+
+func convertWorkflowExecutionStatus(closeStatus *s.WorkflowExecutionCloseStatus) WorkflowExecutionStatus {
+	if closeStatus == nil {
+		return WorkflowExecutionStatusRunning
+	}
+	switch *closeStatus {
+	case s.WorkflowExecutionCloseStatusCompleted:
+		return WorkflowExecutionStatusCompleted
+	case s.WorkflowExecutionCloseStatusFailed:
+		return WorkflowExecutionStatusFailed
+	case s.WorkflowExecutionCloseStatusCanceled:
+		return WorkflowExecutionStatusCanceled
+	case s.WorkflowExecutionCloseStatusTerminated:
+		return WorkflowExecutionStatusTerminated
+	case s.WorkflowExecutionCloseStatusContinuedAsNew:
+		return WorkflowExecutionStatusContinuedAsNew
+	case s.WorkflowExecutionCloseStatusTimedOut:
+		return WorkflowExecutionStatusTimedOut
+	default:
+		return WorkflowExecutionStatusUnknown
+	}
+}
+
+func convertWorkflowExecutionInfo(info *s.WorkflowExecutionInfo) *WorkflowExecutionInfo {
+	result := &WorkflowExecutionInfo{
+		Execution: WorkflowExecution{
+			ID:    info.Execution.GetWorkflowId(),
+			RunID: info.Execution.GetRunId(),
+		},
+		WorkflowType:  WorkflowType{Name: info.Type.GetName()},
+		StartTime:     time.Unix(0, info.GetStartTime()),
+		Status:        convertWorkflowExecutionStatus(info.CloseStatus),
+		HistoryLength: info.GetHistoryLength(),
+	}
+	if info.CloseTime != nil {
+		result.CloseTime = time.Unix(0, info.GetCloseTime())
+	}
+	return result
+}
+
+// IsRunning returns whether the execution is open.
+func (d *WorkflowExecutionDescription) IsRunning() bool {
+	return d.Status == WorkflowExecutionStatusRunning
+}
+
+// PendingActivityByID returns the pending activity with the given activity ID, nil if there is none.
+func (d *WorkflowExecutionDescription) PendingActivityByID(activityID string) *PendingActivityInfo {
+	for i := range d.PendingActivities {
+		if d.PendingActivities[i].ActivityID == activityID {
+			return &d.PendingActivities[i]
+		}
+	}
+	return nil
+}
+
+// PendingChildByWorkflowID returns the pending child workflow with the given workflow ID, nil if there is none.
+func (d *WorkflowExecutionDescription) PendingChildByWorkflowID(workflowID string) *PendingChildWorkflowInfo {
+	for i := range d.PendingChildren {
+		if d.PendingChildren[i].WorkflowID == workflowID {
+			return &d.PendingChildren[i]
+		}
+	}
+	return nil
+}
+
+func (st PendingActivityState) String() string {
+	switch st {
+	case PendingActivityStateScheduled:
+		return "Scheduled"
+	case PendingActivityStateStarted:
+		return "Started"
+	case PendingActivityStateCancelRequested:
+		return "CancelRequested"
+	default:
+		return fmt.Sprintf("PendingActivityState(%d)", int(st))
+	}
+}
+
+func convertWorkflowExecutionDescription(response *s.DescribeWorkflowExecutionResponse, dataConverter encoded.DataConverter) *WorkflowExecutionDescription {
+	description := &WorkflowExecutionDescription{
+		WorkflowExecutionInfo: *convertWorkflowExecutionInfo(response.WorkflowExecutionInfo),
+	}
+	if config := response.ExecutionConfiguration; config != nil {
+		description.TaskList = config.TaskList.GetName()
+		description.ExecutionStartToCloseTimeout = time.Duration(config.GetExecutionStartToCloseTimeoutSeconds()) * time.Second
+		description.TaskStartToCloseTimeout = time.Duration(config.GetTaskStartToCloseTimeoutSeconds()) * time.Second
+	}
+	for _, activity := range response.PendingActivities {
+		info := PendingActivityInfo{
+			ActivityID:   activity.GetActivityID(),
+			ActivityType: ActivityType{Name: activity.ActivityType.GetName()},
+			State:        convertPendingActivityState(activity.GetState()),
+		}
+		if activity.LastHeartbeatTimestamp != nil {
+			info.LastHeartbeatTime = time.Unix(0, activity.GetLastHeartbeatTimestamp())
+		}
+		if len(activity.HeartbeatDetails) > 0 {
+			info.HeartbeatDetails = newEncodedValues(activity.HeartbeatDetails, dataConverter)
+		}
+		description.PendingActivities = append(description.PendingActivities, info)
+	}
+	return description
+}
+
+func convertPendingActivityState(state s.PendingActivityState) PendingActivityState {
+	switch state {
+	case s.PendingActivityStateStarted:
+		return PendingActivityStateStarted
+	case s.PendingActivityStateCancelRequested:
+		return PendingActivityStateCancelRequested
+	default:
+		return PendingActivityStateScheduled
+	}
+}
+
 // NewValue creates a new encoded.Value which can be used to decode binary data returned by Cadence.  For example:
 // User had Activity.RecordHeartbeat(ctx, "my-heartbeat") and then got response from calling Client.DescribeWorkflowExecution.
 // The response contains binary field PendingActivityInfo.HeartbeatDetails,
@@ -0,0 +1,502 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// command line config params
+	config struct {
+		source         string
+		output         string
+		workflows      string
+		activities     string
+		workflowPrefix string
+		activityPrefix string
+	}
+
+	// method is a workflow, activity, signal or query described by an interface method.
+	method struct {
+		name       string
+		kind       methodKind
+		params     []param // without the leading context
+		resultType string  // empty if the method only returns error
+	}
+
+	param struct {
+		name string
+		typ  string
+		expr ast.Expr
+	}
+
+	methodKind int
+
+	// generator renders the stubs of one source file.
+	generator struct {
+		fset       *token.FileSet
+		file       *ast.File
+		cfg        *config
+		imports    map[string]string // package name -> import path of the source file
+		usedImport map[string]bool
+		typeNames  map[string]bool
+	}
+)
+
+const (
+	kindActivity methodKind = iota
+	kindWorkflow
+	kindSignal
+	kindQuery
+)
+
+const (
+	signalDirective = "cadence:signal"
+	queryDirective  = "cadence:query"
+)
+
+// reservedNames are the identifiers used by the generated code, parameters with these names are renamed.
+var reservedNames = map[string]bool{
+	"c": true, "f": true, "ctx": true, "options": true, "workflowID": true, "runID": true,
+	"run": true, "err": true, "result": true, "value": true,
+	"context": true, "client": true, "workflow": true,
+}
+
+// command line utility that generates typed stubs for the workflows and activities described by Go interfaces.
+// Usage as follows:
+//
+//	go run ./internal/cmd/tools/stubgen/stubgen.go -source payments.go -activities PaymentActivities \
+//	    -workflows PaymentWorkflows -out payments_stubs.go
+//
+// Activity methods take an optional leading context.Context and return either error or (T, error). They are
+// scheduled by name, so register the implementing struct with RegisterActivity, or register each function under
+// the method name. Workflow methods take a leading workflow.Context and return either error or (T, error); register
+// them under the method name with RegisterWorkflowWithOptions. A workflow interface may also describe its signals
+// and queries, marked with a "cadence:signal" or "cadence:query" line in the method comment:
+//
+//	type PaymentWorkflows interface {
+//	    Checkout(ctx workflow.Context, order *Order) (*Receipt, error)
+//	    // cadence:signal
+//	    Approve(approval *Approval)
+//	    // cadence:query
+//	    Status() (string, error)
+//	}
+//
+// The stubs are written into the package of the source file.
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.source, "source", "", "go file declaring the interfaces")
+	flag.StringVar(&cfg.output, "out", "", "output file, defaults to <source>_stubs.go")
+	flag.StringVar(&cfg.workflows, "workflows", "", "name of the interface describing workflows, signals and queries")
+	flag.StringVar(&cfg.activities, "activities", "", "name of the interface describing activities")
+	flag.StringVar(&cfg.workflowPrefix, "workflowPrefix", "", "prefix of the registered workflow names")
+	flag.StringVar(&cfg.activityPrefix, "activityPrefix", "", "prefix of the registered activity names")
+	flag.Parse()
+
+	if err := run(&cfg); err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+}
+
+func run(cfg *config) error {
+	if cfg.source == "" || (cfg.workflows == "" && cfg.activities == "") {
+		return errors.New("-source and at least one of -workflows or -activities are required")
+	}
+	if cfg.output == "" {
+		cfg.output = strings.TrimSuffix(cfg.source, ".go") + "_stubs.go"
+	}
+	src, err := ioutil.ReadFile(cfg.source)
+	if err != nil {
+		return err
+	}
+	out, err := generate(cfg, cfg.source, src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cfg.output, out, 0644)
+}
+
+// generate returns the formatted stubs for the interfaces named in cfg, declared in src.
+func generate(cfg *config, filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		fset:       fset,
+		file:       file,
+		cfg:        cfg,
+		imports:    make(map[string]string),
+		usedImport: make(map[string]bool),
+		typeNames:  make(map[string]bool),
+	}
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		g.imports[name] = importPath
+	}
+
+	var body bytes.Buffer
+	if cfg.activities != "" {
+		methods, err := g.parseInterface(cfg.activities, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.writeActivities(&body, methods); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.workflows != "" {
+		methods, err := g.parseInterface(cfg.workflows, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.writeWorkflows(&body, methods); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by stubgen from %s. DO NOT EDIT.\n\n", path.Base(filename))
+	fmt.Fprintf(&buf, "package %s\n\n", file.Name.Name)
+	buf.WriteString("import (\n")
+	for _, group := range g.importGroups() {
+		for _, importPath := range group {
+			fmt.Fprintf(&buf, "\t%q\n", importPath)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// parseInterface returns the methods of the named interface.
+func (g *generator) parseInterface(name string, isWorkflow bool) ([]*method, error) {
+	var iface *ast.InterfaceType
+	ast.Inspect(g.file, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Name == name {
+			iface, _ = ts.Type.(*ast.InterfaceType)
+		}
+		return iface == nil
+	})
+	if iface == nil {
+		return nil, fmt.Errorf("interface %v not found in %v", name, g.cfg.source)
+	}
+
+	var methods []*method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%v: embedded interfaces are not supported", name)
+		}
+		m, err := g.parseMethod(field.Names[0].Name, fn, field.Doc, isWorkflow)
+		if err != nil {
+			return nil, fmt.Errorf("%v.%v: %v", name, field.Names[0].Name, err)
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+func (g *generator) parseMethod(name string, fn *ast.FuncType, doc *ast.CommentGroup, isWorkflow bool) (*method, error) {
+	m := &method{name: name, kind: kindActivity}
+	if isWorkflow {
+		m.kind = kindWorkflow
+		if doc != nil {
+			for _, line := range strings.Split(doc.Text(), "\n") {
+				switch strings.TrimSpace(line) {
+				case signalDirective:
+					m.kind = kindSignal
+				case queryDirective:
+					m.kind = kindQuery
+				}
+			}
+		}
+	}
+
+	var params []param
+	for _, field := range fn.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return nil, errors.New("variadic parameters are not supported")
+		}
+		typ := g.typeString(field.Type)
+		if len(field.Names) == 0 {
+			params = append(params, param{typ: typ, expr: field.Type})
+		}
+		for _, n := range field.Names {
+			params = append(params, param{name: n.Name, typ: typ, expr: field.Type})
+		}
+	}
+	switch {
+	case m.kind == kindWorkflow:
+		if len(params) == 0 || params[0].typ != "workflow.Context" {
+			return nil, errors.New("workflow must take workflow.Context as its first parameter")
+		}
+		params = params[1:]
+	case m.kind == kindActivity:
+		if len(params) > 0 && params[0].typ == "context.Context" {
+			params = params[1:]
+		}
+	case m.kind == kindSignal && len(params) > 1:
+		return nil, errors.New("signal must take at most one parameter")
+	}
+	for i := range params {
+		// only the parameters emitted by the stubs need their imports
+		g.useTypeImports(params[i].expr)
+		if params[i].name == "" || params[i].name == "_" {
+			params[i].name = fmt.Sprintf("arg%d", i)
+		} else if reservedNames[params[i].name] {
+			params[i].name += "Arg"
+		}
+	}
+	m.params = params
+
+	var results []string
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			typ := g.typeString(field.Type)
+			g.useTypeImports(field.Type)
+			for i := 0; i < len(field.Names) || (i == 0 && len(field.Names) == 0); i++ {
+				results = append(results, typ)
+			}
+		}
+	}
+	if m.kind == kindSignal {
+		if len(results) != 0 {
+			return nil, errors.New("signal must not return results")
+		}
+		return m, nil
+	}
+	switch len(results) {
+	case 1:
+		if m.kind == kindQuery {
+			return nil, errors.New("query must return (T, error)")
+		}
+	case 2:
+		m.resultType = results[0]
+	default:
+		return nil, errors.New("must return either error or (T, error)")
+	}
+	if results[len(results)-1] != "error" {
+		return nil, errors.New("last result must be error")
+	}
+	return m, nil
+}
+
+// typeString renders a type expression as written in the source.
+func (g *generator) typeString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, expr)
+	return buf.String()
+}
+
+// useTypeImports records the imports a type expression emitted in the generated code refers to.
+func (g *generator) useTypeImports(expr ast.Expr) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				g.useImport(ident.Name)
+			}
+		}
+		return true
+	})
+}
+
+func (g *generator) useImport(name string) {
+	g.usedImport[name] = true
+}
+
+// importGroups returns the standard library and the other imports of the generated code, each sorted.
+func (g *generator) importGroups() [2][]string {
+	defaults := map[string]string{
+		"context":  "context",
+		"client":   "go.uber.org/cadence/client",
+		"workflow": "go.uber.org/cadence/workflow",
+	}
+	var groups [2][]string
+	for name := range g.usedImport {
+		importPath, ok := g.imports[name]
+		if !ok {
+			if importPath, ok = defaults[name]; !ok {
+				continue
+			}
+		}
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			groups[1] = append(groups[1], importPath)
+		} else {
+			groups[0] = append(groups[0], importPath)
+		}
+	}
+	sort.Strings(groups[0])
+	sort.Strings(groups[1])
+	return groups
+}
+
+// declareType reserves name for a generated type so two methods never produce clashing declarations.
+func (g *generator) declareType(name string) error {
+	if g.typeNames[name] {
+		return fmt.Errorf("generated type %v is declared twice", name)
+	}
+	g.typeNames[name] = true
+	return nil
+}
+
+func (g *generator) writeActivities(w *bytes.Buffer, methods []*method) error {
+	stub := g.cfg.activities + "Stub"
+	if err := g.declareType(stub); err != nil {
+		return err
+	}
+	g.useImport("workflow")
+	fmt.Fprintf(w, "\n// %s schedules the activities of %s from workflow code.\n", stub, g.cfg.activities)
+	fmt.Fprintf(w, "type %s struct{}\n", stub)
+
+	for _, m := range methods {
+		future := m.name + "Future"
+		if err := g.declareType(future); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n// %s is the result of the %s activity.\n", future, m.name)
+		fmt.Fprintf(w, "type %s struct{ workflow.Future }\n", future)
+		writeGet(w, future, "workflow.Context", "f.Future", m.resultType, "activity")
+
+		fmt.Fprintf(w, "\n// %s schedules the %s activity.\n", m.name, m.name)
+		fmt.Fprintf(w, "func (%s) %s(ctx workflow.Context%s) %s {\n", stub, m.name, paramList(m.params), future)
+		fmt.Fprintf(w, "\treturn %s{workflow.ExecuteActivity(ctx, %q%s)}\n}\n", future, g.cfg.activityPrefix+m.name, argList(m.params))
+	}
+	return nil
+}
+
+func (g *generator) writeWorkflows(w *bytes.Buffer, methods []*method) error {
+	stub := g.cfg.workflows + "Stub"
+	clientStub := g.cfg.workflows + "Client"
+	if err := g.declareType(stub); err != nil {
+		return err
+	}
+	if err := g.declareType(clientStub); err != nil {
+		return err
+	}
+	g.useImport("context")
+	g.useImport("client")
+	g.useImport("workflow")
+	fmt.Fprintf(w, "\n// %s starts the workflows of %s as child workflows from workflow code.\n", stub, g.cfg.workflows)
+	fmt.Fprintf(w, "type %s struct{}\n", stub)
+	fmt.Fprintf(w, "\n// %s starts, signals and queries the workflows of %s.\n", clientStub, g.cfg.workflows)
+	fmt.Fprintf(w, "type %s struct {\n\tClient client.Client\n}\n", clientStub)
+	fmt.Fprintf(w, "\n// New%s returns a %s using c.\n", clientStub, clientStub)
+	fmt.Fprintf(w, "func New%s(c client.Client) *%s {\n\treturn &%s{Client: c}\n}\n", clientStub, clientStub, clientStub)
+
+	for _, m := range methods {
+		name := g.cfg.workflowPrefix + m.name
+		switch m.kind {
+		case kindWorkflow:
+			future, run := m.name+"ChildFuture", m.name+"Run"
+			if err := g.declareType(future); err != nil {
+				return err
+			}
+			if err := g.declareType(run); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\n// %s is the result of the %s child workflow.\n", future, m.name)
+			fmt.Fprintf(w, "type %s struct{ workflow.ChildWorkflowFuture }\n", future)
+			writeGet(w, future, "workflow.Context", "f.ChildWorkflowFuture", m.resultType, "child workflow")
+
+			fmt.Fprintf(w, "\n// %s starts the %s workflow as a child workflow.\n", m.name, m.name)
+			fmt.Fprintf(w, "func (%s) %s(ctx workflow.Context%s) %s {\n", stub, m.name, paramList(m.params), future)
+			fmt.Fprintf(w, "\treturn %s{workflow.ExecuteChildWorkflow(ctx, %q%s)}\n}\n", future, name, argList(m.params))
+
+			fmt.Fprintf(w, "\n// %s is a run of the %s workflow started by a client.\n", run, m.name)
+			fmt.Fprintf(w, "type %s struct{ client.WorkflowRun }\n", run)
+			writeGet(w, run, "context.Context", "f.WorkflowRun", m.resultType, "workflow")
+
+			fmt.Fprintf(w, "\n// %s starts the %s workflow.\n", m.name, m.name)
+			fmt.Fprintf(w, "func (c *%s) %s(ctx context.Context, options client.StartWorkflowOptions%s) (%s, error) {\n",
+				clientStub, m.name, paramList(m.params), run)
+			fmt.Fprintf(w, "\trun, err := c.Client.ExecuteWorkflow(ctx, options, %q%s)\n", name, argList(m.params))
+			fmt.Fprintf(w, "\treturn %s{run}, err\n}\n", run)
+		case kindSignal:
+			arg := "nil"
+			if len(m.params) == 1 {
+				arg = m.params[0].name
+			}
+			fmt.Fprintf(w, "\n// Signal%s sends the %s signal to a workflow.\n", m.name, m.name)
+			fmt.Fprintf(w, "func (c *%s) Signal%s(ctx context.Context, workflowID string, runID string%s) error {\n",
+				clientStub, m.name, paramList(m.params))
+			fmt.Fprintf(w, "\treturn c.Client.SignalWorkflow(ctx, workflowID, runID, %q, %s)\n}\n", m.name, arg)
+		case kindQuery:
+			fmt.Fprintf(w, "\n// Query%s runs the %s query against a workflow.\n", m.name, m.name)
+			fmt.Fprintf(w, "func (c *%s) Query%s(ctx context.Context, workflowID string, runID string%s) (%s, error) {\n",
+				clientStub, m.name, paramList(m.params), m.resultType)
+			fmt.Fprintf(w, "\tvar result %s\n", m.resultType)
+			fmt.Fprintf(w, "\tvalue, err := c.Client.QueryWorkflow(ctx, workflowID, runID, %q%s)\n", m.name, argList(m.params))
+			fmt.Fprintf(w, "\tif err != nil {\n\t\treturn result, err\n\t}\n")
+			fmt.Fprintf(w, "\terr = value.Get(&result)\n\treturn result, err\n}\n")
+		}
+	}
+	return nil
+}
+
+// writeGet renders the typed Get method of a future like type embedding an untyped one.
+func writeGet(w *bytes.Buffer, typeName, ctxType, embedded, resultType, what string) {
+	fmt.Fprintf(w, "\n// Get blocks until the %s completes and returns its result.\n", what)
+	if resultType == "" {
+		fmt.Fprintf(w, "func (f %s) Get(ctx %s) error {\n\treturn %s.Get(ctx, nil)\n}\n", typeName, ctxType, embedded)
+		return
+	}
+	fmt.Fprintf(w, "func (f %s) Get(ctx %s) (%s, error) {\n", typeName, ctxType, resultType)
+	fmt.Fprintf(w, "\tvar result %s\n\terr := %s.Get(ctx, &result)\n\treturn result, err\n}\n", resultType, embedded)
+}
+
+func paramList(params []param) string {
+	var buf bytes.Buffer
+	for _, p := range params {
+		fmt.Fprintf(&buf, ", %s %s", p.name, p.typ)
+	}
+	return buf.String()
+}
+
+func argList(params []param) string {
+	var buf bytes.Buffer
+	for _, p := range params {
+		fmt.Fprintf(&buf, ", %s", p.name)
+	}
+	return buf.String()
+}
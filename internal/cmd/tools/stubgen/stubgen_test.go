@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `package payments
+
+import (
+	"context"
+
+	"go.uber.org/cadence/workflow"
+)
+
+type PaymentActivities interface {
+	Charge(ctx context.Context, amount int) (*Receipt, error)
+	Refund(id string) error
+}
+
+type PaymentWorkflows interface {
+	Checkout(ctx workflow.Context, order *Order) (*Receipt, error)
+	// cadence:signal
+	Approve(approved bool)
+	// cadence:query
+	Status() (string, error)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	cfg := &config{source: "payments.go", activities: "PaymentActivities", workflows: "PaymentWorkflows", activityPrefix: "Payment"}
+	out, err := generate(cfg, cfg.source, []byte(testSource))
+	require.NoError(t, err)
+	code := string(out)
+
+	require.Contains(t, code, "package payments")
+	require.Contains(t, code, `"go.uber.org/cadence/client"`)
+	require.Contains(t, code, "func (f ChargeFuture) Get(ctx workflow.Context) (*Receipt, error)")
+	require.Contains(t, code, `workflow.ExecuteActivity(ctx, "PaymentCharge", amount)`)
+	require.Contains(t, code, "func (f RefundFuture) Get(ctx workflow.Context) error")
+	require.Contains(t, code, `workflow.ExecuteChildWorkflow(ctx, "Checkout", order)`)
+	require.Contains(t, code, "func (c *PaymentWorkflowsClient) Checkout(ctx context.Context, options client.StartWorkflowOptions, order *Order) (CheckoutRun, error)")
+	require.Contains(t, code, `c.Client.SignalWorkflow(ctx, workflowID, runID, "Approve", approved)`)
+	require.Contains(t, code, "func (c *PaymentWorkflowsClient) QueryStatus(ctx context.Context, workflowID string, runID string) (string, error)")
+}
+
+func TestGenerateActivitiesOnly(t *testing.T) {
+	cfg := &config{source: "payments.go", activities: "PaymentActivities"}
+	out, err := generate(cfg, cfg.source, []byte(testSource))
+	require.NoError(t, err)
+	code := string(out)
+
+	// the context.Context parameter of the activities is dropped from the stubs, so is its import
+	require.NotContains(t, code, `"context"`)
+	require.NotContains(t, code, `"go.uber.org/cadence/client"`)
+	require.Contains(t, code, `"go.uber.org/cadence/workflow"`)
+	require.Contains(t, code, "func (PaymentActivitiesStub) Charge(ctx workflow.Context, amount int) ChargeFuture")
+	_, err = parser.ParseFile(token.NewFileSet(), cfg.source, out, 0)
+	require.NoError(t, err)
+}
+
+func TestGenerateInvalidInterface(t *testing.T) {
+	tests := map[string]string{
+		"Missing":         "",
+		"NoContext":       "Checkout(order string) error",
+		"NoError":         "Checkout(ctx workflow.Context) string",
+		"Variadic":        "Checkout(ctx workflow.Context, items ...string) error",
+		"SignalWithError": "// cadence:signal\n\tApprove(approved bool) error",
+	}
+	for name, method := range tests {
+		src := "package payments\n\nimport \"go.uber.org/cadence/workflow\"\n\ntype PaymentWorkflows interface {\n\t" + method + "\n}\n"
+		if name == "Missing" {
+			src = "package payments\n"
+		}
+		_, err := generate(&config{workflows: "PaymentWorkflows"}, "payments.go", []byte(src))
+		require.Error(t, err, name)
+	}
+}
@@ -242,6 +242,8 @@ func parentCancelCtx(parent Context) (*cancelCtx, bool) {
 		switch c := parent.(type) {
 		case *cancelCtx:
 			return c, true
+		case *cancellationScopeCtx:
+			return c.cancelCtx, true
 		// TODO: Uncomment once timer story is implemented
 		//case *timerCtx:
 		//	return c.cancelCtx, true
@@ -316,6 +318,151 @@ func (c *cancelCtx) cancel(removeFromParent bool, err error) {
 	}
 }
 
+// CancellationScopeOptions configures a CancellationScope.
+type CancellationScopeOptions struct {
+	// WaitForCancellation - Whether CancellationScope.Cancel blocks until every activity, child workflow and timer
+	// started under the scope is completed. The activities and child workflows of such a scope are started with
+	// WaitForCancellation set, so Cancel returns once the cancellation is acknowledged by them.
+	// Optional: default false
+	WaitForCancellation bool
+}
+
+// CancellationScope groups the activities, local activities, child workflows and timers started with its context,
+// or with a context derived from it, so they can be canceled together. Use workflow.NewCancellationScope to create
+// one, or workflow.NewDetachedCancellationScope for cleanup code which must run after the workflow is canceled.
+type CancellationScope interface {
+	// Context returns the context of the scope. The operations started with it are tracked by the scope.
+	Context() Context
+
+	// Cancel cancels the context of the scope, which cancels the tracked operations that are not completed yet and
+	// the scopes created from it which are not detached. If the scope was created with WaitForCancellation, Cancel
+	// blocks the calling coroutine until they acknowledged the cancellation. ctx is the context of the caller, it is
+	// only used to block.
+	Cancel(ctx Context)
+
+	// IsCanceled returns true once the scope is canceled, by Cancel or by the cancellation of its parent context.
+	IsCanceled() bool
+
+	// Wait blocks the calling coroutine until every operation tracked by the scope and by the scopes created from it
+	// which are not detached is completed, failed or canceled.
+	Wait(ctx Context)
+}
+
+// cancellationScopeCtx is the Context of a CancellationScope. It is a cancelCtx which tracks the futures of the
+// operations started under it.
+type cancellationScopeCtx struct {
+	*cancelCtx
+
+	waitForCancellation bool
+	futures             []Future                // not ready futures of the operations started under the scope
+	children            []*cancellationScopeCtx // scopes created from this one which are not detached
+}
+
+var _ CancellationScope = (*cancellationScopeCtx)(nil)
+
+// NewCancellationScope returns a CancellationScope whose context is a child of parent. The scope is canceled when
+// its Cancel method is called or when parent is canceled, whichever happens first.
+func NewCancellationScope(parent Context, options CancellationScopeOptions) CancellationScope {
+	s := newCancellationScopeCtx(parent, options)
+	propagateCancel(parent, s)
+	if p := getCancellationScope(parent); p != nil {
+		p.children = append(p.children, s)
+	}
+	return s
+}
+
+// NewDetachedCancellationScope returns a CancellationScope which, like NewDisconnectedContext, is not canceled when
+// parent is canceled and is not canceled or waited for by the scope of parent. Use it to run cleanup code after the
+// workflow or an enclosing scope is canceled.
+func NewDetachedCancellationScope(parent Context, options CancellationScopeOptions) CancellationScope {
+	return newCancellationScopeCtx(parent, options)
+}
+
+func newCancellationScopeCtx(parent Context, options CancellationScopeOptions) *cancellationScopeCtx {
+	return &cancellationScopeCtx{
+		cancelCtx:           newCancelCtx(parent),
+		waitForCancellation: options.WaitForCancellation,
+	}
+}
+
+// getCancellationScope returns the innermost scope ctx belongs to, nil if ctx was not created from a scope.
+func getCancellationScope(ctx Context) *cancellationScopeCtx {
+	s, _ := ctx.Value(cancellationScopeContextKey).(*cancellationScopeCtx)
+	return s
+}
+
+// trackFuture adds the future of an operation started with ctx to the scope ctx belongs to.
+func trackFuture(ctx Context, f Future) {
+	if s := getCancellationScope(ctx); s != nil {
+		s.track(f)
+	}
+}
+
+func (s *cancellationScopeCtx) Value(key interface{}) interface{} {
+	if key == cancellationScopeContextKey {
+		return s
+	}
+	return s.cancelCtx.Value(key)
+}
+
+func (s *cancellationScopeCtx) String() string {
+	return fmt.Sprintf("%v.WithCancellationScope", s.cancelCtx.Context)
+}
+
+func (s *cancellationScopeCtx) cancel(removeFromParent bool, err error) {
+	s.cancelCtx.cancel(false, err)
+	if removeFromParent {
+		// Remove this scope from its parent cancelCtx's children.
+		removeChild(s.cancelCtx.Context, s)
+	}
+}
+
+func (s *cancellationScopeCtx) track(f Future) {
+	// drop the futures which are ready, so a long running scope does not hold on to all of them
+	futures := s.futures[:0]
+	for _, tracked := range s.futures {
+		if !tracked.IsReady() {
+			futures = append(futures, tracked)
+		}
+	}
+	s.futures = append(futures, f)
+}
+
+func (s *cancellationScopeCtx) Context() Context {
+	return s
+}
+
+func (s *cancellationScopeCtx) Cancel(ctx Context) {
+	s.cancel(true, ErrCanceled)
+	if s.waitForCancellation {
+		s.Wait(ctx)
+	}
+}
+
+func (s *cancellationScopeCtx) IsCanceled() bool {
+	return s.Err() != nil
+}
+
+func (s *cancellationScopeCtx) Wait(ctx Context) {
+	// operations can be started under the scope while waiting, so the pending futures are looked up again after
+	// every wait
+	for f := s.nextPendingFuture(); f != nil; f = s.nextPendingFuture() {
+		f.Get(ctx, nil)
+	}
+	for i := 0; i < len(s.children); i++ {
+		s.children[i].Wait(ctx)
+	}
+}
+
+func (s *cancellationScopeCtx) nextPendingFuture() Future {
+	for _, f := range s.futures {
+		if !f.IsReady() {
+			return f
+		}
+	}
+	return nil
+}
+
 // Commented out until workflow time API is exposed.
 // WithDeadline returns a copy of the parent context with the deadline adjusted
 // to be no later than d.  If the parent's deadline is already earlier than d,
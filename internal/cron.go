@@ -0,0 +1,311 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+// All code in this file is private to the package.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/zap"
+)
+
+// cronScheduleHeaderKey is the header field carrying the cron schedule of a workflow. The server has no notion of
+// cron, so the schedule rides along with the workflow input and is carried over to every continued run.
+const cronScheduleHeaderKey = "cadence-cron-schedule"
+
+// cronLastFailureHeaderKey is the header field carrying the error of the previous run of a cron workflow to the next
+// run, if the previous run failed.
+const cronLastFailureHeaderKey = "cadence-cron-last-failure"
+
+// maxCronSearchYears bounds the search for the next fire time so schedules that can never fire, like "0 0 30 2 *",
+// fail instead of looping forever.
+const maxCronSearchYears = 5
+
+type (
+	// cronSchedule is a parsed standard five field cron expression: minute, hour, day of month, month, day of week.
+	cronSchedule struct {
+		minute, hour, dom, month, dow uint64 // bit i is set if value i matches
+		domStar, dowStar              bool   // day of month / day of week was not restricted
+	}
+
+	cronField struct {
+		min, max int
+		names    map[string]int
+	}
+)
+
+var (
+	cronMinute = cronField{min: 0, max: 59}
+	cronHour   = cronField{min: 0, max: 23}
+	cronDom    = cronField{min: 1, max: 31}
+	cronMonth  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// day of week accepts 7 as an alias of sunday.
+	cronDow = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// parseCronSchedule parses a standard cron expression, e.g. "*/15 9-17 * * mon-fri", or one of the descriptors
+// @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly. Schedules are evaluated in UTC.
+func parseCronSchedule(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron schedule %q: expected 5 fields, found %d", spec, len(fields))
+	}
+
+	var err error
+	c := &cronSchedule{}
+	if c.minute, _, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule %q: minute: %v", spec, err)
+	}
+	if c.hour, _, err = cronHour.parse(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule %q: hour: %v", spec, err)
+	}
+	if c.dom, c.domStar, err = cronDom.parse(fields[2]); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule %q: day of month: %v", spec, err)
+	}
+	if c.month, _, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule %q: month: %v", spec, err)
+	}
+	if c.dow, c.dowStar, err = cronDow.parse(fields[4]); err != nil {
+		return nil, fmt.Errorf("invalid cron schedule %q: day of week: %v", spec, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parse parses a comma separated list of values, ranges and steps into a bit set. The returned bool reports if the
+// field was a wildcard.
+func (f cronField) parse(expr string) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			low, high = f.min, f.max
+			star = star || step == 1
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if low, err = f.value(bounds[0]); err != nil {
+				return 0, false, err
+			}
+			if high, err = f.value(bounds[1]); err != nil {
+				return 0, false, err
+			}
+			if low > high {
+				return 0, false, fmt.Errorf("invalid range %q", rangeExpr)
+			}
+		default:
+			var err error
+			if low, err = f.value(rangeExpr); err != nil {
+				return 0, false, err
+			}
+			high = low
+			if step > 1 {
+				// "a/n" means every n starting at a.
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func (f cronField) value(expr string) (int, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first fire time strictly after t, or the zero time if the schedule never fires.
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxCronSearchYears, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay follows the cron convention: if both day of month and day of week are restricted, a day matching
+// either of them fires.
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// withCronSchedule adds the cron schedule to the header carried in front of input.
+func withCronSchedule(input []byte, cronSchedule string) ([]byte, error) {
+	if cronSchedule == "" {
+		return input, nil
+	}
+	return withCronHeader(input, cronScheduleHeaderKey, cronSchedule)
+}
+
+// withCronHeader sets the field key of the header carried in front of input to value, or removes it if value is
+// empty.
+func withCronHeader(input []byte, key, value string) ([]byte, error) {
+	header, input := decodeHeaderFromInput(input)
+	if header == nil {
+		header = &s.Header{}
+	}
+	if value == "" {
+		delete(header.Fields, key)
+	} else {
+		NewHeaderWriter(header).Set(key, []byte(value))
+	}
+	return encodeHeaderIntoInput(header, input)
+}
+
+// getCronHeader returns the field key of header, if any.
+func getCronHeader(header *s.Header, key string) string {
+	if header == nil {
+		return ""
+	}
+	return string(header.Fields[key])
+}
+
+// getCronSchedule returns the cron schedule carried by header, if any.
+func getCronSchedule(header *s.Header) string {
+	return getCronHeader(header, cronScheduleHeaderKey)
+}
+
+// executeCronWorkflow waits for the next fire time of the schedule on a workflow timer, runs the workflow once and
+// continues as new with the same input, so the next run waits for the following fire time. The error of a failed
+// run is passed to the next run, see WorkflowInfo.CronLastFailure.
+func executeCronWorkflow(ctx Context, workflow workflow, schedule string, rawInput, input []byte) ([]byte, error) {
+	cron, err := parseCronSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	info := GetWorkflowInfo(ctx)
+	now := Now(ctx)
+	scheduledTime := cron.Next(now)
+	if scheduledTime.IsZero() {
+		return nil, fmt.Errorf("cron schedule %q does not fire within %d years", schedule, maxCronSearchYears)
+	}
+	// The wait for the fire time is part of the run, fail right away instead of timing out while waiting.
+	timeout := time.Duration(info.ExecutionStartToCloseTimeoutSeconds) * time.Second
+	if wait := scheduledTime.Sub(now); wait >= timeout {
+		return nil, fmt.Errorf("cron schedule %q next fires in %v, which exceeds the ExecutionStartToCloseTimeout of %v",
+			schedule, wait, timeout)
+	}
+	if err := Sleep(ctx, scheduledTime.Sub(now)); err != nil {
+		return nil, err
+	}
+
+	info.CronScheduledTime = scheduledTime
+	result, err := workflow.Execute(ctx, input)
+	if _, ok := err.(*ContinueAsNewError); ok {
+		return result, err
+	}
+	if ctx.Err() != nil {
+		// the workflow was canceled, stop the schedule.
+		return result, err
+	}
+	lastFailure := ""
+	if err != nil {
+		GetLogger(ctx).Warn("Cron workflow run failed, continuing with the next scheduled run.",
+			zap.Time("CronScheduledTime", scheduledTime), zap.Error(err))
+		lastFailure = err.Error()
+	}
+	if rawInput, err = withCronHeader(rawInput, cronLastFailureHeaderKey, lastFailure); err != nil {
+		return nil, err
+	}
+
+	options := getWorkflowEnvOptions(ctx)
+	if options == nil {
+		return nil, errors.New("context is missing required options for continue as new")
+	}
+	params := &executeWorkflowParams{
+		workflowOptions: *options,
+		workflowType:    &info.WorkflowType,
+		input:           rawInput,
+	}
+	return nil, &ContinueAsNewError{wfn: info.WorkflowType.Name, params: params}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// Saturday
+	now := time.Date(2018, time.September, 15, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2018, time.September, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2018, time.September, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * mon-fri", time.Date(2018, time.September, 17, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1,15 * *", time.Date(2018, time.October, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, time.September, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2018, time.September, 21, 0, 0, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2018, time.September, 15, 10, 25, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, time.September, 15, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2018, time.September, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		cron, err := parseCronSchedule(tt.spec)
+		require.NoError(t, err, tt.spec)
+		require.Equal(t, tt.next, cron.Next(now), tt.spec)
+	}
+}
+
+func TestCronScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@every"} {
+		_, err := parseCronSchedule(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestWithCronSchedule(t *testing.T) {
+	input := []byte("input")
+	data, err := withCronSchedule(input, "")
+	require.NoError(t, err)
+	require.Equal(t, input, data)
+
+	data, err = withCronSchedule(input, "@daily")
+	require.NoError(t, err)
+	header, decodedInput := decodeHeaderFromInput(data)
+	require.Equal(t, input, decodedInput)
+	require.Equal(t, "@daily", getCronSchedule(header))
+}
@@ -21,6 +21,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -123,6 +124,33 @@ type (
 		args   []interface{}
 		params *executeWorkflowParams
 	}
+
+	// QueryFailedError returned by Client.QueryWorkflowWithOptions when the query handler of the workflow failed.
+	// When the handler returned a *CustomError, its reason and details are available on this error.
+	QueryFailedError struct {
+		message string
+		reason  string
+		details encoded.Values
+	}
+
+	// WorkflowContinuedAsNewError returned by WorkflowRun.GetWithOptions when the run continued as new and following
+	// the runs is disabled.
+	WorkflowContinuedAsNewError struct {
+		newRunID string
+	}
+
+	// CompensationError returned by Saga.Compensate when compensations failed. It holds the error of every failed
+	// compensation in the order the compensations were executed.
+	CompensationError struct {
+		errors []error
+	}
+
+	// queryFailure is appended to the error message of a query failed with a *CustomError, the details are encoded
+	// with the DataConverter of the worker.
+	queryFailure struct {
+		Reason  string `json:"reason"`
+		Details []byte `json:"details,omitempty"`
+	}
 )
 
 const (
@@ -130,6 +158,10 @@ const (
 	errReasonGeneric  = "cadenceInternal:Generic"
 	errReasonCanceled = "cadenceInternal:Canceled"
 	errReasonTimeout  = "cadenceInternal:Timeout"
+
+	// queryFailureSeparator separates the error message of a failed query from the encoded queryFailure, so that the
+	// error message starts with the human readable message of the error.
+	queryFailureSeparator = "\ncadenceInternal:QueryFailed:"
 )
 
 // ErrNoData is returned when trying to extract strong typed data while there is no data available.
@@ -204,7 +236,16 @@ func NewContinueAsNewError(ctx Context, wfn interface{}, args ...interface{}) *C
 	if options == nil {
 		panic("context is missing required options for continue as new")
 	}
-	workflowType, input, err := getValidatedWorkflowFunction(wfn, args, options.dataConverter)
+	workflowType, input, err := getValidatedWorkflowFunction(wfn, args, options.dataConverter, getWorkflowEnvironment(ctx).GetRegistry())
+	if err != nil {
+		panic(err)
+	}
+	input, err = injectHeaderFromWorkflowContext(ctx, input)
+	if err != nil {
+		panic(err)
+	}
+	// a cron workflow keeps its schedule when it continues as new.
+	input, err = withCronSchedule(input, GetWorkflowInfo(ctx).CronSchedule)
 	if err != nil {
 		panic(err)
 	}
@@ -323,3 +364,88 @@ func newTerminatedError() *TerminatedError {
 func (e *TerminatedError) Error() string {
 	return "Terminated"
 }
+
+// Error from error interface
+func (e *QueryFailedError) Error() string {
+	return e.message
+}
+
+// Reason gets the reason of the *CustomError returned by the query handler, empty if the handler returned another
+// error.
+func (e *QueryFailedError) Reason() string {
+	return e.reason
+}
+
+// HasDetails return if this error has strong typed detail data.
+func (e *QueryFailedError) HasDetails() bool {
+	return e.details != nil && e.details.HasValues()
+}
+
+// Details extracts strong typed detail data of the *CustomError returned by the query handler. If there is no
+// details, it will return ErrNoData.
+func (e *QueryFailedError) Details(d ...interface{}) error {
+	if !e.HasDetails() {
+		return ErrNoData
+	}
+	return e.details.Get(d...)
+}
+
+// Error from error interface
+func (e *WorkflowContinuedAsNewError) Error() string {
+	return "workflow continued as new, new run ID: " + e.newRunID
+}
+
+// NewRunID returns the run ID of the run started by continue as new.
+func (e *WorkflowContinuedAsNewError) NewRunID() string {
+	return e.newRunID
+}
+
+// Error from error interface
+func (e *CompensationError) Error() string {
+	messages := make([]string, len(e.errors))
+	for i, err := range e.errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d compensations failed: %v", len(e.errors), strings.Join(messages, "; "))
+}
+
+// Errors returns the errors of the failed compensations in the order the compensations were executed.
+func (e *CompensationError) Errors() []error {
+	return e.errors
+}
+
+// encodeQueryFailure creates the error message of a failed query task. The message starts with the message of the
+// error, the reason and details of a *CustomError are appended so that the client can return them with the
+// QueryFailedError.
+func encodeQueryFailure(err error, dataConverter encoded.DataConverter) string {
+	message := err.Error()
+	customErr, ok := err.(*CustomError)
+	if !ok {
+		return message
+	}
+	var failure queryFailure
+	failure.Reason, failure.Details = getErrorDetails(customErr, dataConverter)
+	data, err := json.Marshal(failure)
+	if err != nil {
+		return message
+	}
+	return message + queryFailureSeparator + string(data)
+}
+
+// decodeQueryFailure creates the QueryFailedError from the error message of a failed query, messages without an
+// encoded failure are kept as is.
+func decodeQueryFailure(message string, dataConverter encoded.DataConverter) *QueryFailedError {
+	i := strings.LastIndex(message, queryFailureSeparator)
+	if i < 0 {
+		return &QueryFailedError{message: message}
+	}
+	var failure queryFailure
+	if json.Unmarshal([]byte(message[i+len(queryFailureSeparator):]), &failure) != nil {
+		return &QueryFailedError{message: message}
+	}
+	queryErr := &QueryFailedError{message: message[:i], reason: failure.Reason}
+	if len(failure.Details) > 0 {
+		queryErr.details = newEncodedValues(failure.Details, dataConverter)
+	}
+	return queryErr
+}
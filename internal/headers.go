@@ -0,0 +1,267 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+)
+
+type (
+	// HeaderWriter is an interface to write information to cadence headers
+	HeaderWriter interface {
+		Set(string, []byte)
+	}
+
+	// HeaderReader is an interface to read information from cadence headers
+	HeaderReader interface {
+		ForEachKey(handler func(string, []byte) error) error
+	}
+
+	// ContextPropagator is an interface that determines what information from context to pass along.
+	// Values are written to a header on the caller side (client or workflow) and read back from it on the
+	// callee side (workflow or activity). A propagator must be registered on both the client, via
+	// ClientOptions.ContextPropagators, and the worker, via WorkerOptions.ContextPropagators.
+	// The cadence service has no header field for starting workflows and activities or for signals, so the header
+	// is carried in an envelope in front of the encoded input. This changes the input stored by the server, thus
+	// headers are only sent when ClientOptions.EnableHeaderEnvelope and WorkerOptions.EnableHeaderEnvelope are set.
+	// Local activities always receive the header as they run in the worker process.
+	ContextPropagator interface {
+		// Inject injects information from a Go Context into headers
+		Inject(context.Context, HeaderWriter) error
+
+		// Extract extracts context information from headers and returns a context
+		// object
+		Extract(context.Context, HeaderReader) (context.Context, error)
+
+		// InjectFromWorkflow injects information from workflow context into headers
+		InjectFromWorkflow(Context, HeaderWriter) error
+
+		// ExtractToWorkflow extracts context information from headers and returns
+		// a workflow context
+		ExtractToWorkflow(Context, HeaderReader) (Context, error)
+	}
+
+	headerReader struct {
+		header *s.Header
+	}
+
+	headerWriter struct {
+		header *s.Header
+	}
+)
+
+// headerEnvelopePrefix marks an input payload which carries a propagated header in front of the encoded arguments.
+// The start, signal, schedule activity and start child workflow requests have no header field, so the header
+// travels inside the input and is stripped off before the arguments are decoded. The leading zero byte keeps the
+// prefix from colliding with JSON or thrift encoded arguments.
+// The prefix is followed by the version of the envelope, the size of the JSON encoded header fields and the fields,
+// then the size of the input and the input, both sizes as big endian uint32. Data is only taken as an envelope if
+// the sizes add up to its length exactly, and an input which happens to start with the prefix is always wrapped in
+// an envelope, so that any input is read back as it was written.
+// Workers always strip the envelope, but it is only written when EnableHeaderEnvelope is set: anything else that
+// reads the input, like workers written in other languages, the CLI or tools reading the history, sees the
+// envelope as part of the input.
+var headerEnvelopePrefix = []byte("\x00cadence-header\x00")
+
+// headerEnvelopeVersion is the version of the envelope written by encodeHeaderIntoInput.
+const headerEnvelopeVersion byte = 1
+
+// NewHeaderReader returns a header reader interface
+func NewHeaderReader(header *s.Header) HeaderReader {
+	return &headerReader{header}
+}
+
+// NewHeaderWriter returns a header writer interface
+func NewHeaderWriter(header *s.Header) HeaderWriter {
+	if header != nil && header.Fields == nil {
+		header.Fields = make(map[string][]byte)
+	}
+	return &headerWriter{header}
+}
+
+func (hr *headerReader) ForEachKey(handler func(string, []byte) error) error {
+	if hr.header == nil {
+		return nil
+	}
+	for key, value := range hr.header.Fields {
+		if err := handler(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hw *headerWriter) Set(key string, value []byte) {
+	if hw.header == nil {
+		return
+	}
+	hw.header.Fields[key] = value
+}
+
+// encodeHeaderIntoInput wraps input in an envelope with header. The input is returned unchanged if header is empty,
+// so callers without context propagators produce exactly the same payload as before, unless the input starts with
+// headerEnvelopePrefix and would be mistaken for an envelope.
+func encodeHeaderIntoInput(header *s.Header, input []byte) ([]byte, error) {
+	var fields []byte
+	if header != nil && len(header.Fields) > 0 {
+		var err error
+		if fields, err = json.Marshal(header.Fields); err != nil {
+			return nil, fmt.Errorf("unable to encode header: %v", err)
+		}
+	} else if !bytes.HasPrefix(input, headerEnvelopePrefix) {
+		return input, nil
+	}
+	if uint64(len(fields)) > math.MaxUint32 || uint64(len(input)) > math.MaxUint32 {
+		return nil, errors.New("input is too large for a header envelope")
+	}
+
+	var size [4]byte
+	var buf bytes.Buffer
+	buf.Grow(len(headerEnvelopePrefix) + 1 + 2*len(size) + len(fields) + len(input))
+	buf.Write(headerEnvelopePrefix)
+	buf.WriteByte(headerEnvelopeVersion)
+	binary.BigEndian.PutUint32(size[:], uint32(len(fields)))
+	buf.Write(size[:])
+	buf.Write(fields)
+	binary.BigEndian.PutUint32(size[:], uint32(len(input)))
+	buf.Write(size[:])
+	buf.Write(input)
+	return buf.Bytes(), nil
+}
+
+// decodeHeaderFromInput splits data produced by encodeHeaderIntoInput back into header and input. Data which is not
+// a well formed envelope is returned as is with a nil header.
+func decodeHeaderFromInput(data []byte) (*s.Header, []byte) {
+	if !bytes.HasPrefix(data, headerEnvelopePrefix) {
+		return nil, data
+	}
+	rest := data[len(headerEnvelopePrefix):]
+	if len(rest) < 1+4 || rest[0] != headerEnvelopeVersion {
+		return nil, data
+	}
+	rest = rest[1:]
+	fieldsSize := uint64(binary.BigEndian.Uint32(rest))
+	rest = rest[4:]
+	if uint64(len(rest)) < fieldsSize+4 {
+		return nil, data
+	}
+	fields := rest[:fieldsSize]
+	rest = rest[fieldsSize:]
+	inputSize := uint64(binary.BigEndian.Uint32(rest))
+	rest = rest[4:]
+	if uint64(len(rest)) != inputSize {
+		return nil, data
+	}
+
+	var header *s.Header
+	if len(fields) > 0 {
+		header = &s.Header{}
+		if err := json.Unmarshal(fields, &header.Fields); err != nil {
+			return nil, data
+		}
+	}
+	if len(rest) == 0 {
+		return header, nil
+	}
+	return header, rest
+}
+
+// injectHeaderFromContext runs propagators against a Go context and prepends the resulting header to input.
+func injectHeaderFromContext(ctx context.Context, propagators []ContextPropagator, input []byte) ([]byte, error) {
+	if len(propagators) == 0 {
+		return input, nil
+	}
+	header := &s.Header{}
+	writer := NewHeaderWriter(header)
+	for _, propagator := range propagators {
+		if err := propagator.Inject(ctx, writer); err != nil {
+			return nil, err
+		}
+	}
+	return encodeHeaderIntoInput(header, input)
+}
+
+// workflowHeader runs propagators against a workflow context and returns the resulting header.
+func workflowHeader(ctx Context, propagators []ContextPropagator) (*s.Header, error) {
+	if len(propagators) == 0 {
+		return nil, nil
+	}
+	header := &s.Header{}
+	writer := NewHeaderWriter(header)
+	for _, propagator := range propagators {
+		if err := propagator.InjectFromWorkflow(ctx, writer); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// injectHeaderFromWorkflowContext runs the propagators of the worker against a workflow context and prepends the
+// resulting header to input. The input is returned unchanged unless the worker enabled the header envelope.
+func injectHeaderFromWorkflowContext(ctx Context, input []byte) ([]byte, error) {
+	env := getWorkflowEnvironment(ctx)
+	if !env.IsHeaderEnvelopeEnabled() {
+		return input, nil
+	}
+	header, err := workflowHeader(ctx, env.GetContextPropagators())
+	if err != nil {
+		return nil, err
+	}
+	return encodeHeaderIntoInput(header, input)
+}
+
+// extractHeaderToContext rehydrates header into a Go context using propagators.
+func extractHeaderToContext(ctx context.Context, propagators []ContextPropagator, header *s.Header) (context.Context, error) {
+	if header == nil {
+		return ctx, nil
+	}
+	reader := NewHeaderReader(header)
+	for _, propagator := range propagators {
+		var err error
+		if ctx, err = propagator.Extract(ctx, reader); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// extractHeaderToWorkflowContext rehydrates header into a workflow context using propagators.
+func extractHeaderToWorkflowContext(ctx Context, propagators []ContextPropagator, header *s.Header) (Context, error) {
+	if header == nil {
+		return ctx, nil
+	}
+	reader := NewHeaderReader(header)
+	for _, propagator := range propagators {
+		var err error
+		if ctx, err = propagator.ExtractToWorkflow(ctx, reader); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/.gen/go/shared"
+)
+
+const testTenantHeaderKey = "test-tenant"
+
+type testTenantContextKey struct{}
+
+// testTenantPropagator propagates a tenant string stored under testTenantContextKey.
+type testTenantPropagator struct{}
+
+func (p *testTenantPropagator) Inject(ctx context.Context, writer HeaderWriter) error {
+	if tenant, ok := ctx.Value(testTenantContextKey{}).(string); ok {
+		writer.Set(testTenantHeaderKey, []byte(tenant))
+	}
+	return nil
+}
+
+func (p *testTenantPropagator) Extract(ctx context.Context, reader HeaderReader) (context.Context, error) {
+	err := reader.ForEachKey(func(key string, value []byte) error {
+		if key == testTenantHeaderKey {
+			ctx = context.WithValue(ctx, testTenantContextKey{}, string(value))
+		}
+		return nil
+	})
+	return ctx, err
+}
+
+func (p *testTenantPropagator) InjectFromWorkflow(ctx Context, writer HeaderWriter) error {
+	if tenant, ok := ctx.Value(testTenantContextKey{}).(string); ok {
+		writer.Set(testTenantHeaderKey, []byte(tenant))
+	}
+	return nil
+}
+
+func (p *testTenantPropagator) ExtractToWorkflow(ctx Context, reader HeaderReader) (Context, error) {
+	err := reader.ForEachKey(func(key string, value []byte) error {
+		if key == testTenantHeaderKey {
+			ctx = WithValue(ctx, testTenantContextKey{}, string(value))
+		}
+		return nil
+	})
+	return ctx, err
+}
+
+func Test_HeaderEnvelope_RoundTrip(t *testing.T) {
+	header := &shared.Header{Fields: map[string][]byte{"a": []byte("1"), "b": {}}}
+	input := []byte(`"hello"`)
+
+	data, err := encodeHeaderIntoInput(header, input)
+	require.NoError(t, err)
+	require.NotEqual(t, input, data)
+
+	decodedHeader, decodedInput := decodeHeaderFromInput(data)
+	require.Equal(t, input, decodedInput)
+	require.Equal(t, []byte("1"), decodedHeader.Fields["a"])
+	require.Contains(t, decodedHeader.Fields, "b")
+
+	// empty input stays empty
+	data, err = encodeHeaderIntoInput(header, nil)
+	require.NoError(t, err)
+	_, decodedInput = decodeHeaderFromInput(data)
+	require.Nil(t, decodedInput)
+}
+
+func Test_HeaderEnvelope_NoHeader(t *testing.T) {
+	input := []byte(`"hello"`)
+
+	data, err := encodeHeaderIntoInput(nil, input)
+	require.NoError(t, err)
+	require.Equal(t, input, data)
+
+	data, err = encodeHeaderIntoInput(&shared.Header{}, input)
+	require.NoError(t, err)
+	require.Equal(t, input, data)
+
+	header, decodedInput := decodeHeaderFromInput(input)
+	require.Nil(t, header)
+	require.Equal(t, input, decodedInput)
+}
+
+func Test_HeaderEnvelope_InputWithPrefix(t *testing.T) {
+	malformed := append(append([]byte{}, headerEnvelopePrefix...), headerEnvelopeVersion, 0, 0, 0, 2, '{')
+	header, decodedInput := decodeHeaderFromInput(malformed)
+	require.Nil(t, header)
+	require.Equal(t, malformed, decodedInput)
+
+	data, err := encodeHeaderIntoInput(nil, malformed)
+	require.NoError(t, err)
+	require.NotEqual(t, malformed, data)
+	header, decodedInput = decodeHeaderFromInput(data)
+	require.Nil(t, header)
+	require.Equal(t, malformed, decodedInput)
+}
+
+func Test_ContextPropagator_InjectExtract(t *testing.T) {
+	propagators := []ContextPropagator{&testTenantPropagator{}}
+	ctx := context.WithValue(context.Background(), testTenantContextKey{}, "tenant-a")
+
+	data, err := injectHeaderFromContext(ctx, propagators, []byte("input"))
+	require.NoError(t, err)
+
+	header, input := decodeHeaderFromInput(data)
+	require.Equal(t, []byte("input"), input)
+
+	extracted, err := extractHeaderToContext(context.Background(), propagators, header)
+	require.NoError(t, err)
+	require.Equal(t, "tenant-a", extracted.Value(testTenantContextKey{}))
+
+	// nothing to propagate leaves the input untouched
+	data, err = injectHeaderFromContext(context.Background(), propagators, []byte("input"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("input"), data)
+}
+
+type testFailingPropagator struct {
+	testTenantPropagator
+}
+
+func (p *testFailingPropagator) Inject(ctx context.Context, writer HeaderWriter) error {
+	return errors.New("inject failed")
+}
+
+func Test_ContextPropagator_InjectError(t *testing.T) {
+	propagators := []ContextPropagator{&testFailingPropagator{}}
+	_, err := injectHeaderFromContext(context.Background(), propagators, []byte("input"))
+	require.EqualError(t, err, "inject failed")
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/thriftrw/protocol"
+	"go.uber.org/thriftrw/wire"
+)
+
+type (
+	// HistoryFormat is the encoding of the events written by a HistoryWriter.
+	HistoryFormat int
+
+	// HistoryHeader is written at the beginning of a history by a HistoryWriter, to identify the workflow
+	// execution the history belongs to.
+	HistoryHeader struct {
+		Domain     string `json:"domain"`
+		WorkflowID string `json:"workflowID"`
+		RunID      string `json:"runID"`
+		// ClientVersion is the version of the cadence client which wrote the history, set by the HistoryWriter.
+		ClientVersion string `json:"clientVersion"`
+		// Format is the encoding of the events, set by the HistoryWriter.
+		Format HistoryFormat `json:"format"`
+	}
+
+	// HistoryWriterOptions are optional parameters of a HistoryWriter.
+	HistoryWriterOptions struct {
+		// Optional: Format of the written events.
+		// default: HistoryFormatJSON
+		Format HistoryFormat
+
+		// Optional: Compresses the history with gzip.
+		// default: false
+		Gzip bool
+	}
+
+	// HistoryWriter writes the events of a workflow history to an io.Writer, one at a time so that histories which
+	// do not fit in memory can be written. The written history is read back with a HistoryReader. A history is
+	// written as a line with the JSON encoded HistoryHeader followed by the events, either as one JSON encoded
+	// event per line or as thrift binary encoded events each prefixed by its length as a big endian uint32.
+	HistoryWriter struct {
+		writer *bufio.Writer
+		gzip   *gzip.Writer
+		format HistoryFormat
+		closed bool
+	}
+
+	// HistoryReader reads a history written by a HistoryWriter. The format of the events and the compression are
+	// detected from the history. HistoryReader is a HistoryEventIterator which reads the events one at a time, so
+	// that histories which do not fit in memory can be read.
+	HistoryReader struct {
+		reader *bufio.Reader
+		header HistoryHeader
+		next   *shared.HistoryEvent
+		err    error
+	}
+)
+
+const (
+	// HistoryFormatJSON writes each event as a line of JSON after the header line. It is not the JSON array of
+	// events written by the cli.
+	HistoryFormatJSON HistoryFormat = iota
+
+	// HistoryFormatThriftBinary writes each event in the thrift binary encoding used by the cadence service, which is
+	// more compact and faster to read and write than JSON.
+	HistoryFormatThriftBinary
+)
+
+// maxHistoryEventSize is the largest thrift binary encoded event a HistoryReader reads, to not allocate an arbitrary
+// amount of memory for the size read from a corrupted history.
+const maxHistoryEventSize = 64 * 1024 * 1024
+
+var _ HistoryEventIterator = (*HistoryReader)(nil)
+
+// errHistoryWriterClosed is returned when writing to a closed HistoryWriter.
+var errHistoryWriterClosed = errors.New("history writer is closed")
+
+// NewHistoryWriter creates a HistoryWriter which writes the header right away. Close must be called once all the
+// events are written, it does not close w.
+func NewHistoryWriter(w io.Writer, header HistoryHeader, options HistoryWriterOptions) (*HistoryWriter, error) {
+	if options.Format != HistoryFormatJSON && options.Format != HistoryFormatThriftBinary {
+		return nil, fmt.Errorf("unknown history format %v", options.Format)
+	}
+	hw := &HistoryWriter{format: options.Format}
+	if options.Gzip {
+		hw.gzip = gzip.NewWriter(w)
+		w = hw.gzip
+	}
+	hw.writer = bufio.NewWriter(w)
+
+	header.ClientVersion = LibraryVersion
+	header.Format = options.Format
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := hw.writeLine(data); err != nil {
+		return nil, err
+	}
+	return hw, nil
+}
+
+// WriteEvent writes one event of the history.
+func (hw *HistoryWriter) WriteEvent(event *shared.HistoryEvent) error {
+	if hw.closed {
+		return errHistoryWriterClosed
+	}
+	if hw.format == HistoryFormatJSON {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return hw.writeLine(data)
+	}
+
+	value, err := event.ToWire()
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := protocol.Binary.Encode(value, &buffer); err != nil {
+		return err
+	}
+	if buffer.Len() > maxHistoryEventSize {
+		return fmt.Errorf("history event %v of %v bytes is larger than %v bytes", event.GetEventId(), buffer.Len(), maxHistoryEventSize)
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(buffer.Len()))
+	if _, err := hw.writer.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = buffer.WriteTo(hw.writer)
+	return err
+}
+
+// WriteHistory writes all the events returned by the iterator, such as the one returned by Client.GetWorkflowHistory.
+func (hw *HistoryWriter) WriteHistory(iter HistoryEventIterator) error {
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		if err := hw.WriteEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the history to the underlying io.Writer, which is not closed.
+func (hw *HistoryWriter) Close() error {
+	if hw.closed {
+		return nil
+	}
+	hw.closed = true
+	if err := hw.writer.Flush(); err != nil {
+		return err
+	}
+	if hw.gzip != nil {
+		return hw.gzip.Close()
+	}
+	return nil
+}
+
+func (hw *HistoryWriter) writeLine(data []byte) error {
+	if _, err := hw.writer.Write(data); err != nil {
+		return err
+	}
+	return hw.writer.WriteByte('\n')
+}
+
+// NewHistoryReader creates a HistoryReader which reads the header of the history right away.
+func NewHistoryReader(r io.Reader) (*HistoryReader, error) {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = bufio.NewReader(gzipReader)
+	}
+
+	hr := &HistoryReader{reader: reader}
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if err := json.Unmarshal(line, &hr.header); err != nil {
+		return nil, fmt.Errorf("invalid history header: %v", err)
+	}
+	if hr.header.Format != HistoryFormatJSON && hr.header.Format != HistoryFormatThriftBinary {
+		return nil, fmt.Errorf("unknown history format %v", hr.header.Format)
+	}
+	return hr, nil
+}
+
+// Header returns the header of the history.
+func (hr *HistoryReader) Header() HistoryHeader {
+	return hr.header
+}
+
+// HasNext returns whether the history has another event, or an error reading it.
+func (hr *HistoryReader) HasNext() bool {
+	if hr.next == nil && hr.err == nil {
+		hr.next, hr.err = hr.readEvent()
+	}
+	return hr.next != nil || (hr.err != nil && hr.err != io.EOF)
+}
+
+// Next returns the next event of the history.
+func (hr *HistoryReader) Next() (*shared.HistoryEvent, error) {
+	if !hr.HasNext() {
+		panic("HistoryReader Next() called without checking HasNext()")
+	}
+	if hr.next == nil {
+		return nil, hr.err
+	}
+	event := hr.next
+	hr.next = nil
+	return event, nil
+}
+
+// ReadHistory reads all the remaining events of the history.
+func (hr *HistoryReader) ReadHistory() (*shared.History, error) {
+	history := &shared.History{}
+	for hr.HasNext() {
+		event, err := hr.Next()
+		if err != nil {
+			return nil, err
+		}
+		history.Events = append(history.Events, event)
+	}
+	return history, nil
+}
+
+func (hr *HistoryReader) readEvent() (*shared.HistoryEvent, error) {
+	event := &shared.HistoryEvent{}
+	if hr.header.Format == HistoryFormatJSON {
+		line, err := hr.reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err == nil {
+				return hr.readEvent()
+			}
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if err := json.Unmarshal(line, event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(hr.reader, size[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated history event")
+		}
+		return nil, err
+	}
+	eventSize := binary.BigEndian.Uint32(size[:])
+	if eventSize > maxHistoryEventSize {
+		return nil, fmt.Errorf("history event of %v bytes is larger than %v bytes", eventSize, maxHistoryEventSize)
+	}
+	data := make([]byte, eventSize)
+	if _, err := io.ReadFull(hr.reader, data); err != nil {
+		return nil, errors.New("truncated history event")
+	}
+	value, err := protocol.Binary.Decode(bytes.NewReader(data), wire.TStruct)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.FromWire(value); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
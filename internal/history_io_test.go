@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
+)
+
+func createTestHistoryEvents() []*shared.HistoryEvent {
+	return []*shared.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{
+			WorkflowType: &shared.WorkflowType{Name: common.StringPtr("testWorkflow")},
+			TaskList:     &shared.TaskList{Name: common.StringPtr("taskList")},
+			Input:        []byte("input"),
+		}),
+		createTestEventDecisionTaskScheduled(2, &shared.DecisionTaskScheduledEventAttributes{}),
+		createTestEventDecisionTaskStarted(3),
+		createTestEventWorkflowExecutionSignaled(4, "signal"),
+	}
+}
+
+func TestHistoryWriterReader(t *testing.T) {
+	events := createTestHistoryEvents()
+	header := HistoryHeader{Domain: "domain", WorkflowID: "workflowID", RunID: "runID"}
+	tests := []struct {
+		name    string
+		options HistoryWriterOptions
+	}{
+		{name: "json", options: HistoryWriterOptions{Format: HistoryFormatJSON}},
+		{name: "json gzip", options: HistoryWriterOptions{Format: HistoryFormatJSON, Gzip: true}},
+		{name: "thrift", options: HistoryWriterOptions{Format: HistoryFormatThriftBinary}},
+		{name: "thrift gzip", options: HistoryWriterOptions{Format: HistoryFormatThriftBinary, Gzip: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			writer, err := NewHistoryWriter(&buffer, header, test.options)
+			require.NoError(t, err)
+			require.NoError(t, writer.WriteHistory(&testHistoryEventIterator{events: events}))
+			require.NoError(t, writer.Close())
+			require.Equal(t, errHistoryWriterClosed, writer.WriteEvent(events[0]))
+
+			reader, err := NewHistoryReader(&buffer)
+			require.NoError(t, err)
+			require.Equal(t, HistoryHeader{
+				Domain:        "domain",
+				WorkflowID:    "workflowID",
+				RunID:         "runID",
+				ClientVersion: LibraryVersion,
+				Format:        test.options.Format,
+			}, reader.Header())
+			var readEvents []*shared.HistoryEvent
+			for reader.HasNext() {
+				event, err := reader.Next()
+				require.NoError(t, err)
+				readEvents = append(readEvents, event)
+			}
+			require.Equal(t, len(events), len(readEvents))
+			for i := range events {
+				require.True(t, events[i].Equals(readEvents[i]), "event %v", i)
+			}
+		})
+	}
+}
+
+func TestHistoryReader_Errors(t *testing.T) {
+	_, err := NewHistoryReader(bytes.NewReader([]byte("[not a header]\n")))
+	require.Error(t, err)
+
+	_, err = NewHistoryWriter(&bytes.Buffer{}, HistoryHeader{}, HistoryWriterOptions{Format: HistoryFormat(5)})
+	require.EqualError(t, err, "unknown history format 5")
+
+	var buffer bytes.Buffer
+	writer, err := NewHistoryWriter(&buffer, HistoryHeader{}, HistoryWriterOptions{Format: HistoryFormatThriftBinary})
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteHistory(&testHistoryEventIterator{events: createTestHistoryEvents()}))
+	require.NoError(t, writer.Close())
+
+	reader, err := NewHistoryReader(bytes.NewReader(buffer.Bytes()[:buffer.Len()-2]))
+	require.NoError(t, err)
+	history, err := reader.ReadHistory()
+	require.Nil(t, history)
+	require.EqualError(t, err, "truncated history event")
+
+	header := buffer.Bytes()[:bytes.IndexByte(buffer.Bytes(), '\n')+1]
+	reader, err = NewHistoryReader(bytes.NewReader(append(append([]byte{}, header...), 0xff, 0xff, 0xff, 0xff)))
+	require.NoError(t, err)
+	history, err = reader.ReadHistory()
+	require.Nil(t, history)
+	require.EqualError(t, err, "history event of 4294967295 bytes is larger than 67108864 bytes")
+}
+
+func TestExtractHistoryFromFile_HistoryWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "history.bin")
+	file, err := os.Create(fileName)
+	require.NoError(t, err)
+	writer, err := NewHistoryWriter(file, HistoryHeader{}, HistoryWriterOptions{Format: HistoryFormatThriftBinary, Gzip: true})
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteHistory(&testHistoryEventIterator{events: createTestHistoryEvents()}))
+	require.NoError(t, writer.Close())
+	require.NoError(t, file.Close())
+
+	history, err := extractHistoryFromFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(history.Events))
+	require.Equal(t, shared.EventTypeWorkflowExecutionSignaled, history.Events[3].GetEventType())
+}
+
+type testHistoryEventIterator struct {
+	events []*shared.HistoryEvent
+}
+
+func (it *testHistoryEventIterator) HasNext() bool {
+	return len(it.events) > 0
+}
+
+func (it *testHistoryEventIterator) Next() (*shared.HistoryEvent, error) {
+	event := it.events[0]
+	it.events = it.events[1:]
+	return event, nil
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/cadence/encoded"
+)
+
+type (
+	// WorkerInterceptor creates the interceptors which wrap workflow and activity code run by a worker. It is
+	// configured through WorkerOptions.Interceptors. The first interceptor in the list is the outermost one, it sees
+	// every call first and the last interceptor hands the call over to the cadence implementation.
+	WorkerInterceptor interface {
+		// InterceptWorkflow is called once for every workflow execution the worker runs, including executions which
+		// are replayed after being evicted from the sticky cache. Workflow interceptors must be deterministic: they
+		// run as part of the workflow code.
+		InterceptWorkflow(info *WorkflowInfo, next WorkflowInterceptor) WorkflowInterceptor
+
+		// InterceptActivity is called once for every activity task, including local activities.
+		InterceptActivity(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor
+	}
+
+	// WorkflowInterceptor wraps workflow execution and the calls a workflow makes to the cadence APIs. Each method
+	// mirrors the workflow package function of the same name. An implementation usually embeds WorkflowInterceptorBase
+	// and overrides the methods it is interested in.
+	WorkflowInterceptor interface {
+		// ExecuteWorkflow runs the workflow function with the decoded input. The workflow completes once it returns.
+		// result is nil if the workflow function only returns an error.
+		ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) (result interface{}, err error)
+
+		ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future
+		ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}) Future
+		ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interface{}) ChildWorkflowFuture
+		NewTimer(ctx Context, d time.Duration) Future
+		RequestCancelExternalWorkflow(ctx Context, workflowID, runID string) Future
+		SignalExternalWorkflow(ctx Context, workflowID, runID, signalName string, arg interface{}) Future
+		SideEffect(ctx Context, f func(ctx Context) interface{}) encoded.Value
+	}
+
+	// ActivityInterceptor wraps activity execution.
+	ActivityInterceptor interface {
+		// ExecuteActivity runs the activity function with the decoded input. result is nil if the activity function
+		// only returns an error.
+		ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (result interface{}, err error)
+	}
+
+	// ClientInterceptor creates the interceptor which wraps the calls made through a Client. It is configured through
+	// ClientOptions.Interceptors, the first interceptor in the list is the outermost one.
+	ClientInterceptor interface {
+		// InterceptClient is called once when the client is created.
+		InterceptClient(next ClientOutboundInterceptor) ClientOutboundInterceptor
+	}
+
+	// ClientOutboundInterceptor wraps the calls made through a Client. Each method mirrors the Client method of the
+	// same name and sees the arguments before they are encoded, an interceptor may change them before calling the
+	// next one. An implementation usually embeds ClientOutboundInterceptorBase and overrides the methods it is
+	// interested in.
+	ClientOutboundInterceptor interface {
+		StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error)
+		ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error)
+		SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error
+		SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+			options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error)
+		CancelWorkflow(ctx context.Context, workflowID string, runID string) error
+		TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error
+		QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error)
+		CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error
+		CompleteActivityByID(ctx context.Context, domain, workflowID, runID, activityID string, result interface{}, err error) error
+		RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error
+		RecordActivityHeartbeatByID(ctx context.Context, domain, workflowID, runID, activityID string, details ...interface{}) error
+	}
+
+	// WorkflowInterceptorBase is a WorkflowInterceptor which forwards every call to Next.
+	WorkflowInterceptorBase struct {
+		Next WorkflowInterceptor
+	}
+
+	// ActivityInterceptorBase is an ActivityInterceptor which forwards every call to Next.
+	ActivityInterceptorBase struct {
+		Next ActivityInterceptor
+	}
+
+	// ClientOutboundInterceptorBase is a ClientOutboundInterceptor which forwards every call to Next.
+	ClientOutboundInterceptorBase struct {
+		Next ClientOutboundInterceptor
+	}
+)
+
+var _ WorkflowInterceptor = (*WorkflowInterceptorBase)(nil)
+var _ ActivityInterceptor = (*ActivityInterceptorBase)(nil)
+var _ ClientOutboundInterceptor = (*ClientOutboundInterceptorBase)(nil)
+
+// ExecuteWorkflow forwards to Next.
+func (b *WorkflowInterceptorBase) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) (interface{}, error) {
+	return b.Next.ExecuteWorkflow(ctx, workflowType, args...)
+}
+
+// ExecuteActivity forwards to Next.
+func (b *WorkflowInterceptorBase) ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	return b.Next.ExecuteActivity(ctx, activity, args...)
+}
+
+// ExecuteLocalActivity forwards to Next.
+func (b *WorkflowInterceptorBase) ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	return b.Next.ExecuteLocalActivity(ctx, activity, args...)
+}
+
+// ExecuteChildWorkflow forwards to Next.
+func (b *WorkflowInterceptorBase) ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interface{}) ChildWorkflowFuture {
+	return b.Next.ExecuteChildWorkflow(ctx, childWorkflow, args...)
+}
+
+// NewTimer forwards to Next.
+func (b *WorkflowInterceptorBase) NewTimer(ctx Context, d time.Duration) Future {
+	return b.Next.NewTimer(ctx, d)
+}
+
+// RequestCancelExternalWorkflow forwards to Next.
+func (b *WorkflowInterceptorBase) RequestCancelExternalWorkflow(ctx Context, workflowID, runID string) Future {
+	return b.Next.RequestCancelExternalWorkflow(ctx, workflowID, runID)
+}
+
+// SignalExternalWorkflow forwards to Next.
+func (b *WorkflowInterceptorBase) SignalExternalWorkflow(ctx Context, workflowID, runID, signalName string, arg interface{}) Future {
+	return b.Next.SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+// SideEffect forwards to Next.
+func (b *WorkflowInterceptorBase) SideEffect(ctx Context, f func(ctx Context) interface{}) encoded.Value {
+	return b.Next.SideEffect(ctx, f)
+}
+
+// ExecuteActivity forwards to Next.
+func (b *ActivityInterceptorBase) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (interface{}, error) {
+	return b.Next.ExecuteActivity(ctx, activityType, args...)
+}
+
+// newWorkflowInterceptorChain wraps root with the workflow interceptors created by interceptors.
+func newWorkflowInterceptorChain(interceptors []WorkerInterceptor, info *WorkflowInfo, root WorkflowInterceptor) WorkflowInterceptor {
+	chain := root
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		chain = interceptors[i].InterceptWorkflow(info, chain)
+	}
+	return chain
+}
+
+// newActivityInterceptorChain wraps root with the activity interceptors created by interceptors.
+func newActivityInterceptorChain(interceptors []WorkerInterceptor, info *ActivityInfo, root ActivityInterceptor) ActivityInterceptor {
+	chain := root
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		chain = interceptors[i].InterceptActivity(info, chain)
+	}
+	return chain
+}
+
+// getWorkflowInterceptor returns the head of the interceptor chain of the workflow execution ctx belongs to.
+func getWorkflowInterceptor(ctx Context) WorkflowInterceptor {
+	if interceptor, ok := ctx.Value(workflowInterceptorContextKey).(WorkflowInterceptor); ok {
+		return interceptor
+	}
+	return &workflowEnvironmentInterceptor{}
+}
+
+// newClientInterceptorChain wraps root with the client interceptors created by interceptors.
+func newClientInterceptorChain(interceptors []ClientInterceptor, root ClientOutboundInterceptor) ClientOutboundInterceptor {
+	chain := root
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		chain = interceptors[i].InterceptClient(chain)
+	}
+	return chain
+}
+
+// StartWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error) {
+	return b.Next.StartWorkflow(ctx, options, workflow, args...)
+}
+
+// ExecuteWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
+	return b.Next.ExecuteWorkflow(ctx, options, workflow, args...)
+}
+
+// SignalWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	return b.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+// SignalWithStartWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error) {
+	return b.Next.SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs...)
+}
+
+// CancelWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
+	return b.Next.CancelWorkflow(ctx, workflowID, runID)
+}
+
+// TerminateWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error {
+	return b.Next.TerminateWorkflow(ctx, workflowID, runID, reason, details)
+}
+
+// QueryWorkflow forwards to Next.
+func (b *ClientOutboundInterceptorBase) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error) {
+	return b.Next.QueryWorkflow(ctx, workflowID, runID, queryType, args...)
+}
+
+// CompleteActivity forwards to Next.
+func (b *ClientOutboundInterceptorBase) CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error {
+	return b.Next.CompleteActivity(ctx, taskToken, result, err)
+}
+
+// CompleteActivityByID forwards to Next.
+func (b *ClientOutboundInterceptorBase) CompleteActivityByID(ctx context.Context, domain, workflowID, runID, activityID string, result interface{}, err error) error {
+	return b.Next.CompleteActivityByID(ctx, domain, workflowID, runID, activityID, result, err)
+}
+
+// RecordActivityHeartbeat forwards to Next.
+func (b *ClientOutboundInterceptorBase) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
+	return b.Next.RecordActivityHeartbeat(ctx, taskToken, details...)
+}
+
+// RecordActivityHeartbeatByID forwards to Next.
+func (b *ClientOutboundInterceptorBase) RecordActivityHeartbeatByID(ctx context.Context, domain, workflowID, runID, activityID string, details ...interface{}) error {
+	return b.Next.RecordActivityHeartbeatByID(ctx, domain, workflowID, runID, activityID, details...)
+}
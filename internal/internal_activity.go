@@ -26,7 +26,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/uber-go/tally"
@@ -36,6 +38,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// noRetryBackoff is the backoff reported for a local activity attempt that must not be retried.
+const noRetryBackoff = time.Duration(-1)
+
 type (
 	// activity is an interface of an activity implementation.
 	activity interface {
@@ -67,6 +72,7 @@ type (
 
 	localActivityOptions struct {
 		ScheduleToCloseTimeoutSeconds int32
+		RetryPolicy                   *RetryPolicy
 	}
 
 	executeActivityParams struct {
@@ -82,6 +88,18 @@ type (
 		InputArgs     []interface{}
 		WorkflowInfo  *WorkflowInfo
 		DataConverter encoded.DataConverter
+		Header        *shared.Header
+		Attempt       int32     // starts from 0.
+		ScheduledTime time.Time // time of the first attempt, the retry expiration is counted from it.
+	}
+
+	// localActivityResultWrapper carries the local activity outcome back to the workflow together with the retry
+	// state. A positive backoff means the workflow has to wait that long before it schedules the next attempt.
+	localActivityResultWrapper struct {
+		result  []byte
+		err     error
+		attempt int32
+		backoff time.Duration
 	}
 
 	// asyncActivityClient for requesting activity execution
@@ -99,7 +117,7 @@ type (
 
 	// localActivityClient for requesting local activity execution
 	localActivityClient interface {
-		ExecuteLocalActivity(params executeLocalActivityParams, callback resultHandler) *localActivityInfo
+		ExecuteLocalActivity(params executeLocalActivityParams, callback laResultHandler) *localActivityInfo
 
 		RequestCancelLocalActivity(activityID string)
 	}
@@ -120,6 +138,7 @@ type (
 		taskList           string
 		dataConverter      encoded.DataConverter
 		attempt            int // starts from 0.
+		workerInterceptors []WorkerInterceptor
 	}
 
 	// context.WithValue need this type instead of basic type string to avoid lint error
@@ -199,9 +218,81 @@ func getValidatedLocalActivityOptions(ctx Context) (*localActivityOptions, error
 		return nil, errors.New("missing or negative ScheduleToCloseTimeoutSeconds")
 	}
 
+	if err := validateLocalRetryPolicy(p.RetryPolicy); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
+func validateLocalRetryPolicy(p *RetryPolicy) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.InitialInterval <= 0 {
+		return errors.New("missing or negative InitialInterval on retry policy")
+	}
+	if p.MaximumInterval < 0 {
+		return errors.New("negative MaximumInterval on retry policy is invalid")
+	}
+	if p.MaximumInterval == 0 {
+		// if not set, default to 100x of initial interval
+		p.MaximumInterval = 100 * p.InitialInterval
+	}
+	if p.MaximumAttempts < 0 {
+		return errors.New("negative MaximumAttempts on retry policy is invalid")
+	}
+	if p.ExpirationInterval < 0 {
+		return errors.New("negative ExpirationInterval on retry policy is invalid")
+	}
+	if p.BackoffCoefficient == 0 {
+		p.BackoffCoefficient = 2.0
+	}
+	if p.BackoffCoefficient < 1 {
+		return errors.New("BackoffCoefficient on retry policy cannot be less than 1.0")
+	}
+	if p.MaximumAttempts == 0 && p.ExpirationInterval == 0 {
+		return errors.New("both MaximumAttempts and ExpirationInterval on retry policy are not set, at least one of them must be set")
+	}
+
+	return nil
+}
+
+// getRetryBackoff returns how long to wait before the next attempt of a failed local activity, or noRetryBackoff
+// if the retry policy does not allow another attempt. now has to be on the workflow clock, as the retry expiration
+// is counted from the workflow time the first attempt was scheduled at.
+func getRetryBackoff(lar *localActivityResult, now time.Time, dc encoded.DataConverter) time.Duration {
+	p := lar.task.params.RetryPolicy
+	if p == nil || lar.err == nil {
+		return noRetryBackoff
+	}
+	if _, ok := lar.err.(*CanceledError); ok {
+		return noRetryBackoff
+	}
+
+	attempt := lar.attempt
+	if p.MaximumAttempts > 0 && attempt >= p.MaximumAttempts-1 {
+		return noRetryBackoff
+	}
+	errReason, _ := getErrorDetails(lar.err, dc)
+	for _, reason := range p.NonRetriableErrorReasons {
+		if reason == errReason {
+			return noRetryBackoff
+		}
+	}
+
+	backoff := time.Duration(float64(p.InitialInterval) * math.Pow(p.BackoffCoefficient, float64(attempt)))
+	if backoff <= 0 || backoff > p.MaximumInterval {
+		// math.Pow() could overflow
+		backoff = p.MaximumInterval
+	}
+	if p.ExpirationInterval > 0 && now.Add(backoff).After(lar.task.params.ScheduledTime.Add(p.ExpirationInterval)) {
+		return noRetryBackoff
+	}
+	return backoff
+}
+
 func validateRetryPolicy(p *shared.RetryPolicy) error {
 	if p == nil {
 		return nil
@@ -272,7 +363,21 @@ func validateFunctionArgs(f interface{}, args []interface{}, isWorkflow bool) er
 	return nil
 }
 
-func getValidatedActivityFunction(f interface{}, args []interface{}, dataConverter encoded.DataConverter) (*ActivityType, []byte, error) {
+// getActivityFunctionName returns the name the activity function f is registered under.
+func getActivityFunctionName(registry *hostEnvImpl, f interface{}) string {
+	fnName := getFunctionName(f)
+	if alias, ok := registry.getActivityAlias(fnName); ok {
+		return alias
+	}
+	// The method value of a registered struct is named after the method with a "-fm" suffix. It resolves to the
+	// activity of the registered instance of the struct, whichever instance it is bound to.
+	if alias, ok := registry.getActivityAlias(strings.TrimSuffix(fnName, "-fm")); ok {
+		return alias
+	}
+	return fnName
+}
+
+func getValidatedActivityFunction(f interface{}, args []interface{}, dataConverter encoded.DataConverter, registry *hostEnvImpl) (*ActivityType, []byte, error) {
 	fnName := ""
 	fType := reflect.TypeOf(f)
 	switch getKind(fType) {
@@ -283,10 +388,10 @@ func getValidatedActivityFunction(f interface{}, args []interface{}, dataConvert
 		if err := validateFunctionArgs(f, args, false); err != nil {
 			return nil, nil, err
 		}
-		fnName = getFunctionName(f)
-		if alias, ok := getHostEnvironment().getActivityAlias(fnName); ok {
-			fnName = alias
+		if err := registry.checkActivityAlias(strings.TrimSuffix(getFunctionName(f), "-fm")); err != nil {
+			return nil, nil, err
 		}
+		fnName = getActivityFunctionName(registry, f)
 
 	default:
 		return nil, nil, fmt.Errorf(
@@ -313,6 +418,12 @@ func isActivityContext(inType reflect.Type) bool {
 }
 
 func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataConverter encoded.DataConverter) ([]byte, error) {
+	result, err := getFunctionResults(f, values)
+	return encodeFunctionResult(dataConverter, result, err)
+}
+
+// getFunctionResults converts the values returned by a workflow or activity function into its result and error.
+func getFunctionResults(f interface{}, values []reflect.Value) (interface{}, error) {
 	fnName := getFunctionName(f)
 	resultSize := len(values)
 
@@ -322,17 +433,13 @@ func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataCo
 			fnName, resultSize)
 	}
 
-	var result []byte
-	var err error
+	var result interface{}
 
 	// Parse result
 	if resultSize > 1 {
 		retValue := values[0]
 		if retValue.Kind() != reflect.Ptr || !retValue.IsNil() {
-			result, err = encodeArg(dataConverter, retValue.Interface())
-			if err != nil {
-				return nil, err
-			}
+			result = retValue.Interface()
 		}
 	}
 
@@ -350,6 +457,41 @@ func validateFunctionAndGetResults(f interface{}, values []reflect.Value, dataCo
 	return result, errInterface
 }
 
+// encodeFunctionResult encodes the result returned by a workflow or activity function, err is passed through.
+func encodeFunctionResult(dataConverter encoded.DataConverter, result interface{}, err error) ([]byte, error) {
+	if result == nil {
+		return nil, err
+	}
+	data, encodeErr := encodeArg(dataConverter, result)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	return data, err
+}
+
+// valuesToInterfaces converts decoded arguments into the form passed to interceptors.
+func valuesToInterfaces(values []reflect.Value) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.Interface())
+	}
+	return result
+}
+
+// interfacesToValues converts arguments back into reflect values for fnType, whose first offset parameters are
+// not part of args.
+func interfacesToValues(fnType reflect.Type, offset int, args []interface{}) []reflect.Value {
+	result := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			result = append(result, reflect.New(fnType.In(i+offset)).Elem())
+		} else {
+			result = append(result, reflect.ValueOf(arg))
+		}
+	}
+	return result
+}
+
 func deSerializeFnResultFromFnType(fnType reflect.Type, result []byte, to interface{}, dataConverter encoded.DataConverter) error {
 	if fnType.Kind() != reflect.Func {
 		return fmt.Errorf("expecting only function type but got type: %v", fnType)
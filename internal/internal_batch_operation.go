@@ -0,0 +1,268 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	s "go.uber.org/cadence/.gen/go/shared"
+	"golang.org/x/time/rate"
+)
+
+type (
+	// batchOperationImpl is the implementation of BatchOperation
+	batchOperationImpl struct {
+		sync.Mutex
+		progress   BatchProgress
+		checkpoint batchCheckpoint
+		// err which stopped the batch
+		err    error
+		doneCh chan struct{}
+
+		concurrency int
+		limiter     *rate.Limiter
+		paginate    func(nexttoken []byte) ([]*s.WorkflowExecutionInfo, []byte, error)
+		filter      func(info *WorkflowExecutionInfo) bool
+		operation   func(ctx context.Context, execution WorkflowExecution) error
+		isSkipped   func(err error) bool
+	}
+
+	// batchCheckpoint is the state of a batch operation encoded into its checkpoint token. The batch processes one
+	// page of executions at a time, so the token of the page being processed and the run IDs of the executions of
+	// that page which are already processed are enough to resume it.
+	batchCheckpoint struct {
+		PageToken []byte   `json:"pageToken,omitempty"`
+		Processed []string `json:"processed,omitempty"`
+		Done      bool     `json:"done,omitempty"`
+	}
+)
+
+var _ BatchOperation = (*batchOperationImpl)(nil)
+
+// BatchCancel requests cancellation of every open workflow execution matching the filter.
+func (wc *workflowClient) BatchCancel(ctx context.Context, filter ListWorkflowsFilter, options BatchOptions) (BatchOperation, error) {
+	operation := func(ctx context.Context, execution WorkflowExecution) error {
+		return wc.CancelWorkflow(ctx, execution.ID, execution.RunID)
+	}
+	isSkipped := func(err error) bool {
+		switch err.(type) {
+		case *s.EntityNotExistsError, *s.CancellationAlreadyRequestedError:
+			return true
+		default:
+			return false
+		}
+	}
+	return wc.startBatchOperation(ctx, filter, options, operation, isSkipped)
+}
+
+// BatchTerminate terminates every open workflow execution matching the filter.
+func (wc *workflowClient) BatchTerminate(ctx context.Context, filter ListWorkflowsFilter, reason string, details []byte, options BatchOptions) (BatchOperation, error) {
+	operation := func(ctx context.Context, execution WorkflowExecution) error {
+		return wc.TerminateWorkflow(ctx, execution.ID, execution.RunID, reason, details)
+	}
+	return wc.startBatchOperation(ctx, filter, options, operation, isEntityNotExistsError)
+}
+
+// BatchSignal sends the signal to every open workflow execution matching the filter.
+func (wc *workflowClient) BatchSignal(ctx context.Context, filter ListWorkflowsFilter, signalName string, arg interface{}, options BatchOptions) (BatchOperation, error) {
+	operation := func(ctx context.Context, execution WorkflowExecution) error {
+		return wc.SignalWorkflow(ctx, execution.ID, execution.RunID, signalName, arg)
+	}
+	return wc.startBatchOperation(ctx, filter, options, operation, isEntityNotExistsError)
+}
+
+func (wc *workflowClient) startBatchOperation(
+	ctx context.Context,
+	filter ListWorkflowsFilter,
+	options BatchOptions,
+	operation func(ctx context.Context, execution WorkflowExecution) error,
+	isSkipped func(err error) bool,
+) (BatchOperation, error) {
+	if filter.Closed {
+		return nil, errors.New("batch operations only apply to open workflow executions")
+	}
+	if options.Concurrency < 0 {
+		return nil, errors.New("negative Concurrency provided")
+	}
+	if options.RateLimit < 0 {
+		return nil, errors.New("negative RateLimit provided")
+	}
+
+	b := &batchOperationImpl{
+		doneCh:      make(chan struct{}),
+		concurrency: options.Concurrency,
+		limiter:     rate.NewLimiter(rate.Inf, 1),
+		paginate:    wc.getListWorkflowsPaginateFn(ctx, filter),
+		filter:      filter.matches,
+		operation:   operation,
+		isSkipped:   isSkipped,
+	}
+	if b.concurrency == 0 {
+		b.concurrency = 1
+	}
+	if options.RateLimit > 0 {
+		b.limiter = rate.NewLimiter(rate.Limit(options.RateLimit), 1)
+	}
+	if options.Checkpoint != "" {
+		data, err := base64.StdEncoding.DecodeString(options.Checkpoint)
+		if err != nil {
+			return nil, errors.New("invalid Checkpoint provided")
+		}
+		if err := json.Unmarshal(data, &b.checkpoint); err != nil {
+			return nil, errors.New("invalid Checkpoint provided")
+		}
+	}
+
+	go b.run(ctx)
+	return b, nil
+}
+
+func (b *batchOperationImpl) run(ctx context.Context) {
+	defer close(b.doneCh)
+
+	// the checkpoint is only written by run and by the workers of processPage, so it is safe to read between pages
+	// without holding the lock.
+	for !b.checkpoint.Done {
+		if err := ctx.Err(); err != nil {
+			b.stop(err)
+			return
+		}
+		executions, nexttoken, err := b.paginate(b.checkpoint.PageToken)
+		if err != nil {
+			b.stop(err)
+			return
+		}
+
+		processed := make(map[string]struct{}, len(b.checkpoint.Processed))
+		for _, runID := range b.checkpoint.Processed {
+			processed[runID] = struct{}{}
+		}
+		b.processPage(ctx, executions, processed)
+		if err := ctx.Err(); err != nil {
+			b.stop(err)
+			return
+		}
+
+		b.Lock()
+		b.checkpoint = batchCheckpoint{PageToken: nexttoken, Done: len(nexttoken) == 0}
+		b.Unlock()
+	}
+
+	b.Lock()
+	b.progress.Done = true
+	b.Unlock()
+}
+
+func (b *batchOperationImpl) processPage(ctx context.Context, executions []*s.WorkflowExecutionInfo, processed map[string]struct{}) {
+	tasks := make(chan WorkflowExecution)
+	var wg sync.WaitGroup
+	for i := 0; i < b.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for execution := range tasks {
+				b.complete(ctx, execution, b.operation(ctx, execution))
+			}
+		}()
+	}
+
+	for _, execution := range executions {
+		info := convertWorkflowExecutionInfo(execution)
+		if _, ok := processed[info.Execution.RunID]; ok || !b.filter(info) {
+			continue
+		}
+		if err := b.limiter.Wait(ctx); err != nil {
+			break
+		}
+		tasks <- info.Execution
+	}
+	close(tasks)
+	wg.Wait()
+}
+
+func (b *batchOperationImpl) complete(ctx context.Context, execution WorkflowExecution, err error) {
+	if err != nil && ctx.Err() != nil {
+		// interrupted by the batch context, the execution is processed again when the batch is resumed.
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	switch {
+	case err == nil:
+		b.progress.Succeeded++
+	case b.isSkipped(err):
+		b.progress.Skipped++
+	default:
+		b.progress.Failed++
+		b.progress.Failures = append(b.progress.Failures, BatchFailure{Execution: execution, Err: err})
+	}
+	b.checkpoint.Processed = append(b.checkpoint.Processed, execution.RunID)
+}
+
+func (b *batchOperationImpl) stop(err error) {
+	b.Lock()
+	b.err = err
+	b.Unlock()
+}
+
+// Progress returns the current progress of the batch.
+func (b *batchOperationImpl) Progress() BatchProgress {
+	b.Lock()
+	defer b.Unlock()
+	progress := b.progress
+	progress.Failures = append([]BatchFailure(nil), b.progress.Failures...)
+	return progress
+}
+
+// Checkpoint returns a token which resumes the batch from its current progress.
+func (b *batchOperationImpl) Checkpoint() string {
+	b.Lock()
+	defer b.Unlock()
+	data, err := json.Marshal(b.checkpoint)
+	if err != nil {
+		// will never happen
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// Wait blocks until the batch stops or ctx is done.
+func (b *batchOperationImpl) Wait(ctx context.Context) (BatchProgress, error) {
+	select {
+	case <-b.doneCh:
+		b.Lock()
+		err := b.err
+		b.Unlock()
+		return b.Progress(), err
+	case <-ctx.Done():
+		return b.Progress(), ctx.Err()
+	}
+}
+
+func isEntityNotExistsError(err error) bool {
+	_, ok := err.(*s.EntityNotExistsError)
+	return ok
+}
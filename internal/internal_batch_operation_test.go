@@ -0,0 +1,131 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/cadence/.gen/go/cadence/workflowservicetest"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/internal/common"
+)
+
+func newTestBatchExecutionInfo(id string) *shared.WorkflowExecutionInfo {
+	return &shared.WorkflowExecutionInfo{
+		Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(id), RunId: common.StringPtr(id + "-run")},
+		Type:      &shared.WorkflowType{Name: common.StringPtr(workflowType)},
+		StartTime: common.Int64Ptr(0),
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	service := workflowservicetest.NewMockClient(mockCtrl)
+	client := NewClient(service, domain, nil)
+	wc := client.(*workflowClient)
+
+	service.EXPECT().ListOpenWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListOpenWorkflowExecutionsResponse{Executions: []*shared.WorkflowExecutionInfo{
+			newTestBatchExecutionInfo("wid1"),
+			newTestBatchExecutionInfo("wid2"),
+			newTestBatchExecutionInfo("wid3"),
+		}}, nil).
+		Do(func(_ interface{}, req *shared.ListOpenWorkflowExecutionsRequest, _ ...interface{}) {
+			require.Equal(t, workflowType, req.TypeFilter.GetName())
+		})
+	cancelRequest := func(id string) *shared.RequestCancelWorkflowExecutionRequest {
+		return &shared.RequestCancelWorkflowExecutionRequest{
+			Domain: common.StringPtr(domain),
+			WorkflowExecution: &shared.WorkflowExecution{
+				WorkflowId: common.StringPtr(id),
+				RunId:      common.StringPtr(id + "-run"),
+			},
+			Identity: common.StringPtr(wc.identity),
+		}
+	}
+	failure := &shared.BadRequestError{Message: "bad request"}
+	service.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), cancelRequest("wid1"), gomock.Any()).Return(nil)
+	service.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), cancelRequest("wid2"), gomock.Any()).Return(&shared.EntityNotExistsError{})
+	service.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), cancelRequest("wid3"), gomock.Any()).Return(failure)
+
+	batch, err := client.BatchCancel(context.Background(), ListWorkflowsFilter{WorkflowType: workflowType}, BatchOptions{Concurrency: 2, RateLimit: 100})
+	require.NoError(t, err)
+	progress, err := batch.Wait(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, BatchProgress{
+		Succeeded: 1,
+		Failed:    1,
+		Skipped:   1,
+		Failures:  []BatchFailure{{Execution: WorkflowExecution{ID: "wid3", RunID: "wid3-run"}, Err: failure}},
+		Done:      true,
+	}, progress)
+
+	// resuming a completed batch does nothing
+	resumed, err := client.BatchCancel(context.Background(), ListWorkflowsFilter{WorkflowType: workflowType}, BatchOptions{Checkpoint: batch.Checkpoint()})
+	require.NoError(t, err)
+	progress, err = resumed.Wait(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, BatchProgress{Done: true}, progress)
+}
+
+func TestBatchTerminate_ResumeFromCheckpoint(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	service := workflowservicetest.NewMockClient(mockCtrl)
+	client := NewClient(service, domain, nil)
+
+	pageToken := []byte("page 2")
+	service.EXPECT().ListOpenWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListOpenWorkflowExecutionsResponse{Executions: []*shared.WorkflowExecutionInfo{
+			newTestBatchExecutionInfo("wid1"),
+			newTestBatchExecutionInfo("wid2"),
+		}}, nil).
+		Do(func(_ interface{}, req *shared.ListOpenWorkflowExecutionsRequest, _ ...interface{}) {
+			require.Equal(t, pageToken, req.NextPageToken)
+		})
+	service.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).
+		Do(func(_ interface{}, req *shared.TerminateWorkflowExecutionRequest, _ ...interface{}) {
+			require.Equal(t, "wid2", req.WorkflowExecution.GetWorkflowId())
+			require.Equal(t, "bad deploy", req.GetReason())
+		})
+
+	checkpoint := (&batchOperationImpl{checkpoint: batchCheckpoint{PageToken: pageToken, Processed: []string{"wid1-run"}}}).Checkpoint()
+	batch, err := client.BatchTerminate(context.Background(), ListWorkflowsFilter{}, "bad deploy", nil, BatchOptions{Checkpoint: checkpoint})
+	require.NoError(t, err)
+	progress, err := batch.Wait(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, BatchProgress{Succeeded: 1, Done: true}, progress)
+}
+
+func TestBatchOperation_InvalidOptions(t *testing.T) {
+	client := NewClient(nil, domain, nil)
+
+	_, err := client.BatchSignal(context.Background(), ListWorkflowsFilter{Closed: true}, "signal", nil, BatchOptions{})
+	require.EqualError(t, err, "batch operations only apply to open workflow executions")
+	_, err = client.BatchSignal(context.Background(), ListWorkflowsFilter{}, "signal", nil, BatchOptions{Concurrency: -1})
+	require.EqualError(t, err, "negative Concurrency provided")
+	_, err = client.BatchSignal(context.Background(), ListWorkflowsFilter{}, "signal", nil, BatchOptions{Checkpoint: "not a checkpoint"})
+	require.EqualError(t, err, "invalid Checkpoint provided")
+}
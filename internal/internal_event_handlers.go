@@ -24,6 +24,7 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -105,19 +106,32 @@ type (
 		isReplay              bool // flag to indicate if workflow is in replay mode
 		enableLoggingInReplay bool // flag to indicate if workflow should enable logging in replay mode
 
-		metricsScope  tally.Scope
-		hostEnv       *hostEnvImpl
-		dataConverter encoded.DataConverter
+		metricsScope       tally.Scope
+		hostEnv            *hostEnvImpl
+		dataConverter      encoded.DataConverter
+		contextPropagators []ContextPropagator
+		workerInterceptors []WorkerInterceptor
+
+		enableHeaderEnvelope  bool
+		unhandledSignalPolicy UnhandledSignalPolicy
 	}
 
 	localActivityTask struct {
 		sync.Mutex
 		activityID string
 		params     *executeLocalActivityParams
-		callback   resultHandler
+		callback   laResultHandler
 		wc         *workflowExecutionContextImpl
-		canceled   bool
-		cancelFunc func()
+		attempt    int32 // first attempt run by this task, starts from 0.
+
+		// scheduledTime is the workflow time this task was scheduled at, the worker advances it by the time spent
+		// on the task to check the retry expiration.
+		scheduledTime time.Time
+		// retryDeadline is the latest local time the worker may retry the task in process, it is bound to the
+		// decision task that started the local activity.
+		retryDeadline time.Time
+		canceled      bool
+		cancelFunc    func()
 	}
 
 	localActivityMarkerData struct {
@@ -126,6 +140,8 @@ type (
 		ErrJSON    string // string instead of []byte so the encoded blob is human readable
 		ResultJSON string
 		ReplayTime time.Time
+		Attempt    int32         // attempt that produced this result, starts from 0.
+		Backoff    time.Duration // positive if the workflow has to wait this long and schedule the next attempt.
 	}
 
 	// wrapper around zapcore.Core that will be aware of replay
@@ -162,6 +178,10 @@ func newWorkflowExecutionEventHandler(
 	scope tally.Scope,
 	hostEnv *hostEnvImpl,
 	dataConverter encoded.DataConverter,
+	contextPropagators []ContextPropagator,
+	enableHeaderEnvelope bool,
+	workerInterceptors []WorkerInterceptor,
+	unhandledSignalPolicy UnhandledSignalPolicy,
 ) workflowExecutionEventHandler {
 	context := &workflowEnvironmentImpl{
 		workflowInfo:          workflowInfo,
@@ -175,6 +195,10 @@ func newWorkflowExecutionEventHandler(
 		enableLoggingInReplay: enableLoggingInReplay,
 		hostEnv:               hostEnv,
 		dataConverter:         dataConverter,
+		contextPropagators:    contextPropagators,
+		enableHeaderEnvelope:  enableHeaderEnvelope,
+		workerInterceptors:    workerInterceptors,
+		unhandledSignalPolicy: unhandledSignalPolicy,
 	}
 	context.logger = logger.With(
 		zapcore.Field{Key: tagWorkflowType, Type: zapcore.StringType, String: workflowInfo.WorkflowType.Name},
@@ -223,6 +247,23 @@ func (t *localActivityTask) cancel() {
 	t.Unlock()
 }
 
+// waitRetryBackoff blocks until the backoff before the next attempt has passed. It returns false if the task is
+// canceled in the meantime.
+func (t *localActivityTask) waitRetryBackoff(backoff time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), backoff)
+	defer cancel()
+	t.Lock()
+	if t.canceled {
+		t.Unlock()
+		return false
+	}
+	t.cancelFunc = cancel
+	t.Unlock()
+
+	<-ctx.Done()
+	return ctx.Err() == context.DeadlineExceeded
+}
+
 func (s *scheduledCancellation) handle(result []byte, err error) {
 	if s.handled {
 		panic(fmt.Sprintf("cancellation already handled %v", s))
@@ -328,6 +369,26 @@ func (wc *workflowEnvironmentImpl) GetDataConverter() encoded.DataConverter {
 	return wc.dataConverter
 }
 
+func (wc *workflowEnvironmentImpl) GetContextPropagators() []ContextPropagator {
+	return wc.contextPropagators
+}
+
+func (wc *workflowEnvironmentImpl) IsHeaderEnvelopeEnabled() bool {
+	return wc.enableHeaderEnvelope
+}
+
+func (wc *workflowEnvironmentImpl) GetWorkerInterceptors() []WorkerInterceptor {
+	return wc.workerInterceptors
+}
+
+func (wc *workflowEnvironmentImpl) GetUnhandledSignalPolicy() UnhandledSignalPolicy {
+	return wc.unhandledSignalPolicy
+}
+
+func (wc *workflowEnvironmentImpl) GetRegistry() *hostEnvImpl {
+	return wc.hostEnv
+}
+
 func (wc *workflowEnvironmentImpl) IsReplaying() bool {
 	return wc.isReplay
 }
@@ -392,9 +453,15 @@ func (wc *workflowEnvironmentImpl) RequestCancelActivity(activityID string) {
 	wc.logger.Debug("RequestCancelActivity", zap.String(tagActivityID, activityID))
 }
 
-func (wc *workflowEnvironmentImpl) ExecuteLocalActivity(params executeLocalActivityParams, callback resultHandler) *localActivityInfo {
+func (wc *workflowEnvironmentImpl) ExecuteLocalActivity(params executeLocalActivityParams, callback laResultHandler) *localActivityInfo {
 	activityID := wc.GenerateSequenceID()
-	task := &localActivityTask{activityID: activityID, params: &params, callback: callback}
+	task := &localActivityTask{
+		activityID:    activityID,
+		params:        &params,
+		callback:      callback,
+		attempt:       params.Attempt,
+		scheduledTime: wc.Now(),
+	}
 	wc.pendingLaTasks[activityID] = task
 	wc.unstartedLaTasks[activityID] = struct{}{}
 	return &localActivityInfo{activityID: activityID}
@@ -914,11 +981,13 @@ func (weh *workflowExecutionEventHandlerImpl) handleLocalActivityMarker(markerDa
 		weh.decisionsHelper.recordLocalActivityMarker(lamd.ActivityID, markerData)
 		delete(weh.pendingLaTasks, lamd.ActivityID)
 		delete(weh.unstartedLaTasks, lamd.ActivityID)
+		lar := &localActivityResultWrapper{attempt: lamd.Attempt, backoff: lamd.Backoff}
 		if len(lamd.ErrReason) > 0 {
-			la.callback(nil, constructError(lamd.ErrReason, []byte(lamd.ErrJSON), weh.GetDataConverter()))
+			lar.err = constructError(lamd.ErrReason, []byte(lamd.ErrJSON), weh.GetDataConverter())
 		} else {
-			la.callback([]byte(lamd.ResultJSON), nil)
+			lar.result = []byte(lamd.ResultJSON)
 		}
+		la.callback(lar)
 
 		// update time
 		weh.SetCurrentReplayTime(lamd.ReplayTime)
@@ -935,6 +1004,8 @@ func (weh *workflowExecutionEventHandlerImpl) ProcessLocalActivityResult(lar *lo
 	lamd := localActivityMarkerData{
 		ActivityID: lar.task.activityID,
 		ReplayTime: weh.currentReplayTime.Add(time.Now().Sub(weh.currentLocalTime)),
+		Attempt:    lar.attempt,
+		Backoff:    lar.backoff,
 	}
 	if lar.err != nil {
 		errReason, errDetails := getErrorDetails(lar.err, weh.GetDataConverter())
@@ -112,21 +112,37 @@ type (
 		hostEnv                        *hostEnvImpl
 		laTunnel                       *localActivityTunnel
 		nonDeterministicWorkflowPolicy NonDeterministicWorkflowPolicy
+		unhandledSignalPolicy          UnhandledSignalPolicy
 		dataConverter                  encoded.DataConverter
+		contextPropagators             []ContextPropagator
+		enableHeaderEnvelope           bool
+		workerInterceptors             []WorkerInterceptor
+	}
+
+	// nondeterministicError describes the first mismatch found between the history and the decisions produced by
+	// replaying it.
+	nondeterministicError struct {
+		message    string
+		eventID    int64  // ID of the mismatching history event, 0 if the replay produced an extra decision
+		expected   string // the history event, empty if the replay produced an extra decision
+		produced   string // the replay decision, empty if the replay missed a decision
+		stackTrace string // workflow stack trace at the time of the mismatch
 	}
 
 	activityProvider func(name string) activity
 	// activityTaskHandlerImpl is the implementation of ActivityTaskHandler
 	activityTaskHandlerImpl struct {
-		taskListName     string
-		identity         string
-		service          workflowserviceclient.Interface
-		metricsScope     *metrics.TaggedScope
-		logger           *zap.Logger
-		userContext      context.Context
-		hostEnv          *hostEnvImpl
-		activityProvider activityProvider
-		dataConverter    encoded.DataConverter
+		taskListName       string
+		identity           string
+		service            workflowserviceclient.Interface
+		metricsScope       *metrics.TaggedScope
+		logger             *zap.Logger
+		userContext        context.Context
+		hostEnv            *hostEnvImpl
+		activityProvider   activityProvider
+		dataConverter      encoded.DataConverter
+		contextPropagators []ContextPropagator
+		workerInterceptors []WorkerInterceptor
 	}
 
 	// history wrapper method to help information about events.
@@ -314,7 +330,11 @@ func newWorkflowTaskHandler(
 		disableStickyExecution: params.DisableStickyExecution,
 		hostEnv:                hostEnv,
 		nonDeterministicWorkflowPolicy: params.NonDeterministicWorkflowPolicy,
+		unhandledSignalPolicy:          params.UnhandledSignalPolicy,
 		dataConverter:                  params.DataConverter,
+		contextPropagators:             params.ContextPropagators,
+		enableHeaderEnvelope:           params.EnableHeaderEnvelope,
+		workerInterceptors:             params.WorkerInterceptors,
 	}
 }
 
@@ -445,6 +465,10 @@ func (w *workflowExecutionContextImpl) clearState() {
 	w.previousStartedEventID = 0
 	w.newDecisions = nil
 	if w.eventHandler != nil {
+		// stop the in process retries of local activities that are still running, their results are dropped.
+		for _, task := range w.eventHandler.pendingLaTasks {
+			task.cancel()
+		}
 		w.eventHandler.Close()
 		w.eventHandler = nil
 	}
@@ -459,7 +483,11 @@ func (w *workflowExecutionContextImpl) createEventHandler() {
 		w.wth.enableLoggingInReplay,
 		w.wth.metricsScope,
 		w.wth.hostEnv,
-		w.wth.dataConverter).(*workflowExecutionEventHandlerImpl)
+		w.wth.dataConverter,
+		w.wth.contextPropagators,
+		w.wth.enableHeaderEnvelope,
+		w.wth.workerInterceptors,
+		w.wth.unhandledSignalPolicy).(*workflowExecutionEventHandlerImpl)
 }
 
 func resetHistory(task *s.PollForDecisionTaskResponse, historyIterator HistoryIterator) (*s.History, error) {
@@ -707,6 +735,9 @@ ProcessEvents:
 	if !skipReplayCheck {
 		// check if decisions from reply matches to the history events
 		if err := matchReplayWithHistory(replayDecisions, respondEvents); err != nil {
+			if nde, ok := err.(*nondeterministicError); ok {
+				nde.stackTrace = w.StackTrace()
+			}
 			w.wth.metricsScope.GetTaggedScope(tagWorkflowType, task.WorkflowType.GetName()).Counter(metrics.NonDeterministicError).Inc(1)
 			w.wth.logger.Error("Replay and history mismatch.",
 				zap.String(tagWorkflowType, task.WorkflowType.GetName()),
@@ -740,6 +771,12 @@ ProcessEvents:
 }
 
 func (w *workflowExecutionContextImpl) ProcessLocalActivityResult(lar *localActivityResult) (interface{}, error) {
+	if w.isDestroyed() || w.eventHandler.pendingLaTasks[lar.task.activityID] != lar.task {
+		// the workflow state was cleared while the local activity was running, the result belongs to a task that is
+		// no longer pending.
+		return nil, nil
+	}
+
 	err := w.eventHandler.ProcessLocalActivityResult(lar)
 	if err != nil {
 		return nil, err
@@ -760,6 +797,7 @@ func (w *workflowExecutionContextImpl) CompleteDecisionTask(waitLocalActivities
 			for activityID := range w.eventHandler.unstartedLaTasks {
 				task := w.eventHandler.pendingLaTasks[activityID]
 				task.wc = w
+				task.retryDeadline = w.decisionStartTime.Add(w.GetDecisionTimeout())
 				w.laTunnel.sendTask(task)
 			}
 			w.eventHandler.unstartedLaTasks = make(map[string]struct{})
@@ -884,16 +922,28 @@ matchLoop:
 		}
 
 		if d == nil {
-			return fmt.Errorf("nondeterministic workflow: missing replay decision for %s", util.HistoryEventToString(e))
+			return &nondeterministicError{
+				message:  fmt.Sprintf("nondeterministic workflow: missing replay decision for %s", util.HistoryEventToString(e)),
+				eventID:  e.GetEventId(),
+				expected: util.HistoryEventToString(e),
+			}
 		}
 
 		if e == nil {
-			return fmt.Errorf("nondeterministic workflow: extra replay decision for %s", util.DecisionToString(d))
+			return &nondeterministicError{
+				message:  fmt.Sprintf("nondeterministic workflow: extra replay decision for %s", util.DecisionToString(d)),
+				produced: util.DecisionToString(d),
+			}
 		}
 
 		if !isDecisionMatchEvent(d, e, false) {
-			return fmt.Errorf("nondeterministic workflow: history event is %s, replay decision is %s",
-				util.HistoryEventToString(e), util.DecisionToString(d))
+			return &nondeterministicError{
+				message: fmt.Sprintf("nondeterministic workflow: history event is %s, replay decision is %s",
+					util.HistoryEventToString(e), util.DecisionToString(d)),
+				eventID:  e.GetEventId(),
+				expected: util.HistoryEventToString(e),
+				produced: util.DecisionToString(d),
+			}
 		}
 
 		di++
@@ -902,6 +952,10 @@ matchLoop:
 	return nil
 }
 
+func (e *nondeterministicError) Error() string {
+	return e.message
+}
+
 func lastPartOfName(name string) string {
 	lastDotIdx := strings.LastIndex(name, ".")
 	if lastDotIdx < 0 || lastDotIdx == len(name)-1 {
@@ -1107,7 +1161,7 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 		result, err := eventHandler.ProcessQuery(task.Query.GetQueryType(), task.Query.QueryArgs)
 		if err != nil {
 			queryCompletedRequest.CompletedType = common.QueryTaskCompletedTypePtr(s.QueryTaskCompletedTypeFailed)
-			queryCompletedRequest.ErrorMessage = common.StringPtr(err.Error())
+			queryCompletedRequest.ErrorMessage = common.StringPtr(encodeQueryFailure(err, wth.dataConverter))
 		} else {
 			queryCompletedRequest.CompletedType = common.QueryTaskCompletedTypePtr(s.QueryTaskCompletedTypeCompleted)
 			queryCompletedRequest.QueryResult = result
@@ -1127,6 +1181,13 @@ func (wth *workflowTaskHandlerImpl) completeWorkflow(
 		return errorToFailDecisionTask(task.TaskToken, panicErr, wth.identity)
 	}
 
+	// fail decision task instead of dropping unhandled signals
+	if usErr, ok := workflowContext.err.(*unhandledSignalsError); ok {
+		wth.logger.Warn("Workflow completed with unhandled signals.",
+			zap.Strings("SignalNames", usErr.signalNames))
+		return errorToFailDecisionTask(task.TaskToken, usErr, wth.identity)
+	}
+
 	// complete decision task
 	var closeDecision *s.Decision
 	if canceledErr, ok := workflowContext.err.(*CanceledError); ok {
@@ -1224,15 +1285,17 @@ func newActivityTaskHandlerWithCustomProvider(
 	activityProvider activityProvider,
 ) ActivityTaskHandler {
 	return &activityTaskHandlerImpl{
-		taskListName:     params.TaskList,
-		identity:         params.Identity,
-		service:          service,
-		logger:           params.Logger,
-		metricsScope:     metrics.NewTaggedScope(params.MetricsScope),
-		userContext:      params.UserContext,
-		hostEnv:          env,
-		activityProvider: activityProvider,
-		dataConverter:    params.DataConverter,
+		taskListName:       params.TaskList,
+		identity:           params.Identity,
+		service:            service,
+		logger:             params.Logger,
+		metricsScope:       metrics.NewTaggedScope(params.MetricsScope),
+		userContext:        params.UserContext,
+		hostEnv:            env,
+		activityProvider:   activityProvider,
+		dataConverter:      params.DataConverter,
+		contextPropagators: params.ContextPropagators,
+		workerInterceptors: params.WorkerInterceptors,
 	}
 }
 
@@ -1372,6 +1435,7 @@ func (ath *activityTaskHandlerImpl) Execute(taskList string, t *s.PollForActivit
 	invoker := newServiceInvoker(t.TaskToken, ath.identity, ath.service, cancel, t.GetHeartbeatTimeoutSeconds())
 	defer invoker.Close()
 	ctx := WithActivityTask(canCtx, t, taskList, invoker, ath.logger, ath.metricsScope, ath.dataConverter)
+	getActivityEnv(ctx).workerInterceptors = ath.workerInterceptors
 	activityType := *t.ActivityType
 	activityImplementation := ath.getActivity(activityType.GetName())
 	if activityImplementation == nil {
@@ -1394,10 +1458,16 @@ func (ath *activityTaskHandlerImpl) Execute(taskList string, t *s.PollForActivit
 			result, err = convertActivityResultToRespondRequest(ath.identity, t.TaskToken, nil, panicErr, ath.dataConverter), nil
 		}
 	}()
+	header, input := decodeHeaderFromInput(t.Input)
+	ctx, err = extractHeaderToContext(ctx, ath.contextPropagators, header)
+	if err != nil {
+		return convertActivityResultToRespondRequest(ath.identity, t.TaskToken, nil, err, ath.dataConverter), nil
+	}
+
 	info := ctx.Value(activityEnvContextKey).(*activityEnvironment)
 	ctx, dlCancelFunc := context.WithDeadline(ctx, info.deadline)
 
-	output, err := activityImplementation.Execute(ctx, t.Input)
+	output, err := activityImplementation.Execute(ctx, input)
 
 	dlCancelFunc()
 	if <-ctx.Done(); ctx.Err() == context.DeadlineExceeded {
@@ -1420,7 +1490,7 @@ func (ath *activityTaskHandlerImpl) getActivity(name string) activity {
 }
 
 func (ath *activityTaskHandlerImpl) getRegisteredActivityNames() (activityNames []string) {
-	for _, a := range ath.hostEnv.activityFuncMap {
+	for _, a := range ath.hostEnv.getRegisteredActivities() {
 		activityNames = append(activityNames, a.ActivityType().Name)
 	}
 	return
@@ -31,6 +31,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 	"go.uber.org/cadence/.gen/go/cadence/workflowservicetest"
 	s "go.uber.org/cadence/.gen/go/shared"
 	"go.uber.org/cadence/internal/common"
@@ -251,6 +252,49 @@ func (t *TaskHandlersTestSuite) TestWorkflowTask_ActivityTaskScheduled() {
 	t.NotNil(response.Decisions[0].CompleteWorkflowExecutionDecisionAttributes)
 }
 
+func (t *TaskHandlersTestSuite) TestWorkflowTask_UnhandledSignalPolicy() {
+	taskList := "tl1"
+	testEvents := []*s.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &s.WorkflowExecutionStartedEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskScheduled(2, &s.DecisionTaskScheduledEventAttributes{TaskList: &s.TaskList{Name: &taskList}}),
+		createTestEventDecisionTaskStarted(3),
+		createTestEventDecisionTaskCompleted(4, &s.DecisionTaskCompletedEventAttributes{ScheduledEventId: common.Int64Ptr(2)}),
+		createTestEventActivityTaskScheduled(5, &s.ActivityTaskScheduledEventAttributes{
+			ActivityId:   common.StringPtr("0"),
+			ActivityType: &s.ActivityType{Name: common.StringPtr("Greeter_Activity")},
+			TaskList:     &s.TaskList{Name: &taskList},
+		}),
+		createTestEventActivityTaskStarted(6, &s.ActivityTaskStartedEventAttributes{}),
+		createTestEventActivityTaskCompleted(7, &s.ActivityTaskCompletedEventAttributes{ScheduledEventId: common.Int64Ptr(5)}),
+		createTestEventWorkflowExecutionSignaled(8, "test-signal"),
+		createTestEventDecisionTaskStarted(9),
+	}
+	params := workerExecutionParameters{
+		TaskList: taskList,
+		Identity: "test-id-1",
+		Logger:   t.logger,
+	}
+
+	// the unhandled signal is dropped by default
+	task := createWorkflowTask(testEvents, 3, "HelloWorld_Workflow")
+	taskHandler := newWorkflowTaskHandler(testDomain, params, nil, getHostEnvironment())
+	request, _, err := taskHandler.ProcessWorkflowTask(task, nil)
+	t.NoError(err)
+	response := request.(*s.RespondDecisionTaskCompletedRequest)
+	t.Equal(1, len(response.Decisions))
+	t.Equal(s.DecisionTypeCompleteWorkflowExecution, response.Decisions[0].GetDecisionType())
+
+	params.UnhandledSignalPolicy = UnhandledSignalPolicyFailDecision
+	task = createWorkflowTask(testEvents, 3, "HelloWorld_Workflow")
+	taskHandler = newWorkflowTaskHandler(testDomain, params, nil, getHostEnvironment())
+	request, _, err = taskHandler.ProcessWorkflowTask(task, nil)
+	t.NoError(err)
+	failedRequest, ok := request.(*s.RespondDecisionTaskFailedRequest)
+	t.True(ok)
+	t.Equal(s.DecisionTaskFailedCauseWorkflowWorkerUnhandledFailure, failedRequest.GetCause())
+	t.Contains(string(failedRequest.Details), "test-signal")
+}
+
 func (t *TaskHandlersTestSuite) TestWorkflowTask_QueryWorkflow_Sticky() {
 	// Schedule an activity and see if we complete workflow.
 	taskList := "sticky-tl"
@@ -653,6 +697,69 @@ func (t *TaskHandlersTestSuite) TestActivityExecutionDeadline() {
 	}
 }
 
+func (t *TaskHandlersTestSuite) TestLocalActivityRetry_InProcess() {
+	var attempts []int
+	activityFn := func(ctx context.Context) (int, error) {
+		attempt := GetActivityInfo(ctx).Attempt
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return 0, NewCustomError("retry-me")
+		}
+		return attempt, nil
+	}
+	newTask := func(initialInterval time.Duration, retryDeadline time.Time) *localActivityTask {
+		return &localActivityTask{
+			activityID: "0",
+			params: &executeLocalActivityParams{
+				localActivityOptions: localActivityOptions{
+					ScheduleToCloseTimeoutSeconds: 1,
+					RetryPolicy: &RetryPolicy{
+						InitialInterval:    initialInterval,
+						BackoffCoefficient: 2,
+						MaximumInterval:    time.Minute,
+						MaximumAttempts:    5,
+					},
+				},
+				ActivityFn:   activityFn,
+				WorkflowInfo: &WorkflowInfo{},
+			},
+			scheduledTime: time.Now(),
+			retryDeadline: retryDeadline,
+		}
+	}
+	taskHandler := &localActivityTaskHandler{
+		metricsScope:  tally.NoopScope,
+		logger:        t.logger,
+		dataConverter: getDefaultDataConverter(),
+	}
+
+	// short backoffs are retried by the worker within the decision task
+	task := newTask(time.Millisecond, time.Now().Add(time.Second))
+	result := taskHandler.executeLocalActivityTaskWithRetry(task)
+	t.NoError(result.err)
+	t.Equal(int32(2), result.attempt)
+	t.Equal(time.Duration(0), result.backoff)
+	t.Equal([]int{0, 1, 2}, attempts)
+	t.Equal(int32(0), task.attempt)
+
+	// a backoff past the end of the decision task is handed back to the workflow
+	attempts = nil
+	task = newTask(time.Minute, time.Now().Add(time.Second))
+	result = taskHandler.executeLocalActivityTaskWithRetry(task)
+	t.Error(result.err)
+	t.Equal(int32(0), result.attempt)
+	t.Equal(time.Minute, result.backoff)
+	t.Equal([]int{0}, attempts)
+
+	// a task canceled while it waits for the next attempt is not retried
+	attempts = nil
+	task = newTask(time.Second, time.Now().Add(time.Minute))
+	time.AfterFunc(100*time.Millisecond, task.cancel)
+	result = taskHandler.executeLocalActivityTaskWithRetry(task)
+	t.Equal(ErrCanceled, result.err)
+	t.Equal([]int{0}, attempts)
+}
+
 func Test_NonDeterministicCheck(t *testing.T) {
 	decisionTypes := s.DecisionType_Values()
 	require.Equal(t, 12, len(decisionTypes), "If you see this error, you are adding new decision type. "+
@@ -110,16 +110,20 @@ type (
 	}
 
 	localActivityTaskHandler struct {
-		userContext   context.Context
-		metricsScope  tally.Scope
-		logger        *zap.Logger
-		dataConverter encoded.DataConverter
+		userContext        context.Context
+		metricsScope       tally.Scope
+		logger             *zap.Logger
+		dataConverter      encoded.DataConverter
+		contextPropagators []ContextPropagator
+		workerInterceptors []WorkerInterceptor
 	}
 
 	localActivityResult struct {
-		result []byte
-		err    error
-		task   *localActivityTask
+		result  []byte
+		err     error
+		task    *localActivityTask
+		attempt int32         // attempt that produced this result, starts from 0.
+		backoff time.Duration // set when the next attempt has to be scheduled by a workflow timer
 	}
 
 	localActivityTunnel struct {
@@ -439,10 +443,12 @@ func (wtp *workflowTaskPoller) RespondTaskCompleted(completedRequest interface{}
 
 func newLocalActivityPoller(params workerExecutionParameters, laTunnel *localActivityTunnel) *localActivityTaskPoller {
 	handler := &localActivityTaskHandler{
-		userContext:   params.UserContext,
-		metricsScope:  params.MetricsScope,
-		logger:        params.Logger,
-		dataConverter: params.DataConverter,
+		userContext:        params.UserContext,
+		metricsScope:       params.MetricsScope,
+		logger:             params.Logger,
+		dataConverter:      params.DataConverter,
+		contextPropagators: params.ContextPropagators,
+		workerInterceptors: params.WorkerInterceptors,
 	}
 	return &localActivityTaskPoller{
 		handler:      handler,
@@ -457,12 +463,37 @@ func (latp *localActivityTaskPoller) PollTask() (interface{}, error) {
 }
 
 func (latp *localActivityTaskPoller) ProcessTask(task interface{}) error {
-	result := latp.handler.executeLocalActivityTask(task.(*localActivityTask))
+	result := latp.handler.executeLocalActivityTaskWithRetry(task.(*localActivityTask))
 	latp.laTunnel.deliverResult(result)
 	return nil
 }
 
-func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivityTask) (result *localActivityResult) {
+// executeLocalActivityTaskWithRetry runs the local activity task and retries it in process as long as the retry
+// backoff fits into the time left for the decision task that started it. A longer backoff is returned with the
+// result, the workflow then waits it out with a timer before it schedules the next attempt.
+func (lath *localActivityTaskHandler) executeLocalActivityTaskWithRetry(task *localActivityTask) *localActivityResult {
+	startTime := time.Now()
+	attempt := task.attempt
+	for {
+		result := lath.executeLocalActivityTask(task, attempt)
+		// advance the workflow clock by the time spent on the task, the retry expiration is on the workflow clock.
+		now := task.scheduledTime.Add(time.Now().Sub(startTime))
+		backoff := getRetryBackoff(result, now, lath.dataConverter)
+		if backoff <= 0 {
+			return result
+		}
+		if time.Now().Add(backoff).After(task.retryDeadline) {
+			result.backoff = backoff
+			return result
+		}
+		if !task.waitRetryBackoff(backoff) {
+			return &localActivityResult{err: ErrCanceled, task: task, attempt: attempt}
+		}
+		attempt++
+	}
+}
+
+func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivityTask, attempt int32) (result *localActivityResult) {
 	lath.metricsScope.Counter(metrics.LocalActivityTotalCounter).Inc(1)
 	activityType := getFunctionName(task.params.ActivityFn)
 	ae := activityExecutor{name: activityType, fn: task.params.ActivityFn}
@@ -473,13 +504,15 @@ func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivi
 	}
 
 	ctx := context.WithValue(rootCtx, activityEnvContextKey, &activityEnvironment{
-		activityType:      ActivityType{Name: activityType},
-		activityID:        fmt.Sprintf("%v", task.activityID),
-		workflowExecution: task.params.WorkflowInfo.WorkflowExecution,
-		logger:            lath.logger,
-		metricsScope:      lath.metricsScope,
-		isLocalActivity:   true,
-		dataConverter:     lath.dataConverter,
+		activityType:       ActivityType{Name: activityType},
+		activityID:         fmt.Sprintf("%v", task.activityID),
+		workflowExecution:  task.params.WorkflowInfo.WorkflowExecution,
+		logger:             lath.logger,
+		metricsScope:       lath.metricsScope,
+		isLocalActivity:    true,
+		dataConverter:      lath.dataConverter,
+		attempt:            int(attempt),
+		workerInterceptors: lath.workerInterceptors,
 	})
 
 	// panic handler
@@ -501,8 +534,14 @@ func (lath *localActivityTaskHandler) executeLocalActivityTask(task *localActivi
 		if result.err != nil {
 			lath.metricsScope.Counter(metrics.LocalActivityFailedCounter).Inc(1)
 		}
+		result.attempt = attempt
 	}()
 
+	ctx, headerErr := extractHeaderToContext(ctx, lath.contextPropagators, task.params.Header)
+	if headerErr != nil {
+		return &localActivityResult{err: headerErr, task: task}
+	}
+
 	timeoutDuration := time.Duration(task.params.ScheduleToCloseTimeoutSeconds) * time.Second
 	deadline := time.Now().Add(timeoutDuration)
 	ctx, cancel := context.WithDeadline(ctx, deadline)
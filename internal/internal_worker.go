@@ -30,6 +30,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -70,6 +71,7 @@ const (
 
 // Assert that structs do indeed implement the interfaces
 var _ Worker = (*aggregatedWorker)(nil)
+var _ Registry = (*aggregatedWorker)(nil)
 
 type (
 	// WorkflowWorker wraps the code for hosting workflow types.
@@ -154,7 +156,21 @@ type (
 		// mismatched history events (presumably arising from non-deterministic workflow definitions).
 		NonDeterministicWorkflowPolicy NonDeterministicWorkflowPolicy
 
+		// UnhandledSignalPolicy is used for configuring how client's decision task handler deals with signals which
+		// are not handled when the workflow completes.
+		UnhandledSignalPolicy UnhandledSignalPolicy
+
 		DataConverter encoded.DataConverter
+
+		// ContextPropagators is used to rehydrate context information passed through workflow and activity headers.
+		ContextPropagators []ContextPropagator
+
+		// EnableHeaderEnvelope is used to send the header written by ContextPropagators in front of the input of
+		// activities, child workflows and continued runs.
+		EnableHeaderEnvelope bool
+
+		// WorkerInterceptors wrap workflow and activity executions.
+		WorkerInterceptors []WorkerInterceptor
 	}
 
 	// defaultDataConverter uses thrift encoder/decoder when possible, for everything else use json.
@@ -168,7 +184,7 @@ func newWorkflowWorker(
 	params workerExecutionParameters,
 	ppMgr pressurePointMgr,
 	hostEnv *hostEnvImpl,
-) Worker {
+) *workflowWorker {
 	return newWorkflowWorkerInternal(service, domain, params, ppMgr, nil, hostEnv)
 }
 
@@ -235,7 +251,7 @@ func newWorkflowWorkerInternal(
 	ppMgr pressurePointMgr,
 	overrides *workerOverrides,
 	hostEnv *hostEnvImpl,
-) Worker {
+) *workflowWorker {
 	// Get a workflow task handler.
 	ensureRequiredParams(&params)
 	var taskHandler WorkflowTaskHandler
@@ -252,7 +268,7 @@ func newWorkflowTaskWorkerInternal(
 	service workflowserviceclient.Interface,
 	domain string,
 	params workerExecutionParameters,
-) Worker {
+) *workflowWorker {
 	ensureRequiredParams(&params)
 	poller := newWorkflowTaskPoller(
 		taskHandler,
@@ -343,7 +359,7 @@ func newActivityWorker(
 	params workerExecutionParameters,
 	overrides *workerOverrides,
 	env *hostEnvImpl,
-) Worker {
+) *activityWorker {
 	ensureRequiredParams(&params)
 	// Get a activity task handler.
 	var taskHandler ActivityTaskHandler
@@ -360,7 +376,7 @@ func newActivityTaskWorker(
 	service workflowserviceclient.Interface,
 	domain string,
 	workerParams workerExecutionParameters,
-) (worker Worker) {
+) (worker *activityWorker) {
 	ensureRequiredParams(&workerParams)
 
 	poller := newActivityTaskPoller(
@@ -426,6 +442,15 @@ type hostEnvImpl struct {
 	workflowAliasMap map[string]string
 	activityFuncMap  map[string]activity
 	activityAliasMap map[string]string
+	// fallback is the global registry, it is set for the registry of a worker. Workflows (activities) are looked up
+	// in the fallback as long as no workflow (activity) is registered with the worker. Aliases are always looked up
+	// in the fallback, so a function is resolved to its registered name wherever it is registered.
+	fallback *hostEnvImpl
+	// workerWorkflowAliases and workerActivityAliases are only used by the global registry. They index the aliases
+	// registered with the registries of workers by function name, so that clients and workflows of the process
+	// resolve a function to the name its worker knows it by.
+	workerWorkflowAliases map[string]map[string]bool
+	workerActivityAliases map[string]map[string]bool
 }
 
 func (th *hostEnvImpl) RegisterWorkflow(af interface{}) error {
@@ -448,7 +473,7 @@ func (th *hostEnvImpl) RegisterWorkflowWithOptions(
 		registerName = alias
 	}
 	// Check if already registered
-	if _, ok := th.getWorkflowFn(registerName); ok {
+	if th.hasWorkflowFn(registerName) {
 		return fmt.Errorf("workflow name \"%v\" is already registered", registerName)
 	}
 	th.addWorkflowFn(registerName, af)
@@ -466,8 +491,11 @@ func (th *hostEnvImpl) RegisterActivityWithOptions(
 	af interface{},
 	options RegisterActivityOptions,
 ) error {
-	// Validate that it is a function
 	fnType := reflect.TypeOf(af)
+	if fnType != nil && fnType.Kind() == reflect.Ptr && fnType.Elem().Kind() == reflect.Struct {
+		return th.registerActivityStructWithOptions(af, options)
+	}
+	// Validate that it is a function
 	if err := validateFnFormat(fnType, false); err != nil {
 		return err
 	}
@@ -478,7 +506,7 @@ func (th *hostEnvImpl) RegisterActivityWithOptions(
 		registerName = alias
 	}
 	// Check if already registered
-	if _, ok := th.getActivityFn(registerName); ok {
+	if th.hasActivity(registerName) {
 		return fmt.Errorf("activity type \"%v\" is already registered", registerName)
 	}
 	th.addActivityFn(registerName, af)
@@ -488,17 +516,81 @@ func (th *hostEnvImpl) RegisterActivityWithOptions(
 	return nil
 }
 
+// registerActivityStructWithOptions registers every exported method of the struct pointer aStruct which has the
+// format of an activity. The method values are registered, so the activities run against the given instance.
+// A method value like a.Charge does not tell which instance it is bound to, so ExecuteActivity resolves it by the
+// struct type and method name. Only one instance of a struct type can thus be registered with a host environment.
+func (th *hostEnvImpl) registerActivityStructWithOptions(aStruct interface{}, options RegisterActivityOptions) error {
+	if len(options.Name) > 0 {
+		return errors.New("Name is not supported when registering a struct, use Prefix instead")
+	}
+	structValue := reflect.ValueOf(aStruct)
+	structType := structValue.Type()
+	var fnNames, registerNames []string
+	var methodValues []interface{}
+	for i := 0; i < structValue.NumMethod(); i++ {
+		methodValue := structValue.Method(i)
+		method := structType.Method(i)
+		// skip methods which are not activities, like helpers shared by the activities.
+		if err := validateFnFormat(methodValue.Type(), false); err != nil {
+			continue
+		}
+		// A method value like a.Charge is named after the method with a "-fm" suffix, see getActivityFunctionName.
+		fnName := getFunctionName(method.Func.Interface())
+		if th.hasActivityAlias(fnName) {
+			return fmt.Errorf("an instance of %v is already registered, a struct type can only be registered once", structType)
+		}
+		registerName := options.Prefix + method.Name
+		if th.hasActivity(registerName) {
+			return fmt.Errorf("activity type \"%v\" is already registered", registerName)
+		}
+		fnNames = append(fnNames, fnName)
+		registerNames = append(registerNames, registerName)
+		methodValues = append(methodValues, methodValue.Interface())
+	}
+	if len(registerNames) == 0 {
+		return fmt.Errorf("no activities (public methods) found in the struct %v", structType)
+	}
+	for i, registerName := range registerNames {
+		th.addActivityFn(registerName, methodValues[i])
+		th.addActivityAlias(fnNames[i], registerName)
+	}
+	return nil
+}
+
 func (th *hostEnvImpl) addWorkflowAlias(fnName string, alias string) {
 	th.Lock()
-	defer th.Unlock()
 	th.workflowAliasMap[fnName] = alias
+	th.Unlock()
+	if th.fallback != nil {
+		th.fallback.indexWorkerAlias(th.fallback.workerWorkflowAliases, fnName, alias)
+	}
 }
 
 func (th *hostEnvImpl) getWorkflowAlias(fnName string) (string, bool) {
 	th.Lock()
-	defer th.Unlock()
 	alias, ok := th.workflowAliasMap[fnName]
-	return alias, ok
+	th.Unlock()
+	if ok {
+		return alias, ok
+	}
+	if th.fallback != nil {
+		return th.fallback.getWorkflowAlias(fnName)
+	}
+	return th.getWorkerAlias(th.workerWorkflowAliases, fnName)
+}
+
+// checkWorkflowAlias returns an error if the workflow function is registered under different names by the workers
+// of the process, and none of them is the registry's own.
+func (th *hostEnvImpl) checkWorkflowAlias(fnName string) error {
+	if _, ok := th.getWorkflowAlias(fnName); ok {
+		return nil
+	}
+	global := th
+	if th.fallback != nil {
+		global = th.fallback
+	}
+	return global.checkWorkerAlias(global.workerWorkflowAliases, "workflow", fnName)
 }
 
 func (th *hostEnvImpl) addWorkflowFn(fnName string, wf interface{}) {
@@ -509,32 +601,115 @@ func (th *hostEnvImpl) addWorkflowFn(fnName string, wf interface{}) {
 
 func (th *hostEnvImpl) getWorkflowFn(fnName string) (interface{}, bool) {
 	th.Lock()
-	defer th.Unlock()
 	fn, ok := th.workflowFuncMap[fnName]
+	useFallback := len(th.workflowFuncMap) == 0 && th.fallback != nil
+	th.Unlock()
+	if !ok && useFallback {
+		return th.fallback.getWorkflowFn(fnName)
+	}
 	return fn, ok
 }
 
-func (th *hostEnvImpl) getRegisteredWorkflowTypes() []string {
+// hasWorkflowFn checks if the workflow is registered with this host environment, ignoring the fallback.
+func (th *hostEnvImpl) hasWorkflowFn(fnName string) bool {
 	th.Lock()
 	defer th.Unlock()
+	_, ok := th.workflowFuncMap[fnName]
+	return ok
+}
+
+func (th *hostEnvImpl) getRegisteredWorkflowTypes() []string {
+	th.Lock()
 	var r []string
 	for t := range th.workflowFuncMap {
 		r = append(r, t)
 	}
+	th.Unlock()
+	if len(r) == 0 && th.fallback != nil {
+		return th.fallback.getRegisteredWorkflowTypes()
+	}
 	return r
 }
 
 func (th *hostEnvImpl) addActivityAlias(fnName string, alias string) {
 	th.Lock()
-	defer th.Unlock()
 	th.activityAliasMap[fnName] = alias
+	th.Unlock()
+	if th.fallback != nil {
+		th.fallback.indexWorkerAlias(th.fallback.workerActivityAliases, fnName, alias)
+	}
 }
 
 func (th *hostEnvImpl) getActivityAlias(fnName string) (string, bool) {
 	th.Lock()
-	defer th.Unlock()
 	alias, ok := th.activityAliasMap[fnName]
-	return alias, ok
+	th.Unlock()
+	if ok {
+		return alias, ok
+	}
+	if th.fallback != nil {
+		return th.fallback.getActivityAlias(fnName)
+	}
+	return th.getWorkerAlias(th.workerActivityAliases, fnName)
+}
+
+// hasActivityAlias checks if the activity function has an alias in this host environment, ignoring the fallback.
+func (th *hostEnvImpl) hasActivityAlias(fnName string) bool {
+	th.Lock()
+	defer th.Unlock()
+	_, ok := th.activityAliasMap[fnName]
+	return ok
+}
+
+// checkActivityAlias returns an error if the activity function is registered under different names by the workers
+// of the process, and none of them is the registry's own.
+func (th *hostEnvImpl) checkActivityAlias(fnName string) error {
+	if _, ok := th.getActivityAlias(fnName); ok {
+		return nil
+	}
+	global := th
+	if th.fallback != nil {
+		global = th.fallback
+	}
+	return global.checkWorkerAlias(global.workerActivityAliases, "activity", fnName)
+}
+
+// indexWorkerAlias records that a worker registered the function under alias.
+func (th *hostEnvImpl) indexWorkerAlias(index map[string]map[string]bool, fnName string, alias string) {
+	th.Lock()
+	defer th.Unlock()
+	if index[fnName] == nil {
+		index[fnName] = make(map[string]bool)
+	}
+	index[fnName][alias] = true
+}
+
+// getWorkerAlias returns the alias the function is registered under by workers, if it is a single one.
+func (th *hostEnvImpl) getWorkerAlias(index map[string]map[string]bool, fnName string) (string, bool) {
+	th.Lock()
+	defer th.Unlock()
+	if len(index[fnName]) != 1 {
+		return "", false
+	}
+	for alias := range index[fnName] {
+		return alias, true
+	}
+	return "", false
+}
+
+func (th *hostEnvImpl) checkWorkerAlias(index map[string]map[string]bool, kind string, fnName string) error {
+	th.Lock()
+	defer th.Unlock()
+	if len(index[fnName]) < 2 {
+		return nil
+	}
+	var aliases []string
+	for alias := range index[fnName] {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return fmt.Errorf("%v function %v is registered by workers under the names %v, use the name instead of the function",
+		kind, fnName, strings.Join(aliases, ", "))
 }
 
 func (th *hostEnvImpl) addActivity(fnName string, a activity) {
@@ -549,11 +724,23 @@ func (th *hostEnvImpl) addActivityFn(fnName string, af interface{}) {
 
 func (th *hostEnvImpl) getActivity(fnName string) (activity, bool) {
 	th.Lock()
-	defer th.Unlock()
 	a, ok := th.activityFuncMap[fnName]
+	useFallback := len(th.activityFuncMap) == 0 && th.fallback != nil
+	th.Unlock()
+	if !ok && useFallback {
+		return th.fallback.getActivity(fnName)
+	}
 	return a, ok
 }
 
+// hasActivity checks if the activity is registered with this host environment, ignoring the fallback.
+func (th *hostEnvImpl) hasActivity(fnName string) bool {
+	th.Lock()
+	defer th.Unlock()
+	_, ok := th.activityFuncMap[fnName]
+	return ok
+}
+
 func (th *hostEnvImpl) getActivityFn(fnName string) (interface{}, bool) {
 	if a, ok := th.getActivity(fnName); ok {
 		return a.GetFunction(), ok
@@ -562,10 +749,15 @@ func (th *hostEnvImpl) getActivityFn(fnName string) (interface{}, bool) {
 }
 
 func (th *hostEnvImpl) getRegisteredActivities() []activity {
+	th.Lock()
 	activities := make([]activity, 0, len(th.activityFuncMap))
 	for _, a := range th.activityFuncMap {
 		activities = append(activities, a)
 	}
+	th.Unlock()
+	if len(activities) == 0 && th.fallback != nil {
+		return th.fallback.getRegisteredActivities()
+	}
 	return activities
 }
 
@@ -744,9 +936,20 @@ func newHostEnvironment() *hostEnvImpl {
 		workflowAliasMap: make(map[string]string),
 		activityFuncMap:  make(map[string]activity),
 		activityAliasMap: make(map[string]string),
+
+		workerWorkflowAliases: make(map[string]map[string]bool),
+		workerActivityAliases: make(map[string]map[string]bool),
 	}
 }
 
+// newWorkerHostEnvironment creates the registry of a single worker. The worker falls back to the workflows and the
+// activities of the global registry as long as none is registered with it.
+func newWorkerHostEnvironment() *hostEnvImpl {
+	env := newHostEnvironment()
+	env.fallback = getHostEnvironment()
+	return env
+}
+
 func getHostEnvironment() *hostEnvImpl {
 	once.Do(func() {
 		thImpl = newHostEnvironment()
@@ -762,14 +965,13 @@ type workflowExecutor struct {
 
 func (we *workflowExecutor) Execute(ctx Context, input []byte) ([]byte, error) {
 	fnType := reflect.TypeOf(we.fn)
-	// Workflow context.
-	args := []reflect.Value{reflect.ValueOf(ctx)}
+	var args []interface{}
 
 	dataConverter := getWorkflowEnvOptions(ctx).dataConverter
 	if fnType.NumIn() > 1 && isTypeByteSlice(fnType.In(1)) {
 		// 0 - is workflow context.
 		// 1 ... input types.
-		args = append(args, reflect.ValueOf(input))
+		args = append(args, input)
 	} else {
 		decoded, err := decodeArgs(dataConverter, fnType, input)
 		if err != nil {
@@ -777,13 +979,27 @@ func (we *workflowExecutor) Execute(ctx Context, input []byte) ([]byte, error) {
 				"unable to decode the workflow function input bytes with error: %v, function name: %v",
 				err, we.name)
 		}
-		args = append(args, decoded...)
+		args = append(args, valuesToInterfaces(decoded)...)
 	}
 
+	envInterceptor := &workflowEnvironmentInterceptor{fn: we.fn}
+	interceptor := newWorkflowInterceptorChain(getWorkflowEnvironment(ctx).GetWorkerInterceptors(), GetWorkflowInfo(ctx), envInterceptor)
+	ctx = WithValue(ctx, workflowInterceptorContextKey, interceptor)
+	result, err := interceptor.ExecuteWorkflow(ctx, we.name, args...)
+	return encodeFunctionResult(dataConverter, result, err)
+}
+
+// ExecuteWorkflow invokes the workflow function, it is the innermost call of the workflow interceptor chain.
+func (wc *workflowEnvironmentInterceptor) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) (interface{}, error) {
+	fnType := reflect.TypeOf(wc.fn)
+	// Workflow context.
+	argValues := []reflect.Value{reflect.ValueOf(ctx)}
+	argValues = append(argValues, interfacesToValues(fnType, 1, args)...)
+
 	// Invoke the workflow with arguments.
-	fnValue := reflect.ValueOf(we.fn)
-	retValues := fnValue.Call(args)
-	return validateFunctionAndGetResults(we.fn, retValues, dataConverter)
+	fnValue := reflect.ValueOf(wc.fn)
+	retValues := fnValue.Call(argValues)
+	return getFunctionResults(wc.fn, retValues)
 }
 
 // Wrapper to execute activity functions.
@@ -802,16 +1018,11 @@ func (ae *activityExecutor) GetFunction() interface{} {
 
 func (ae *activityExecutor) Execute(ctx context.Context, input []byte) ([]byte, error) {
 	fnType := reflect.TypeOf(ae.fn)
-	args := []reflect.Value{}
+	var args []interface{}
 	dataConverter := getDataConverterFromActivityCtx(ctx)
 
-	// activities optionally might not take context.
-	if fnType.NumIn() > 0 && isActivityContext(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(ctx))
-	}
-
 	if fnType.NumIn() == 1 && isTypeByteSlice(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(input))
+		args = append(args, input)
 	} else {
 		decoded, err := decodeArgs(dataConverter, fnType, input)
 		if err != nil {
@@ -819,37 +1030,42 @@ func (ae *activityExecutor) Execute(ctx context.Context, input []byte) ([]byte,
 				"unable to decode the activity function input bytes with error: %v for function name: %v",
 				err, ae.name)
 		}
-		args = append(args, decoded...)
+		args = append(args, valuesToInterfaces(decoded)...)
 	}
 
-	fnValue := reflect.ValueOf(ae.fn)
-	retValues := fnValue.Call(args)
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	return ae.executeWithInterceptors(ctx, args, dataConverter)
 }
 
 func (ae *activityExecutor) ExecuteWithActualArgs(ctx context.Context, actualArgs []interface{}) ([]byte, error) {
+	return ae.executeWithInterceptors(ctx, actualArgs, getDataConverterFromActivityCtx(ctx))
+}
+
+func (ae *activityExecutor) executeWithInterceptors(ctx context.Context, args []interface{}, dataConverter encoded.DataConverter) ([]byte, error) {
+	var interceptor ActivityInterceptor = ae
+	if env, ok := ctx.Value(activityEnvContextKey).(*activityEnvironment); ok && len(env.workerInterceptors) > 0 {
+		info := GetActivityInfo(ctx)
+		interceptor = newActivityInterceptorChain(env.workerInterceptors, &info, interceptor)
+	}
+	result, err := interceptor.ExecuteActivity(ctx, ae.name, args...)
+	return encodeFunctionResult(dataConverter, result, err)
+}
+
+// ExecuteActivity invokes the activity function, it is the innermost call of the activity interceptor chain.
+func (ae *activityExecutor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (interface{}, error) {
 	fnType := reflect.TypeOf(ae.fn)
-	args := []reflect.Value{}
-	dataConverter := getDataConverterFromActivityCtx(ctx)
+	argValues := []reflect.Value{}
 
 	// activities optionally might not take context.
-	argsOffeset := 0
+	argsOffset := 0
 	if fnType.NumIn() > 0 && isActivityContext(fnType.In(0)) {
-		args = append(args, reflect.ValueOf(ctx))
-		argsOffeset = 1
-	}
-
-	for i, arg := range actualArgs {
-		if arg == nil {
-			args = append(args, reflect.New(fnType.In(i+argsOffeset)).Elem())
-		} else {
-			args = append(args, reflect.ValueOf(arg))
-		}
+		argValues = append(argValues, reflect.ValueOf(ctx))
+		argsOffset = 1
 	}
+	argValues = append(argValues, interfacesToValues(fnType, argsOffset, args)...)
 
 	fnValue := reflect.ValueOf(ae.fn)
-	retValues := fnValue.Call(args)
-	return validateFunctionAndGetResults(ae.fn, retValues, dataConverter)
+	retValues := fnValue.Call(argValues)
+	return getFunctionResults(ae.fn, retValues)
 }
 
 func getDataConverterFromActivityCtx(ctx context.Context) encoded.DataConverter {
@@ -865,12 +1081,32 @@ func getDataConverterFromActivityCtx(ctx context.Context) encoded.DataConverter
 
 // aggregatedWorker combines management of both workflowWorker and activityWorker worker lifecycle.
 type aggregatedWorker struct {
-	workflowWorker Worker
-	activityWorker Worker
+	workflowWorker *workflowWorker
+	activityWorker *activityWorker
 	logger         *zap.Logger
 	hostEnv        *hostEnvImpl
 }
 
+func (aw *aggregatedWorker) RegisterWorkflow(w interface{}) {
+	aw.RegisterWorkflowWithOptions(w, RegisterWorkflowOptions{})
+}
+
+func (aw *aggregatedWorker) RegisterWorkflowWithOptions(w interface{}, options RegisterWorkflowOptions) {
+	if err := aw.hostEnv.RegisterWorkflowWithOptions(w, options); err != nil {
+		panic(err)
+	}
+}
+
+func (aw *aggregatedWorker) RegisterActivity(a interface{}) {
+	aw.RegisterActivityWithOptions(a, RegisterActivityOptions{})
+}
+
+func (aw *aggregatedWorker) RegisterActivityWithOptions(a interface{}, options RegisterActivityOptions) {
+	if err := aw.hostEnv.RegisterActivityWithOptions(a, options); err != nil {
+		panic(err)
+	}
+}
+
 func (aw *aggregatedWorker) Start() error {
 	if !isInterfaceNil(aw.workflowWorker) {
 		if len(aw.hostEnv.getRegisteredWorkflowTypes()) == 0 {
@@ -948,7 +1184,11 @@ func newAggregatedWorker(
 		StickyScheduleToStartTimeout:         wOptions.StickyScheduleToStartTimeout,
 		TaskListActivitiesPerSecond:          wOptions.TaskListActivitiesPerSecond,
 		NonDeterministicWorkflowPolicy:       wOptions.NonDeterministicWorkflowPolicy,
+		UnhandledSignalPolicy:                wOptions.UnhandledSignalPolicy,
 		DataConverter:                        wOptions.DataConverter,
+		ContextPropagators:                   withTracingContextPropagator(wOptions.Tracer, wOptions.ContextPropagators),
+		EnableHeaderEnvelope:                 wOptions.EnableHeaderEnvelope,
+		WorkerInterceptors:                   withTracingWorkerInterceptor(wOptions.Tracer, wOptions.Interceptors),
 	}
 
 	ensureRequiredParams(&workerParams)
@@ -963,9 +1203,9 @@ func newAggregatedWorker(
 
 	processTestTags(&wOptions, &workerParams)
 
-	hostEnv := getHostEnvironment()
+	hostEnv := newWorkerHostEnvironment()
 	// workflow factory.
-	var workflowWorker Worker
+	var workflowWorker *workflowWorker
 	if !wOptions.DisableWorkflowWorker {
 		testTags := getTestTags(wOptions.BackgroundActivityContext)
 		if testTags != nil && len(testTags) > 0 {
@@ -988,7 +1228,7 @@ func newAggregatedWorker(
 	}
 
 	// activity types.
-	var activityWorker Worker
+	var activityWorker *activityWorker
 
 	if !wOptions.DisableActivityWorker {
 		activityWorker = newActivityWorker(
@@ -51,6 +51,9 @@ type (
 	// resultHandler that returns result
 	resultHandler func(result []byte, err error)
 
+	// laResultHandler that returns local activity result along with its retry state
+	laResultHandler func(lar *localActivityResultWrapper)
+
 	// workflowEnvironment Represents the environment for workflow/decider.
 	// Should only be used within the scope of workflow definition
 	workflowEnvironment interface {
@@ -73,6 +76,11 @@ type (
 		IsReplaying() bool
 		MutableSideEffect(id string, f func() interface{}, equals func(a, b interface{}) bool) encoded.Value
 		GetDataConverter() encoded.DataConverter
+		GetContextPropagators() []ContextPropagator
+		IsHeaderEnvelopeEnabled() bool
+		GetWorkerInterceptors() []WorkerInterceptor
+		GetUnhandledSignalPolicy() UnhandledSignalPolicy
+		GetRegistry() *hostEnvImpl
 	}
 
 	// WorkflowDefinition wraps the code that can execute a workflow.
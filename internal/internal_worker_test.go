@@ -164,6 +164,66 @@ func (s *internalWorkerTestSuite) TestReplayWorkflowHistoryFromFile() {
 	require.NoError(s.T(), err)
 }
 
+func (s *internalWorkerTestSuite) TestWorkflowReplayer() {
+	taskList := "taskList1"
+	createHistory := func(activityType string) *shared.History {
+		return &shared.History{Events: []*shared.HistoryEvent{
+			createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{
+				WorkflowType: &shared.WorkflowType{Name: common.StringPtr("go.uber.org/cadence/internal.testReplayWorkflow")},
+				TaskList:     &shared.TaskList{Name: common.StringPtr(taskList)},
+				Input:        testEncodeFunctionArgs(nil, testReplayWorkflow),
+			}),
+			createTestEventDecisionTaskScheduled(2, &shared.DecisionTaskScheduledEventAttributes{}),
+			createTestEventDecisionTaskStarted(3),
+			createTestEventDecisionTaskCompleted(4, &shared.DecisionTaskCompletedEventAttributes{}),
+			createTestEventActivityTaskScheduled(5, &shared.ActivityTaskScheduledEventAttributes{
+				ActivityId:   common.StringPtr("0"),
+				ActivityType: &shared.ActivityType{Name: common.StringPtr(activityType)},
+				TaskList:     &shared.TaskList{Name: &taskList},
+			}),
+			createTestEventActivityTaskStarted(6, &shared.ActivityTaskStartedEventAttributes{}),
+		}}
+	}
+	histories := []*shared.History{createHistory("testActivity"), createHistory("renamedActivity")}
+
+	replayer := NewWorkflowReplayer(WorkflowReplayerOptions{Logger: getLogger()})
+	report, err := replayer.ReplayWorkflowHistories(&testReplayHistoryIterator{histories: histories})
+	s.NoError(err)
+	s.Len(report.Results, 2)
+	s.NoError(report.Results[0].Err)
+	s.Equal("go.uber.org/cadence/internal.testReplayWorkflow", report.Results[0].WorkflowType)
+
+	failures := report.Failures()
+	s.Len(failures, 1)
+	s.Equal("history-1", failures[0].Name)
+	s.Error(failures[0].Err)
+	s.Equal(int64(5), failures[0].MismatchEventID)
+	s.Contains(failures[0].ExpectedEvent, "renamedActivity")
+	s.Contains(failures[0].ProducedDecision, "testActivity")
+	s.Contains(failures[0].StackTrace, "testReplayWorkflow")
+	s.Contains(report.Err().Error(), "1 of 2 workflow histories failed to replay")
+
+	report, err = replayer.ReplayWorkflowHistoriesFromDirectory("testdata")
+	s.NoError(err)
+	s.NotEmpty(report.Results)
+	s.NoError(report.Err())
+}
+
+type testReplayHistoryIterator struct {
+	histories []*shared.History
+	next      int
+}
+
+func (it *testReplayHistoryIterator) HasNext() bool {
+	return it.next < len(it.histories)
+}
+
+func (it *testReplayHistoryIterator) Next() (string, *shared.History, error) {
+	name := fmt.Sprintf("history-%d", it.next)
+	it.next++
+	return name, it.histories[it.next-1], nil
+}
+
 func (s *internalWorkerTestSuite) testDecisionTaskHandlerHelper(params workerExecutionParameters) {
 	taskList := "taskList1"
 	testEvents := []*shared.HistoryEvent{
@@ -758,6 +818,120 @@ func TestRegisterVariousWorkflowTypes(t *testing.T) {
 	RegisterWorkflow(testWorkflowReturnStructPtrPtr)
 }
 
+func TestWorkerRegistryIsolation(t *testing.T) {
+	globalActivityFn := func() error { return nil }
+	RegisterActivity(globalActivityFn)
+	workerWorkflowFn := func(ctx Context) error { return nil }
+
+	w1 := newWorkerHostEnvironment()
+	w2 := newWorkerHostEnvironment()
+	w3 := newWorkerHostEnvironment()
+	workerActivityFn := func() error { return nil }
+	require.NoError(t, w1.RegisterWorkflowWithOptions(workerWorkflowFn, RegisterWorkflowOptions{Name: "workerWorkflow"}))
+	require.NoError(t, w1.RegisterActivity(workerActivityFn))
+	// registering with a worker does not clash with the global registration.
+	require.NoError(t, w2.RegisterActivity(globalActivityFn))
+
+	_, ok := w1.getWorkflowFn("workerWorkflow")
+	require.True(t, ok)
+	require.Equal(t, []string{"workerWorkflow"}, w1.getRegisteredWorkflowTypes())
+	alias, ok := w1.getWorkflowAlias(getFunctionName(workerWorkflowFn))
+	require.True(t, ok)
+	require.Equal(t, "workerWorkflow", alias)
+	_, ok = w1.getActivity(getFunctionName(workerActivityFn))
+	require.True(t, ok)
+
+	_, ok = w2.getWorkflowFn("workerWorkflow")
+	require.False(t, ok)
+	_, ok = w2.getActivity(getFunctionName(workerActivityFn))
+	require.False(t, ok)
+	_, ok = getHostEnvironment().getActivity(getFunctionName(workerActivityFn))
+	require.False(t, ok)
+
+	// the global registry is only the fallback of workers without registrations of their own.
+	_, ok = w1.getActivity(getFunctionName(globalActivityFn))
+	require.False(t, ok)
+	_, ok = w3.getActivity(getFunctionName(globalActivityFn))
+	require.True(t, ok)
+	require.Error(t, w1.RegisterActivity(workerActivityFn))
+
+	// clients resolve the function to the alias it is registered under by the worker.
+	workflowType, _, err := getValidatedWorkflowFunction(workerWorkflowFn, nil, nil, getHostEnvironment())
+	require.NoError(t, err)
+	require.Equal(t, "workerWorkflow", workflowType.Name)
+
+	// the function is ambiguous once workers register it under different names, except for those workers.
+	require.NoError(t, w2.RegisterWorkflowWithOptions(workerWorkflowFn, RegisterWorkflowOptions{Name: "otherWorkflow"}))
+	_, _, err = getValidatedWorkflowFunction(workerWorkflowFn, nil, nil, getHostEnvironment())
+	require.Error(t, err)
+	workflowType, _, err = getValidatedWorkflowFunction(workerWorkflowFn, nil, nil, w2)
+	require.NoError(t, err)
+	require.Equal(t, "otherWorkflow", workflowType.Name)
+	_, _, err = getValidatedWorkflowFunction("workerWorkflow", nil, nil, getHostEnvironment())
+	require.NoError(t, err)
+}
+
+func TestWorkerRegistryFallback(t *testing.T) {
+	globalWorkflowFn := func(ctx Context) error { return nil }
+	RegisterWorkflowWithOptions(globalWorkflowFn, RegisterWorkflowOptions{Name: "fallbackGlobalWorkflow"})
+	globalActivityFn := func() error { return nil }
+	RegisterActivityWithOptions(globalActivityFn, RegisterActivityOptions{Name: "fallbackGlobalActivity"})
+
+	w := newWorkerHostEnvironment()
+	_, ok := w.getWorkflowFn("fallbackGlobalWorkflow")
+	require.True(t, ok)
+	_, ok = w.getActivity("fallbackGlobalActivity")
+	require.True(t, ok)
+
+	// registering a workflow with the worker hides the global workflows, but not the global activities.
+	workerWorkflowFn := func(ctx Context) error { return nil }
+	require.NoError(t, w.RegisterWorkflowWithOptions(workerWorkflowFn, RegisterWorkflowOptions{Name: "fallbackWorkerWorkflow"}))
+	_, ok = w.getWorkflowFn("fallbackGlobalWorkflow")
+	require.False(t, ok)
+	require.Equal(t, []string{"fallbackWorkerWorkflow"}, w.getRegisteredWorkflowTypes())
+	_, ok = w.getActivity("fallbackGlobalActivity")
+	require.True(t, ok)
+
+	// registering an activity with the worker hides the global activities.
+	workerActivityFn := func() error { return nil }
+	require.NoError(t, w.RegisterActivityWithOptions(workerActivityFn, RegisterActivityOptions{Name: "fallbackWorkerActivity"}))
+	_, ok = w.getActivity("fallbackGlobalActivity")
+	require.False(t, ok)
+	_, ok = w.getActivity("fallbackWorkerActivity")
+	require.True(t, ok)
+}
+
+type testLedgerActivities struct {
+	name string
+}
+
+func (a *testLedgerActivities) Record(amount int) (string, error) {
+	return fmt.Sprintf("%v:%v", a.name, amount), nil
+}
+
+func TestRegisterActivityStructOnce(t *testing.T) {
+	w1 := newWorkerHostEnvironment()
+	require.NoError(t, w1.RegisterActivityWithOptions(&testLedgerActivities{name: "a"}, RegisterActivityOptions{Prefix: "a_"}))
+	require.Error(t, w1.RegisterActivityWithOptions(&testLedgerActivities{name: "b"}, RegisterActivityOptions{Prefix: "b_"}))
+
+	// a method value of any instance resolves to the activity of the registered instance.
+	activityType, _, err := getValidatedActivityFunction((&testLedgerActivities{}).Record, []interface{}{1}, nil, w1)
+	require.NoError(t, err)
+	require.Equal(t, "a_Record", activityType.Name)
+	activityType, _, err = getValidatedActivityFunction((&testLedgerActivities{}).Record, []interface{}{1}, nil, getHostEnvironment())
+	require.NoError(t, err)
+	require.Equal(t, "a_Record", activityType.Name)
+
+	// once workers register the struct type under different prefixes, the method value is ambiguous to the others.
+	w2 := newWorkerHostEnvironment()
+	require.NoError(t, w2.RegisterActivityWithOptions(&testLedgerActivities{name: "b"}, RegisterActivityOptions{Prefix: "b_"}))
+	activityType, _, err = getValidatedActivityFunction((&testLedgerActivities{}).Record, []interface{}{1}, nil, w2)
+	require.NoError(t, err)
+	require.Equal(t, "b_Record", activityType.Name)
+	_, _, err = getValidatedActivityFunction((&testLedgerActivities{}).Record, []interface{}{1}, nil, getHostEnvironment())
+	require.Error(t, err)
+}
+
 type testErrorDetails struct {
 	T string
 }
@@ -959,7 +1133,7 @@ func TestActivityNilArgs(t *testing.T) {
 	}
 
 	args := []interface{}{nil, nil, nil}
-	_, input, err := getValidatedActivityFunction(activityFn, args, nil)
+	_, input, err := getValidatedActivityFunction(activityFn, args, nil, getHostEnvironment())
 	require.NoError(t, err)
 
 	reflectArgs, err := decodeArgs(nil, reflect.TypeOf(activityFn), input)
@@ -979,7 +1153,7 @@ func TestActivityNilArgs_WithDataConverter(t *testing.T) {
 	}
 
 	args := []interface{}{nil, nil, nil}
-	_, _, err := getValidatedActivityFunction(activityFn, args, newTestDataConverter())
+	_, _, err := getValidatedActivityFunction(activityFn, args, newTestDataConverter(), getHostEnvironment())
 	require.Error(t, err) // testDataConverter cannot encode nil value
 }
 
@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -109,6 +110,7 @@ type (
 		dataConverter   encoded.DataConverter // for decode data
 		scope           tally.Scope           // Used to send metrics
 		logger          *zap.Logger
+		dispatcher      *dispatcherImpl // nil if the channel was not created in a workflow coroutine
 	}
 
 	// Single case statement of the Select
@@ -153,6 +155,7 @@ type (
 		executing        bool       // currently running ExecuteUntilAllBlocked. Used to avoid recursive calls to it.
 		mutex            sync.Mutex // used to synchronize executing
 		closed           bool
+		readOnly         bool // set while a query handler runs, operations changing the workflow state panic
 	}
 
 	// The current timeout resolution implementation is in seconds and uses math.Ceil() as the duration. But is
@@ -166,10 +169,32 @@ type (
 		childPolicy                         ChildWorkflowPolicy
 		waitForCancellation                 bool
 		signalChannels                      map[string]Channel
-		queryHandlers                       map[string]func([]byte) ([]byte, error)
+		signalHeaders                       map[string][]*shared.Header // headers of the latest signals sent to signalChannels
+		signalHandlers                      map[string]*channelImpl     // channels of the handlers set with SetSignalHandler
+		queryHandlers                       map[string]*queryHandler
 		workflowIDReusePolicy               WorkflowIDReusePolicy
 		dataConverter                       encoded.DataConverter
 		retryPolicy                         *shared.RetryPolicy
+		cronSchedule                        string
+	}
+
+	// signalHandler runs a handler function set with SetSignalHandler for every signal received on its channel.
+	signalHandler struct {
+		fn         interface{}
+		signalName string
+	}
+
+	// signalPayload is a signal sent to the channel of a handler set with SetSignalHandler, which extracts the header
+	// into the context of the handler. Signal channels returned by GetSignalChannel only carry the input.
+	signalPayload struct {
+		header *shared.Header
+		input  interface{}
+	}
+
+	// unhandledSignalsError fails the decision task which completes a workflow that still has buffered signals when
+	// the worker is configured with UnhandledSignalPolicyFailDecision.
+	unhandledSignalsError struct {
+		signalNames []string
 	}
 
 	executeWorkflowParams struct {
@@ -214,6 +239,12 @@ type (
 		queryType     string
 		dataConverter encoded.DataConverter
 	}
+
+	// workflowEnvironmentInterceptor is the innermost workflow interceptor. It invokes the workflow function and
+	// implements the outbound calls on top of the workflow environment.
+	workflowEnvironmentInterceptor struct {
+		fn interface{}
+	}
 )
 
 const (
@@ -221,12 +252,15 @@ const (
 	workflowResultContextKey      = "workflowResult"
 	coroutinesContextKey          = "coroutines"
 	workflowEnvOptionsContextKey  = "wfEnvOptions"
+	workflowInterceptorContextKey = "workflowInterceptor"
+	cancellationScopeContextKey   = "cancellationScope"
 )
 
 // Assert that structs do indeed implement the interfaces
 var _ Channel = (*channelImpl)(nil)
 var _ Selector = (*selectorImpl)(nil)
 var _ dispatcher = (*dispatcherImpl)(nil)
+var _ WorkflowInterceptor = (*workflowEnvironmentInterceptor)(nil)
 
 var stackBuf [100000]byte
 
@@ -401,7 +435,17 @@ func (d *syncWorkflowDefinition) Execute(env workflowEnvironment, input []byte)
 		state := getState(d.rootCtx)
 		state.yield("yield before executing to setup state")
 
-		r.workflowResult, r.error = d.workflow.Execute(d.rootCtx, input)
+		header, args := decodeHeaderFromInput(input)
+		workflowCtx, err := extractHeaderToWorkflowContext(d.rootCtx, env.GetContextPropagators(), header)
+		if err != nil {
+			r.error = err
+		} else if cronSchedule := getCronSchedule(header); cronSchedule != "" {
+			env.WorkflowInfo().CronSchedule = cronSchedule
+			env.WorkflowInfo().CronLastFailure = getCronHeader(header, cronLastFailureHeaderKey)
+			r.workflowResult, r.error = executeCronWorkflow(workflowCtx, d.workflow, cronSchedule, input, args)
+		} else {
+			r.workflowResult, r.error = d.workflow.Execute(workflowCtx, args)
+		}
 		rpp := getWorkflowResultPointerPointer(ctx)
 		*rpp = r
 	})
@@ -415,10 +459,10 @@ func (d *syncWorkflowDefinition) Execute(env workflowEnvironment, input []byte)
 	})
 
 	getWorkflowEnvironment(d.rootCtx).RegisterSignalHandler(func(name string, result []byte) {
+		header, result := decodeHeaderFromInput(result)
 		eo := getWorkflowEnvOptions(d.rootCtx)
 		// We don't want this code to be blocked ever, using sendAsync().
-		ch := eo.getSignalChannel(d.rootCtx, name).(*channelImpl)
-		ok := ch.SendAsync(result)
+		ok := eo.sendSignal(d.rootCtx, name, header, result)
 		if !ok {
 			panic(fmt.Sprintf("Exceeded channel buffer size for signal: %v", name))
 		}
@@ -426,15 +470,21 @@ func (d *syncWorkflowDefinition) Execute(env workflowEnvironment, input []byte)
 
 	getWorkflowEnvironment(d.rootCtx).RegisterQueryHandler(func(queryType string, queryArgs []byte) ([]byte, error) {
 		eo := getWorkflowEnvOptions(d.rootCtx)
+		if queryType == QueryTypeQueryTypes {
+			return encodeArg(env.GetDataConverter(), eo.getQueryTypes())
+		}
 		handler, ok := eo.queryHandlers[queryType]
 		if !ok {
-			keys := []string{QueryTypeStackTrace}
-			for k := range eo.queryHandlers {
-				keys = append(keys, k)
+			var keys []string
+			for _, t := range eo.getQueryTypes() {
+				keys = append(keys, t.QueryType)
 			}
 			return nil, fmt.Errorf("unknown queryType %v. KnownQueryTypes=%v", queryType, keys)
 		}
-		return handler(queryArgs)
+		// Query handlers must not change the workflow state, the dispatcher rejects such operations until they return.
+		d.dispatcher.readOnly = true
+		defer func() { d.dispatcher.readOnly = false }()
+		return handler.execute(queryArgs)
 	})
 }
 
@@ -481,6 +531,10 @@ func executeDispatcher(ctx Context, dispatcher dispatcher) {
 	if len(us) > 0 {
 		env.GetLogger().Info("Workflow has unhandled signals", zap.Strings("SignalNames", us))
 		env.GetMetricsScope().Counter(metrics.UnhandledSignalsCounter).Inc(1)
+		if env.GetUnhandledSignalPolicy() == UnhandledSignalPolicyFailDecision {
+			env.Complete(nil, &unhandledSignalsError{signalNames: us})
+			return
+		}
 	}
 
 	env.Complete(rp.workflowResult, rp.error)
@@ -617,6 +671,7 @@ func (c *channelImpl) removeSendCallback(callback *sendCallback) {
 }
 
 func (c *channelImpl) Send(ctx Context, v interface{}) {
+	c.dispatcher.panicIfReadOnly("Channel.Send")
 	state := getState(ctx)
 	valueConsumed := false
 	callback := &sendCallback{
@@ -645,6 +700,7 @@ func (c *channelImpl) Send(ctx Context, v interface{}) {
 }
 
 func (c *channelImpl) SendAsync(v interface{}) (ok bool) {
+	c.dispatcher.panicIfReadOnly("Channel.SendAsync")
 	return c.sendAsyncImpl(v, nil)
 }
 
@@ -672,6 +728,7 @@ func (c *channelImpl) sendAsyncImpl(v interface{}, pair *sendCallback) (ok bool)
 }
 
 func (c *channelImpl) Close() {
+	c.dispatcher.panicIfReadOnly("Channel.Close")
 	c.closed = true
 	for _, callback := range c.blockedReceives {
 		callback.fn(nil, false)
@@ -856,6 +913,27 @@ func (d *dispatcherImpl) ExecuteUntilAllBlocked() (err *PanicError) {
 	return nil
 }
 
+// panicIfReadOnly panics if a query handler is running. Query handlers must not change the state of the workflow.
+func (d *dispatcherImpl) panicIfReadOnly(operation string) {
+	if d != nil && d.readOnly {
+		panic(fmt.Sprintf("%v is not allowed in a query handler", operation))
+	}
+}
+
+// getDispatcher returns the dispatcher which runs the coroutine of ctx, nil if ctx is not a workflow coroutine context.
+// Unlike getState it can be used outside of the dispatcher execution.
+func getDispatcher(ctx Context) *dispatcherImpl {
+	if s, ok := ctx.Value(coroutinesContextKey).(*coroutineState); ok {
+		return s.dispatcher
+	}
+	return nil
+}
+
+// panicIfReadOnly panics if a query handler of the workflow ctx belongs to is running.
+func panicIfReadOnly(ctx Context, operation string) {
+	getDispatcher(ctx).panicIfReadOnly(operation)
+}
+
 func (d *dispatcherImpl) IsDone() bool {
 	return len(d.coroutines) == 0
 }
@@ -1036,7 +1114,7 @@ func newWorkflowDefinition(workflow workflow) workflowDefinition {
 	return &syncWorkflowDefinition{workflow: workflow}
 }
 
-func getValidatedWorkflowFunction(workflowFunc interface{}, args []interface{}, dataConverter encoded.DataConverter) (*WorkflowType, []byte, error) {
+func getValidatedWorkflowFunction(workflowFunc interface{}, args []interface{}, dataConverter encoded.DataConverter, registry *hostEnvImpl) (*WorkflowType, []byte, error) {
 	fnName := ""
 	fType := reflect.TypeOf(workflowFunc)
 	switch getKind(fType) {
@@ -1048,7 +1126,10 @@ func getValidatedWorkflowFunction(workflowFunc interface{}, args []interface{},
 			return nil, nil, err
 		}
 		fnName = getFunctionName(workflowFunc)
-		if alias, ok := getHostEnvironment().getWorkflowAlias(fnName); ok {
+		if err := registry.checkWorkflowAlias(fnName); err != nil {
+			return nil, nil, err
+		}
+		if alias, ok := registry.getWorkflowAlias(fnName); ok {
 			fnName = alias
 		}
 
@@ -1095,6 +1176,11 @@ func getValidatedWorkflowOptions(ctx Context) (*workflowOptions, error) {
 	if err := validateRetryPolicy(p.retryPolicy); err != nil {
 		return nil, err
 	}
+	if p.cronSchedule != "" {
+		if _, err := parseCronSchedule(p.cronSchedule); err != nil {
+			return nil, err
+		}
+	}
 
 	return p, nil
 }
@@ -1114,7 +1200,9 @@ func setWorkflowEnvOptionsIfNotExist(ctx Context) Context {
 		newOptions = *options
 	} else {
 		newOptions.signalChannels = make(map[string]Channel)
-		newOptions.queryHandlers = make(map[string]func([]byte) ([]byte, error))
+		newOptions.signalHeaders = make(map[string][]*shared.Header)
+		newOptions.signalHandlers = make(map[string]*channelImpl)
+		newOptions.queryHandlers = make(map[string]*queryHandler)
 	}
 	if newOptions.dataConverter == nil {
 		newOptions.dataConverter = getDefaultDataConverter()
@@ -1140,6 +1228,21 @@ func (w *workflowOptions) getSignalChannel(ctx Context, signalName string) Chann
 	return ch
 }
 
+// sendSignal delivers a received signal to the handler set for the signal, or to the signal channel if there is none.
+// The header is kept aside for the signals sent to the signal channel in case a handler is set later, the channel
+// buffers at most defaultSignalChannelSize signals so only as many headers are kept.
+func (w *workflowOptions) sendSignal(ctx Context, signalName string, header *shared.Header, input []byte) bool {
+	if handlerCh, ok := w.signalHandlers[signalName]; ok {
+		return handlerCh.SendAsync(&signalPayload{header: header, input: input})
+	}
+	headers := append(w.signalHeaders[signalName], header)
+	if len(headers) > defaultSignalChannelSize {
+		headers = headers[len(headers)-defaultSignalChannelSize:]
+	}
+	w.signalHeaders[signalName] = headers
+	return w.getSignalChannel(ctx, signalName).SendAsync(input)
+}
+
 // getUnhandledSignals checks if there are any signal channels that have data to be consumed.
 func (w *workflowOptions) getUnhandledSignals() []string {
 	unhandledSignals := []string{}
@@ -1151,9 +1254,14 @@ func (w *workflowOptions) getUnhandledSignals() []string {
 			ch.recValue = &v
 		}
 	}
+	sort.Strings(unhandledSignals)
 	return unhandledSignals
 }
 
+func (e *unhandledSignalsError) Error() string {
+	return fmt.Sprintf("workflow completed with unhandled signals: %v", e.signalNames)
+}
+
 func (d *decodeFutureImpl) Get(ctx Context, value interface{}) error {
 	more := d.futureImpl.channel.Receive(ctx, nil)
 	if more {
@@ -1208,14 +1316,133 @@ func setQueryHandler(ctx Context, queryType string, handler interface{}) error {
 		return err
 	}
 
-	getWorkflowEnvOptions(ctx).queryHandlers[queryType] = qh.execute
+	getWorkflowEnvOptions(ctx).queryHandlers[queryType] = qh
 	return nil
 }
 
+func removeQueryHandler(ctx Context, queryType string) {
+	delete(getWorkflowEnvOptions(ctx).queryHandlers, queryType)
+}
+
+// getQueryTypes lists the built-in query types and the query handlers set with SetQueryHandler ordered by query type.
+func (w *workflowOptions) getQueryTypes() []QueryTypeInfo {
+	queryTypes := []QueryTypeInfo{
+		{QueryType: QueryTypeQueryTypes, ArgTypes: []string{}},
+		{QueryType: QueryTypeStackTrace, ArgTypes: []string{}},
+	}
+	for _, h := range w.queryHandlers {
+		queryTypes = append(queryTypes, QueryTypeInfo{QueryType: h.queryType, ArgTypes: h.argTypes()})
+	}
+	sort.Slice(queryTypes, func(i, j int) bool {
+		return queryTypes[i].QueryType < queryTypes[j].QueryType
+	})
+	return queryTypes
+}
+
+func setSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	sh := &signalHandler{fn: handler, signalName: signalName}
+	err := sh.validateHandlerFn()
+	if err != nil {
+		return err
+	}
+
+	eo := getWorkflowEnvOptions(ctx)
+	if _, ok := eo.signalHandlers[signalName]; ok {
+		return fmt.Errorf("signal handler for %v is already set", signalName)
+	}
+	ch := eo.getSignalChannel(ctx, signalName).(*channelImpl)
+	handlerCh := NewBufferedChannel(ctx, defaultSignalChannelSize).(*channelImpl)
+	// Signals received before the handler was set are still buffered in the signal channel, they are moved to the
+	// handler to be handled first and in order. The buffered signals are the latest ones sent to the channel, so their
+	// headers are the latest ones kept aside.
+	var buffered []interface{}
+	for {
+		v, ok, _ := ch.receiveAsyncImpl(nil)
+		if !ok {
+			break
+		}
+		buffered = append(buffered, v)
+	}
+	headers := eo.signalHeaders[signalName]
+	delete(eo.signalHeaders, signalName)
+	for i, v := range buffered {
+		var header *shared.Header
+		if j := len(headers) - len(buffered) + i; j >= 0 {
+			header = headers[j]
+		}
+		handlerCh.SendAsync(&signalPayload{header: header, input: v})
+	}
+	eo.signalHandlers[signalName] = handlerCh
+
+	propagators := getWorkflowEnvironment(ctx).GetContextPropagators()
+	GoNamed(ctx, fmt.Sprintf("signal-handler-%s", signalName), func(ctx Context) {
+		for {
+			var payload *signalPayload
+			if more := handlerCh.Receive(ctx, &payload); !more {
+				return
+			}
+			argPtr := sh.newArgPtr()
+			if err := ch.assignValue(payload.input, argPtr); err != nil {
+				continue // corrupt signal, dropped
+			}
+			handlerCtx, err := extractHeaderToWorkflowContext(ctx, propagators, payload.header)
+			if err != nil {
+				getWorkflowEnvironment(ctx).GetLogger().Error("Unable to extract header of signal, dropping it.",
+					zap.String("SignalName", signalName), zap.Error(err))
+				continue
+			}
+			GoNamed(handlerCtx, fmt.Sprintf("signal-%s", signalName), func(ctx Context) {
+				sh.execute(ctx, argPtr)
+			})
+		}
+	})
+	return nil
+}
+
+func (h *signalHandler) validateHandlerFn() error {
+	fnType := reflect.TypeOf(h.fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("signal handler must be function but was %v", fnType)
+	}
+	if fnType.NumIn() < 1 || fnType.NumIn() > 2 || !isWorkflowContext(fnType.In(0)) {
+		return errors.New("signal handler must accept workflow.Context and at most one signal argument")
+	}
+	if fnType.NumIn() == 2 && !isValidResultType(fnType.In(1)) {
+		return fmt.Errorf("signal argument of signal handler must be serializable but found: %v", fnType.In(1).Kind())
+	}
+	if fnType.NumOut() != 0 {
+		return fmt.Errorf("signal handler must not return any value, but found %d return values", fnType.NumOut())
+	}
+	return nil
+}
+
+// newArgPtr returns a pointer to decode the signal argument into, nil if the handler does not accept one.
+func (h *signalHandler) newArgPtr() interface{} {
+	fnType := reflect.TypeOf(h.fn)
+	if fnType.NumIn() < 2 {
+		return nil
+	}
+	return reflect.New(fnType.In(1)).Interface()
+}
+
+func (h *signalHandler) execute(ctx Context, argPtr interface{}) {
+	args := []reflect.Value{reflect.ValueOf(ctx)}
+	if argPtr != nil {
+		args = append(args, reflect.ValueOf(argPtr).Elem())
+	}
+	reflect.ValueOf(h.fn).Call(args)
+}
+
 func (h *queryHandler) validateHandlerFn() error {
 	fnType := reflect.TypeOf(h.fn)
-	if fnType.Kind() != reflect.Func {
-		return fmt.Errorf("query handler must be function but was %s", fnType.Kind())
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("query handler must be function but was %v", fnType)
+	}
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		if isWorkflowContext(fnType.In(i)) || !isValidResultType(fnType.In(i)) {
+			return fmt.Errorf("argument %d of query handler must be serializable but found: %v", i, fnType.In(i))
+		}
 	}
 
 	if fnType.NumOut() != 2 {
@@ -1237,6 +1464,16 @@ func (h *queryHandler) validateHandlerFn() error {
 	return nil
 }
 
+// argTypes returns the names of the argument types of the query handler.
+func (h *queryHandler) argTypes() []string {
+	fnType := reflect.TypeOf(h.fn)
+	argTypes := []string{}
+	for i := 0; i < fnType.NumIn(); i++ {
+		argTypes = append(argTypes, fnType.In(i).String())
+	}
+	return argTypes
+}
+
 func (h *queryHandler) execute(input []byte) (result []byte, err error) {
 	// if query handler panic, convert it to error
 	defer func() {
@@ -1261,7 +1498,8 @@ func (h *queryHandler) execute(input []byte) (result []byte, err error) {
 	} else {
 		decoded, err := decodeArgs(h.dataConverter, fnType, input)
 		if err != nil {
-			return nil, fmt.Errorf("unable to decode the input for queryType: %v, with error: %v", h.queryType, err)
+			return nil, fmt.Errorf("unable to decode the input for queryType: %v, expected arguments: %v, with error: %v",
+				h.queryType, h.argTypes(), err)
 		}
 		args = append(args, decoded...)
 	}
@@ -24,6 +24,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"time"
 
@@ -41,20 +42,35 @@ import (
 // Assert that structs do indeed implement the interfaces
 var _ Client = (*workflowClient)(nil)
 var _ DomainClient = (*domainClient)(nil)
+var _ ClientOutboundInterceptor = (*workflowClientInterceptor)(nil)
 
 const (
 	defaultDecisionTaskTimeoutInSecs = 10
 	defaultGetHistoryTimeoutInSecs   = 25
+
+	// pendingDecisionTimeout bounds the wait of a strongly consistent query for the pending decision task.
+	pendingDecisionTimeout = 30 * time.Second
 )
 
+var startWorkflowRetryPolicy = createStartWorkflowRetryPolicy()
+
 type (
 	// workflowClient is the client for starting a workflow execution.
 	workflowClient struct {
-		workflowService workflowserviceclient.Interface
-		domain          string
-		metricsScope    *metrics.TaggedScope
-		identity        string
-		dataConverter   encoded.DataConverter
+		workflowService    workflowserviceclient.Interface
+		domain             string
+		metricsScope       *metrics.TaggedScope
+		identity           string
+		dataConverter      encoded.DataConverter
+		contextPropagators []ContextPropagator
+		interceptor        ClientOutboundInterceptor
+		// enableHeaderEnvelope is ClientOptions.EnableHeaderEnvelope, the cron schedule requires the envelope.
+		enableHeaderEnvelope bool
+	}
+
+	// workflowClientInterceptor is the innermost client interceptor, it sends the requests to the cadence service.
+	workflowClientInterceptor struct {
+		*workflowClient
 	}
 
 	// domainClient is the client for managing domains.
@@ -77,6 +93,29 @@ type (
 		// error. This is a blocking API.
 		Get(ctx context.Context, valuePtr interface{}) error
 
+		// GetWithOptions is Get with options, see WorkflowRunGetOptions.
+		GetWithOptions(ctx context.Context, valuePtr interface{}, options WorkflowRunGetOptions) error
+
+		// Signal sends a signal to the run, see Client.SignalWorkflow.
+		Signal(ctx context.Context, signalName string, arg interface{}) error
+
+		// Cancel requests the cancellation of the run, see Client.CancelWorkflow.
+		Cancel(ctx context.Context) error
+
+		// Terminate terminates the run, see Client.TerminateWorkflow.
+		Terminate(ctx context.Context, reason string, details []byte) error
+
+		// Query queries the run, see Client.QueryWorkflow.
+		Query(ctx context.Context, queryType string, args ...interface{}) (encoded.Value, error)
+
+		// Describe returns the description of the run, see Client.DescribeWorkflow.
+		Describe(ctx context.Context) (*WorkflowExecutionDescription, error)
+
+		// History returns an iterator over all the events of the run, see Client.GetWorkflowHistory.
+		History(ctx context.Context) HistoryEventIterator
+
+		// NOTE: the methods above other than Get act on the run with the ID returned by GetRunID(), which is the
+		// current run of the workflow if GetRunID() is empty.
 		// NOTE: if the started workflow return ContinueAsNewError during the workflow execution, the
 		// return result of GetRunID() will be the started workflow run ID, not the new run ID caused by ContinueAsNewError,
 		// however, Get(ctx context.Context, valuePtr interface{}) will return result from the run which did not return ContinueAsNewError.
@@ -86,8 +125,17 @@ type (
 		// NOTE: DO NOT USE client.ExecuteWorkflow API INSIDE A WORKFLOW, USE workflow.ExecuteChildWorkflow instead
 	}
 
+	// WorkflowRunGetOptions are the options of WorkflowRun.GetWithOptions.
+	WorkflowRunGetOptions struct {
+		// DisableFollowingRuns - Returns the result of the first run instead of following the runs started by
+		// continue as new. If the first run continued as new, a *WorkflowContinuedAsNewError is returned.
+		// Optional: defaulted to false.
+		DisableFollowingRuns bool
+	}
+
 	// workflowRunImpl is an implementation of WorkflowRun
 	workflowRunImpl struct {
+		client        *workflowClient
 		workflowFn    interface{}
 		workflowID    string
 		firstRunID    string
@@ -109,6 +157,53 @@ type (
 		Next() (*s.HistoryEvent, error)
 	}
 
+	// WorkflowExecutionIterator represents the interface for
+	// workflow execution iterator
+	WorkflowExecutionIterator interface {
+		// HasNext return whether this iterator has next value
+		HasNext() bool
+		// Next returns the next workflow execution and error
+		// The errors it can return:
+		//	- EntityNotExistsError
+		//	- BadRequestError
+		//	- InternalServiceError
+		//	- the error of the context passed to ListWorkflows once it is done
+		Next() (*WorkflowExecutionInfo, error)
+	}
+
+	// workflowExecutionIteratorImpl is the implementation of WorkflowExecutionIterator
+	workflowExecutionIteratorImpl struct {
+		ctx context.Context
+		// whether this iterator is initialized
+		initialized bool
+		// local cached workflow executions and corresponding comsuming index
+		nextIndex  int
+		executions []*WorkflowExecutionInfo
+		// token to get next page of workflow executions
+		nexttoken []byte
+		// err when getting next page of workflow executions
+		err error
+		// func which use a next token to get next page of workflow executions
+		paginate func(nexttoken []byte) ([]*s.WorkflowExecutionInfo, []byte, error)
+		// func which decides whether a workflow execution is returned by the iterator
+		filter func(info *WorkflowExecutionInfo) bool
+	}
+
+	// domainIteratorImpl is the implementation of DomainIterator
+	domainIteratorImpl struct {
+		// whether this iterator is initialized
+		initialized bool
+		// local cached domains and corresponding comsuming index
+		nextIndex int
+		domains   []*s.DescribeDomainResponse
+		// token to get next page of domains
+		nexttoken []byte
+		// err when getting next page of domains
+		err error
+		// func which use a next token to get next page of domains
+		paginate func(nexttoken []byte) (*s.ListDomainsResponse, error)
+	}
+
 	// historyEventIteratorImpl is the implementation of HistoryEventIterator
 	historyEventIteratorImpl struct {
 		// whether this iterator is initialized
@@ -125,6 +220,13 @@ type (
 	}
 )
 
+func (wc *workflowClient) getInterceptor() ClientOutboundInterceptor {
+	if wc.interceptor == nil {
+		return &workflowClientInterceptor{workflowClient: wc}
+	}
+	return wc.interceptor
+}
+
 // StartWorkflow starts a workflow execution
 // The user can use this to start using a functor like.
 // Either by
@@ -138,6 +240,15 @@ func (wc *workflowClient) StartWorkflow(
 	options StartWorkflowOptions,
 	workflowFunc interface{},
 	args ...interface{},
+) (*WorkflowExecution, error) {
+	return wc.getInterceptor().StartWorkflow(ctx, options, workflowFunc, args...)
+}
+
+func (wc *workflowClientInterceptor) StartWorkflow(
+	ctx context.Context,
+	options StartWorkflowOptions,
+	workflowFunc interface{},
+	args ...interface{},
 ) (*WorkflowExecution, error) {
 	workflowID := options.ID
 	if len(workflowID) == 0 {
@@ -162,14 +273,36 @@ func (wc *workflowClient) StartWorkflow(
 	}
 
 	// Validate type and its arguments.
-	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, args, wc.dataConverter)
+	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, args, wc.dataConverter, getHostEnvironment())
+	if err != nil {
+		return nil, err
+	}
+
+	input, err = injectHeaderFromContext(ctx, wc.contextPropagators, input)
 	if err != nil {
 		return nil, err
 	}
 
+	if options.CronSchedule != "" {
+		if !wc.enableHeaderEnvelope {
+			return nil, errors.New("CronSchedule requires ClientOptions.EnableHeaderEnvelope")
+		}
+		if _, err := parseCronSchedule(options.CronSchedule); err != nil {
+			return nil, err
+		}
+		if input, err = withCronSchedule(input, options.CronSchedule); err != nil {
+			return nil, err
+		}
+	}
+
+	requestID := options.RequestID
+	if requestID == "" {
+		requestID = uuid.New()
+	}
+
 	startRequest := &s.StartWorkflowExecutionRequest{
 		Domain:       common.StringPtr(wc.domain),
-		RequestId:    common.StringPtr(uuid.New()),
+		RequestId:    common.StringPtr(requestID),
 		WorkflowId:   common.StringPtr(workflowID),
 		WorkflowType: workflowTypePtr(*workflowType),
 		TaskList:     common.TaskListPtr(s.TaskList{Name: common.StringPtr(options.TaskList)}),
@@ -183,6 +316,11 @@ func (wc *workflowClient) StartWorkflow(
 
 	var response *s.StartWorkflowExecutionResponse
 
+	retryPolicy := serviceOperationRetryPolicy
+	if options.RetryStart {
+		retryPolicy = startWorkflowRetryPolicy
+	}
+
 	// Start creating workflow request.
 	err = backoff.Retry(ctx,
 		func() error {
@@ -192,10 +330,16 @@ func (wc *workflowClient) StartWorkflow(
 			var err1 error
 			response, err1 = wc.workflowService.StartWorkflowExecution(tchCtx, startRequest, opt...)
 			return err1
-		}, serviceOperationRetryPolicy, isServiceTransientError)
+		}, retryPolicy, isServiceTransientError)
 
 	if err != nil {
-		return nil, err
+		// an attempt which timed out may have started the workflow, the following attempts then fail as the
+		// workflow is already started by this request
+		alreadyStartedErr, ok := err.(*s.WorkflowExecutionAlreadyStartedError)
+		if !ok || alreadyStartedErr.GetStartRequestId() != requestID {
+			return nil, err
+		}
+		response = &s.StartWorkflowExecutionResponse{RunId: alreadyStartedErr.RunId}
 	}
 
 	if wc.metricsScope != nil {
@@ -209,6 +353,15 @@ func (wc *workflowClient) StartWorkflow(
 	return executionInfo, nil
 }
 
+// createStartWorkflowRetryPolicy creates the retry policy of StartWorkflowOptions.RetryStart, which retries until the
+// context is done.
+func createStartWorkflowRetryPolicy() backoff.RetryPolicy {
+	policy := backoff.NewExponentialRetryPolicy(retryServiceOperationInitialInterval)
+	policy.SetMaximumInterval(retryServiceOperationMaxInterval)
+	policy.SetExpirationInterval(backoff.NoInterval)
+	return policy
+}
+
 // ExecuteWorkflow starts a workflow execution and wait until this workflow reaches the end state, such as
 // workflow finished successfully or timeout.
 // The user can use this to start using a functor like below and get the workflow execution result, as encoded.Value
@@ -220,6 +373,10 @@ func (wc *workflowClient) StartWorkflow(
 // subjected to change in the future.
 // NOTE: the context.Context should have a fairly large timeout, since workflow execution may take a while to be finished
 func (wc *workflowClient) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
+	return wc.getInterceptor().ExecuteWorkflow(ctx, options, workflow, args...)
+}
+
+func (wc *workflowClientInterceptor) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
 
 	// start the workflow execution
 	var runID string
@@ -239,27 +396,48 @@ func (wc *workflowClient) ExecuteWorkflow(ctx context.Context, options StartWork
 		workflowID = executionInfo.ID
 	}
 
+	return wc.newWorkflowRun(workflow, workflowID, runID), nil
+}
+
+// GetWorkflow returns the WorkflowRun of an existing workflow execution. The workflow execution is not checked, the
+// methods of the WorkflowRun return its errors instead.
+// - runID can be default(empty string). if empty string then the WorkflowRun acts on the current run of the workflow.
+func (wc *workflowClient) GetWorkflow(ctx context.Context, workflowID string, runID string) WorkflowRun {
+	return wc.newWorkflowRun(nil, workflowID, runID)
+}
+
+func (wc *workflowClient) newWorkflowRun(workflow interface{}, workflowID string, runID string) *workflowRunImpl {
 	iterFn := func(fnCtx context.Context, fnRunID string) HistoryEventIterator {
 		return wc.GetWorkflowHistory(fnCtx, workflowID, fnRunID, true, s.HistoryEventFilterTypeCloseEvent)
 	}
 
 	return &workflowRunImpl{
+		client:        wc,
 		workflowFn:    workflow,
 		workflowID:    workflowID,
 		firstRunID:    runID,
 		currentRunID:  runID,
 		iterFn:        iterFn,
 		dataConverter: wc.dataConverter,
-	}, nil
+	}
 }
 
 // SignalWorkflow signals a workflow in execution.
 func (wc *workflowClient) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	return wc.getInterceptor().SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (wc *workflowClientInterceptor) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
 	input, err := encodeArg(wc.dataConverter, arg)
 	if err != nil {
 		return err
 	}
 
+	input, err = injectHeaderFromContext(ctx, wc.contextPropagators, input)
+	if err != nil {
+		return err
+	}
+
 	request := &s.SignalWorkflowExecutionRequest{
 		Domain: common.StringPtr(wc.domain),
 		WorkflowExecution: &s.WorkflowExecution{
@@ -281,8 +459,38 @@ func (wc *workflowClient) SignalWorkflow(ctx context.Context, workflowID string,
 
 // SignalWithStartWorkflow sends a signal to a running workflow.
 // If the workflow is not running or not found, it starts the workflow and then sends the signal in transaction.
+// options.WorkflowIDReusePolicy is ignored, WorkflowIDReusePolicyAllowDuplicate is used instead.
 func (wc *workflowClient) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
 	options StartWorkflowOptions, workflowFunc interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error) {
+	options.WorkflowIDReusePolicy = WorkflowIDReusePolicyAllowDuplicate
+	return wc.getInterceptor().SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflowFunc, workflowArgs...)
+}
+
+// SignalWithStartWorkflowRun sends a signal to a running workflow, or starts the workflow and sends the signal to it
+// if it is not running, and returns the WorkflowRun of the signaled execution.
+// Unlike SignalWithStartWorkflow, options.WorkflowIDReusePolicy is used and options.ID must be empty or match
+// workflowID.
+func (wc *workflowClient) SignalWithStartWorkflowRun(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflowFunc interface{}, workflowArgs ...interface{}) (WorkflowRun, error) {
+	if workflowID == "" {
+		workflowID = options.ID
+	} else if options.ID != "" && options.ID != workflowID {
+		return nil, fmt.Errorf("options.ID %v does not match workflowID %v", options.ID, workflowID)
+	}
+	if workflowID == "" {
+		workflowID = uuid.NewRandom().String()
+	}
+	options.ID = workflowID
+
+	execution, err := wc.getInterceptor().SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflowFunc, workflowArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return wc.newWorkflowRun(workflowFunc, execution.ID, execution.RunID), nil
+}
+
+func (wc *workflowClientInterceptor) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflowFunc interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error) {
 
 	signalInput, err := encodeArg(wc.dataConverter, signalArg)
 	if err != nil {
@@ -311,14 +519,41 @@ func (wc *workflowClient) SignalWithStartWorkflow(ctx context.Context, workflowI
 	}
 
 	// Validate type and its arguments.
-	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, workflowArgs, wc.dataConverter)
+	workflowType, input, err := getValidatedWorkflowFunction(workflowFunc, workflowArgs, wc.dataConverter, getHostEnvironment())
+	if err != nil {
+		return nil, err
+	}
+
+	input, err = injectHeaderFromContext(ctx, wc.contextPropagators, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.CronSchedule != "" {
+		if !wc.enableHeaderEnvelope {
+			return nil, errors.New("CronSchedule requires ClientOptions.EnableHeaderEnvelope")
+		}
+		if _, err := parseCronSchedule(options.CronSchedule); err != nil {
+			return nil, err
+		}
+		if input, err = withCronSchedule(input, options.CronSchedule); err != nil {
+			return nil, err
+		}
+	}
+
+	signalInput, err = injectHeaderFromContext(ctx, wc.contextPropagators, signalInput)
 	if err != nil {
 		return nil, err
 	}
 
+	requestID := options.RequestID
+	if requestID == "" {
+		requestID = uuid.New()
+	}
+
 	signalWithStartRequest := &s.SignalWithStartWorkflowExecutionRequest{
 		Domain:       common.StringPtr(wc.domain),
-		RequestId:    common.StringPtr(uuid.New()),
+		RequestId:    common.StringPtr(requestID),
 		WorkflowId:   common.StringPtr(workflowID),
 		WorkflowType: workflowTypePtr(*workflowType),
 		TaskList:     common.TaskListPtr(s.TaskList{Name: common.StringPtr(options.TaskList)}),
@@ -328,6 +563,7 @@ func (wc *workflowClient) SignalWithStartWorkflow(ctx context.Context, workflowI
 		SignalName:                          common.StringPtr(signalName),
 		SignalInput:                         signalInput,
 		Identity:                            common.StringPtr(wc.identity),
+		WorkflowIdReusePolicy:               options.WorkflowIDReusePolicy.toThriftPtr(),
 		RetryPolicy:                         convertRetryPolicy(options.RetryPolicy),
 	}
 
@@ -354,7 +590,7 @@ func (wc *workflowClient) SignalWithStartWorkflow(ctx context.Context, workflowI
 	}
 
 	executionInfo := &WorkflowExecution{
-		ID:    options.ID,
+		ID:    workflowID,
 		RunID: response.GetRunId()}
 	return executionInfo, nil
 }
@@ -363,6 +599,10 @@ func (wc *workflowClient) SignalWithStartWorkflow(ctx context.Context, workflowI
 // workflowID is required, other parameters are optional.
 // If runID is omit, it will terminate currently running workflow (if there is one) based on the workflowID.
 func (wc *workflowClient) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
+	return wc.getInterceptor().CancelWorkflow(ctx, workflowID, runID)
+}
+
+func (wc *workflowClientInterceptor) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
 	request := &s.RequestCancelWorkflowExecutionRequest{
 		Domain: common.StringPtr(wc.domain),
 		WorkflowExecution: &s.WorkflowExecution{
@@ -384,6 +624,10 @@ func (wc *workflowClient) CancelWorkflow(ctx context.Context, workflowID string,
 // workflowID is required, other parameters are optional.
 // If runID is omit, it will terminate currently running workflow (if there is one) based on the workflowID.
 func (wc *workflowClient) TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error {
+	return wc.getInterceptor().TerminateWorkflow(ctx, workflowID, runID, reason, details)
+}
+
+func (wc *workflowClientInterceptor) TerminateWorkflow(ctx context.Context, workflowID string, runID string, reason string, details []byte) error {
 	request := &s.TerminateWorkflowExecutionRequest{
 		Domain: common.StringPtr(wc.domain),
 		WorkflowExecution: &s.WorkflowExecution{
@@ -461,6 +705,10 @@ func (wc *workflowClient) GetWorkflowHistory(ctx context.Context, workflowID str
 // completed event will be reported; if err is CanceledError, activity task cancelled event will be reported; otherwise,
 // activity task failed event will be reported.
 func (wc *workflowClient) CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error {
+	return wc.getInterceptor().CompleteActivity(ctx, taskToken, result, err)
+}
+
+func (wc *workflowClientInterceptor) CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error {
 	if taskToken == nil {
 		return errors.New("invalid task token provided")
 	}
@@ -481,6 +729,11 @@ func (wc *workflowClient) CompleteActivity(ctx context.Context, taskToken []byte
 // It takes domain name, workflowID, runID, activityID as arguments.
 func (wc *workflowClient) CompleteActivityByID(ctx context.Context, domain, workflowID, runID, activityID string,
 	result interface{}, err error) error {
+	return wc.getInterceptor().CompleteActivityByID(ctx, domain, workflowID, runID, activityID, result, err)
+}
+
+func (wc *workflowClientInterceptor) CompleteActivityByID(ctx context.Context, domain, workflowID, runID, activityID string,
+	result interface{}, err error) error {
 
 	if activityID == "" || workflowID == "" || domain == "" {
 		return errors.New("empty activity or workflow id or domainName")
@@ -501,6 +754,10 @@ func (wc *workflowClient) CompleteActivityByID(ctx context.Context, domain, work
 
 // RecordActivityHeartbeat records heartbeat for an activity.
 func (wc *workflowClient) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
+	return wc.getInterceptor().RecordActivityHeartbeat(ctx, taskToken, details...)
+}
+
+func (wc *workflowClientInterceptor) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
 	data, err := encodeArgs(wc.dataConverter, details)
 	if err != nil {
 		return err
@@ -510,6 +767,11 @@ func (wc *workflowClient) RecordActivityHeartbeat(ctx context.Context, taskToken
 
 // RecordActivityHeartbeatByID records heartbeat for an activity.
 func (wc *workflowClient) RecordActivityHeartbeatByID(ctx context.Context,
+	domain, workflowID, runID, activityID string, details ...interface{}) error {
+	return wc.getInterceptor().RecordActivityHeartbeatByID(ctx, domain, workflowID, runID, activityID, details...)
+}
+
+func (wc *workflowClientInterceptor) RecordActivityHeartbeatByID(ctx context.Context,
 	domain, workflowID, runID, activityID string, details ...interface{}) error {
 	data, err := encodeArgs(wc.dataConverter, details)
 	if err != nil {
@@ -566,6 +828,88 @@ func (wc *workflowClient) ListOpenWorkflow(ctx context.Context, request *s.ListO
 	return response, nil
 }
 
+// ListWorkflows lists the open or closed workflow executions matching the filter.
+// The service filters by at most one of workflow ID, workflow type and close status, the remaining parts of the
+// filter are applied by the iterator.
+func (wc *workflowClient) ListWorkflows(ctx context.Context, filter ListWorkflowsFilter) WorkflowExecutionIterator {
+	return &workflowExecutionIteratorImpl{
+		ctx:      ctx,
+		paginate: wc.getListWorkflowsPaginateFn(ctx, filter),
+		filter:   filter.matches,
+	}
+}
+
+// getListWorkflowsPaginateFn returns the func which uses a next token to get the next page of workflow executions
+// for the filter.
+func (wc *workflowClient) getListWorkflowsPaginateFn(ctx context.Context, filter ListWorkflowsFilter) func(nexttoken []byte) ([]*s.WorkflowExecutionInfo, []byte, error) {
+	timeFilter := &s.StartTimeFilter{
+		EarliestTime: common.Int64Ptr(0),
+		LatestTime:   common.Int64Ptr(math.MaxInt64),
+	}
+	if !filter.EarliestTime.IsZero() {
+		timeFilter.EarliestTime = common.Int64Ptr(filter.EarliestTime.UnixNano())
+	}
+	if !filter.LatestTime.IsZero() {
+		timeFilter.LatestTime = common.Int64Ptr(filter.LatestTime.UnixNano())
+	}
+
+	var executionFilter *s.WorkflowExecutionFilter
+	var typeFilter *s.WorkflowTypeFilter
+	var statusFilter *s.WorkflowExecutionCloseStatus
+	if filter.WorkflowID != "" {
+		executionFilter = &s.WorkflowExecutionFilter{WorkflowId: common.StringPtr(filter.WorkflowID)}
+	} else if filter.WorkflowType != "" {
+		typeFilter = &s.WorkflowTypeFilter{Name: common.StringPtr(filter.WorkflowType)}
+	} else if filter.Closed && len(filter.Statuses) == 1 {
+		statusFilter = filter.Statuses[0].toThriftPtr()
+	}
+
+	return func(nexttoken []byte) ([]*s.WorkflowExecutionInfo, []byte, error) {
+		if filter.Closed {
+			response, err := wc.ListClosedWorkflow(ctx, &s.ListClosedWorkflowExecutionsRequest{
+				NextPageToken:   nexttoken,
+				StartTimeFilter: timeFilter,
+				ExecutionFilter: executionFilter,
+				TypeFilter:      typeFilter,
+				StatusFilter:    statusFilter,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return response.Executions, response.NextPageToken, nil
+		}
+		response, err := wc.ListOpenWorkflow(ctx, &s.ListOpenWorkflowExecutionsRequest{
+			NextPageToken:   nexttoken,
+			StartTimeFilter: timeFilter,
+			ExecutionFilter: executionFilter,
+			TypeFilter:      typeFilter,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return response.Executions, response.NextPageToken, nil
+	}
+}
+
+// matches returns whether the workflow execution is selected by the filter.
+func (filter ListWorkflowsFilter) matches(info *WorkflowExecutionInfo) bool {
+	if filter.WorkflowID != "" && info.Execution.ID != filter.WorkflowID {
+		return false
+	}
+	if filter.WorkflowType != "" && info.WorkflowType.Name != filter.WorkflowType {
+		return false
+	}
+	if !filter.Closed || len(filter.Statuses) == 0 {
+		return true
+	}
+	for _, status := range filter.Statuses {
+		if info.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
 // DescribeWorkflowExecution returns information about the specified workflow execution.
 // The errors it can return:
 //  - BadRequestError
@@ -594,6 +938,95 @@ func (wc *workflowClient) DescribeWorkflowExecution(ctx context.Context, workflo
 	return response, nil
 }
 
+// DescribeWorkflow returns the description of the specified workflow execution with typed fields.
+// The errors it can return:
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+func (wc *workflowClient) DescribeWorkflow(ctx context.Context, workflowID, runID string) (*WorkflowExecutionDescription, error) {
+	return wc.DescribeWorkflowWithOptions(ctx, workflowID, runID, DescribeWorkflowOptions{})
+}
+
+// DescribeWorkflowWithOptions returns the description of the specified workflow execution with typed fields. With
+// options.ReadHistory the pending child workflows are read from the history of the execution while it is running.
+// The errors it can return:
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+func (wc *workflowClient) DescribeWorkflowWithOptions(ctx context.Context, workflowID, runID string, options DescribeWorkflowOptions) (*WorkflowExecutionDescription, error) {
+	response, err := wc.DescribeWorkflowExecution(ctx, workflowID, runID)
+	if err != nil {
+		return nil, err
+	}
+	description := convertWorkflowExecutionDescription(response, wc.dataConverter)
+	if !options.ReadHistory || !description.IsRunning() {
+		return description, nil
+	}
+
+	events, err := wc.getHistoryEvents(ctx, workflowID, description.Execution.RunID)
+	if err != nil {
+		return nil, err
+	}
+	setPendingFromHistory(description, events)
+	return description, nil
+}
+
+// setPendingFromHistory sets the pending child workflows of the description, which the Cadence service does not
+// describe.
+func setPendingFromHistory(description *WorkflowExecutionDescription, events []*s.HistoryEvent) {
+	var childEventIDs []int64
+	children := make(map[int64]*PendingChildWorkflowInfo)
+	for _, event := range events {
+		switch event.GetEventType() {
+		case s.EventTypeStartChildWorkflowExecutionInitiated:
+			attributes := event.StartChildWorkflowExecutionInitiatedEventAttributes
+			childEventIDs = append(childEventIDs, event.GetEventId())
+			children[event.GetEventId()] = &PendingChildWorkflowInfo{
+				WorkflowID:       attributes.GetWorkflowId(),
+				WorkflowType:     WorkflowType{Name: attributes.WorkflowType.GetName()},
+				InitiatedEventID: event.GetEventId(),
+			}
+		case s.EventTypeChildWorkflowExecutionStarted:
+			attributes := event.ChildWorkflowExecutionStartedEventAttributes
+			if child, ok := children[attributes.GetInitiatedEventId()]; ok {
+				child.RunID = attributes.WorkflowExecution.GetRunId()
+			}
+		case s.EventTypeStartChildWorkflowExecutionFailed:
+			delete(children, event.StartChildWorkflowExecutionFailedEventAttributes.GetInitiatedEventId())
+		case s.EventTypeChildWorkflowExecutionCompleted:
+			delete(children, event.ChildWorkflowExecutionCompletedEventAttributes.GetInitiatedEventId())
+		case s.EventTypeChildWorkflowExecutionFailed:
+			delete(children, event.ChildWorkflowExecutionFailedEventAttributes.GetInitiatedEventId())
+		case s.EventTypeChildWorkflowExecutionCanceled:
+			delete(children, event.ChildWorkflowExecutionCanceledEventAttributes.GetInitiatedEventId())
+		case s.EventTypeChildWorkflowExecutionTimedOut:
+			delete(children, event.ChildWorkflowExecutionTimedOutEventAttributes.GetInitiatedEventId())
+		case s.EventTypeChildWorkflowExecutionTerminated:
+			delete(children, event.ChildWorkflowExecutionTerminatedEventAttributes.GetInitiatedEventId())
+		}
+	}
+
+	for _, eventID := range childEventIDs {
+		if child, ok := children[eventID]; ok {
+			description.PendingChildren = append(description.PendingChildren, *child)
+		}
+	}
+}
+
+// getHistoryEvents reads the full history of the workflow execution.
+func (wc *workflowClient) getHistoryEvents(ctx context.Context, workflowID string, runID string) ([]*s.HistoryEvent, error) {
+	var events []*s.HistoryEvent
+	iter := wc.GetWorkflowHistory(ctx, workflowID, runID, false, s.HistoryEventFilterTypeAllEvent)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
 // QueryWorkflow queries a given workflow execution
 // workflowID and queryType are required, other parameters are optional.
 // - workflow ID of the workflow.
@@ -607,6 +1040,15 @@ func (wc *workflowClient) DescribeWorkflowExecution(ctx context.Context, workflo
 //  - EntityNotExistError
 //  - QueryFailError
 func (wc *workflowClient) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error) {
+	value, err := wc.getInterceptor().QueryWorkflow(ctx, workflowID, runID, queryType, args...)
+	if queryErr, ok := err.(*s.QueryFailedError); ok {
+		// drop the failure details appended to the message by the worker
+		return nil, &s.QueryFailedError{Message: decodeQueryFailure(queryErr.Message, wc.dataConverter).Error()}
+	}
+	return value, err
+}
+
+func (wc *workflowClientInterceptor) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error) {
 	var input []byte
 	if len(args) > 0 {
 		var err error
@@ -642,6 +1084,86 @@ func (wc *workflowClient) QueryWorkflow(ctx context.Context, workflowID string,
 	return newEncodedValue(resp.QueryResult, wc.dataConverter), nil
 }
 
+// QueryWorkflowWithOptions queries a given workflow execution with the reject condition and consistency level of the
+// request. The Cadence service runs every query, so the execution is described before the query to check the reject
+// condition and return its status, and the strong consistency level is implemented by waiting until the workflow
+// processed the events recorded when the execution was described.
+// The errors it can return:
+//  - BadRequestError
+//  - InternalServiceError
+//  - EntityNotExistError
+//  - *QueryFailedError
+func (wc *workflowClient) QueryWorkflowWithOptions(ctx context.Context, request *QueryWorkflowWithOptionsRequest) (*QueryWorkflowWithOptionsResponse, error) {
+	if request == nil {
+		return nil, errors.New("missing query request")
+	}
+	describeResponse, err := wc.DescribeWorkflowExecution(ctx, request.WorkflowID, request.RunID)
+	if err != nil {
+		return nil, err
+	}
+	executionInfo := describeResponse.WorkflowExecutionInfo
+	status := convertWorkflowExecutionStatus(executionInfo.CloseStatus)
+	if request.QueryRejectCondition.rejects(status) {
+		return &QueryWorkflowWithOptionsResponse{QueryRejected: &QueryRejected{Status: status}, Status: status}, nil
+	}
+	// query the described run, not a run started since then
+	runID := executionInfo.Execution.GetRunId()
+	if request.QueryConsistencyLevel == QueryConsistencyLevelStrong && status == WorkflowExecutionStatusRunning {
+		if err := wc.waitForPendingDecision(ctx, request.WorkflowID, runID, executionInfo.GetHistoryLength()); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := wc.getInterceptor().QueryWorkflow(ctx, request.WorkflowID, runID, request.QueryType, request.Args...)
+	if err != nil {
+		if queryErr, ok := err.(*s.QueryFailedError); ok {
+			return nil, decodeQueryFailure(queryErr.Message, wc.dataConverter)
+		}
+		return nil, err
+	}
+
+	return &QueryWorkflowWithOptionsResponse{QueryResult: result, Status: status}, nil
+}
+
+// waitForPendingDecision blocks until the workflow has processed the first historyLength events of the execution, that
+// is until no decision task is scheduled after the last completed one among them. The history is long polled, so
+// every event is read once. The wait fails with the context error once ctx is done or pendingDecisionTimeout passed.
+func (wc *workflowClient) waitForPendingDecision(ctx context.Context, workflowID string, runID string, historyLength int64) error {
+	ctx, cancel := context.WithTimeout(ctx, pendingDecisionTimeout)
+	defer cancel()
+
+	pending := false
+	var eventCount int64
+	iter := wc.GetWorkflowHistory(ctx, workflowID, runID, true, s.HistoryEventFilterTypeAllEvent)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		pending = isDecisionPending(pending, event)
+		eventCount++
+		if eventCount >= historyLength && !pending {
+			return nil
+		}
+	}
+	// the execution is closed, there is no decision task to wait for
+	return nil
+}
+
+// isDecisionPending returns whether a decision task is pending after the event, given whether one was pending before.
+func isDecisionPending(pending bool, event *s.HistoryEvent) bool {
+	switch event.GetEventType() {
+	case s.EventTypeDecisionTaskScheduled:
+		return true
+	case s.EventTypeDecisionTaskCompleted, s.EventTypeDecisionTaskFailed, s.EventTypeDecisionTaskTimedOut:
+		return false
+	}
+	return pending
+}
+
 // DescribeTaskList returns information about the target tasklist, right now this API returns the
 // pollers which polled this tasklist in last few minutes.
 // - tasklist name of tasklist
@@ -730,6 +1252,116 @@ func (dc *domainClient) Update(ctx context.Context, request *s.UpdateDomainReque
 		}, serviceOperationRetryPolicy, isServiceTransientError)
 }
 
+// List returns an iterator over all the domains.
+// The errors the iterator can return:
+//	- BadRequestError
+//	- InternalServiceError
+func (dc *domainClient) List(ctx context.Context) DomainIterator {
+	paginate := func(nexttoken []byte) (*s.ListDomainsResponse, error) {
+		request := &s.ListDomainsRequest{
+			NextPageToken: nexttoken,
+		}
+		var response *s.ListDomainsResponse
+		err := backoff.Retry(ctx,
+			func() error {
+				tchCtx, cancel, opt := newChannelContext(ctx)
+				defer cancel()
+				var err error
+				response, err = dc.workflowService.ListDomains(tchCtx, request, opt...)
+				return err
+			}, serviceOperationRetryPolicy, isServiceTransientError)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	return &domainIteratorImpl{
+		paginate: paginate,
+	}
+}
+
+// Deprecate a domain.
+// The errors it can throw:
+//	- EntityNotExistsError
+//	- BadRequestError
+//	- InternalServiceError
+func (dc *domainClient) Deprecate(ctx context.Context, name string) error {
+	request := &s.DeprecateDomainRequest{
+		Name: common.StringPtr(name),
+	}
+
+	return backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel, opt := newChannelContext(ctx)
+			defer cancel()
+			return dc.workflowService.DeprecateDomain(tchCtx, request, opt...)
+		}, serviceOperationRetryPolicy, isServiceTransientError)
+}
+
+// RegisterDomain registers a domain with cadence server.
+// The errors it can throw:
+//	- DomainAlreadyExistsError
+//	- BadRequestError
+//	- InternalServiceError
+func (dc *domainClient) RegisterDomain(ctx context.Context, info DomainInfo, config DomainConfig, replicationConfig DomainReplicationConfig) error {
+	request := &s.RegisterDomainRequest{
+		Name:        common.StringPtr(info.Name),
+		Description: common.StringPtr(info.Description),
+		OwnerEmail:  common.StringPtr(info.OwnerEmail),
+		Data:        info.Data,
+		WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(config.WorkflowExecutionRetentionPeriodInDays),
+		EmitMetric:                             common.BoolPtr(config.EmitMetric),
+		Clusters:                               convertClusterNames(replicationConfig.Clusters),
+	}
+	if replicationConfig.ActiveClusterName != "" {
+		request.ActiveClusterName = common.StringPtr(replicationConfig.ActiveClusterName)
+	}
+	return dc.Register(ctx, request)
+}
+
+// DescribeDomain describes a domain.
+// The errors it can throw:
+//	- EntityNotExistsError
+//	- BadRequestError
+//	- InternalServiceError
+func (dc *domainClient) DescribeDomain(ctx context.Context, name string) (*DomainDescription, error) {
+	response, err := dc.Describe(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainDescription(response), nil
+}
+
+// UpdateDomain applies the changes of the update to a domain and returns the updated domain.
+// The errors it can throw:
+//	- EntityNotExistsError
+//	- BadRequestError
+//	- InternalServiceError
+func (dc *domainClient) UpdateDomain(ctx context.Context, name string, update *DomainUpdate) (*DomainDescription, error) {
+	request := update.toThrift(name)
+
+	var response *s.UpdateDomainResponse
+	err := backoff.Retry(ctx,
+		func() error {
+			tchCtx, cancel, opt := newChannelContext(ctx)
+			defer cancel()
+			var err error
+			response, err = dc.workflowService.UpdateDomain(tchCtx, request, opt...)
+			return err
+		}, serviceOperationRetryPolicy, isServiceTransientError)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainDescription(&s.DescribeDomainResponse{
+		DomainInfo:               response.DomainInfo,
+		Configuration:            response.Configuration,
+		ReplicationConfiguration: response.ReplicationConfiguration,
+		FailoverVersion:          response.FailoverVersion,
+		IsGlobalDomain:           response.IsGlobalDomain,
+	}), nil
+}
+
 func getRunID(runID string) *string {
 	if runID == "" {
 		// Cadence Server will pick current runID if provided empty.
@@ -786,6 +1418,98 @@ func (iter *historyEventIteratorImpl) Next() (*s.HistoryEvent, error) {
 	panic("HistoryEventIterator Next() should return either a history event or a err")
 }
 
+func (iter *workflowExecutionIteratorImpl) HasNext() bool {
+	for {
+		if iter.nextIndex < len(iter.executions) || iter.err != nil {
+			return true
+		}
+		if iter.initialized && len(iter.nexttoken) == 0 {
+			return false
+		}
+		iter.initialized = true
+		iter.nextIndex = 0
+		iter.executions = nil
+		if err := iter.ctx.Err(); err != nil {
+			iter.nexttoken = nil
+			iter.err = err
+			continue
+		}
+
+		executions, nexttoken, err := iter.paginate(iter.nexttoken)
+		if err != nil {
+			iter.nexttoken = nil
+			iter.err = err
+			continue
+		}
+		iter.nexttoken = nexttoken
+		for _, execution := range executions {
+			info := convertWorkflowExecutionInfo(execution)
+			if iter.filter(info) {
+				iter.executions = append(iter.executions, info)
+			}
+		}
+	}
+}
+
+func (iter *workflowExecutionIteratorImpl) Next() (*WorkflowExecutionInfo, error) {
+	if !iter.HasNext() {
+		panic("WorkflowExecutionIterator Next() called without checking HasNext()")
+	}
+
+	// we have cached workflow executions
+	if iter.nextIndex < len(iter.executions) {
+		index := iter.nextIndex
+		iter.nextIndex++
+		return iter.executions[index], nil
+	}
+
+	// we have err, clear that iter.err and return err
+	err := iter.err
+	iter.err = nil
+	return nil, err
+}
+
+func (iter *domainIteratorImpl) HasNext() bool {
+	if iter.nextIndex < len(iter.domains) || iter.err != nil {
+		return true
+	} else if !iter.initialized || len(iter.nexttoken) != 0 {
+		iter.initialized = true
+		response, err := iter.paginate(iter.nexttoken)
+		iter.nextIndex = 0
+		if err == nil {
+			iter.domains = response.Domains
+			iter.nexttoken = response.NextPageToken
+			iter.err = nil
+		} else {
+			iter.domains = nil
+			iter.nexttoken = nil
+			iter.err = err
+		}
+
+		return iter.HasNext()
+	}
+
+	return false
+}
+
+func (iter *domainIteratorImpl) Next() (*DomainDescription, error) {
+	if !iter.HasNext() {
+		panic("DomainIterator Next() called without checking HasNext()")
+	}
+
+	// we have cached domains
+	if iter.nextIndex < len(iter.domains) {
+		index := iter.nextIndex
+		iter.nextIndex++
+		return convertDomainDescription(iter.domains[index]), nil
+	}
+
+	// we have err, clear that iter.err and return err
+	err := iter.err
+	iter.err = nil
+	return nil, err
+}
+
 func (workflowRun *workflowRunImpl) GetRunID() string {
 	return workflowRun.firstRunID
 }
@@ -795,8 +1519,15 @@ func (workflowRun *workflowRunImpl) GetID() string {
 }
 
 func (workflowRun *workflowRunImpl) Get(ctx context.Context, valuePtr interface{}) error {
+	return workflowRun.GetWithOptions(ctx, valuePtr, WorkflowRunGetOptions{})
+}
 
-	iter := workflowRun.iterFn(ctx, workflowRun.currentRunID)
+func (workflowRun *workflowRunImpl) GetWithOptions(ctx context.Context, valuePtr interface{}, options WorkflowRunGetOptions) error {
+	runID := workflowRun.currentRunID
+	if options.DisableFollowingRuns {
+		runID = workflowRun.firstRunID
+	}
+	iter := workflowRun.iterFn(ctx, runID)
 	if !iter.HasNext() {
 		panic("could not get last history event for workflow")
 	}
@@ -830,10 +1561,37 @@ func (workflowRun *workflowRunImpl) Get(ctx context.Context, valuePtr interface{
 		err = NewTimeoutError(attributes.GetTimeoutType())
 	case s.EventTypeWorkflowExecutionContinuedAsNew:
 		attributes := closeEvent.WorkflowExecutionContinuedAsNewEventAttributes
+		if options.DisableFollowingRuns {
+			return &WorkflowContinuedAsNewError{newRunID: attributes.GetNewExecutionRunId()}
+		}
 		workflowRun.currentRunID = attributes.GetNewExecutionRunId()
-		return workflowRun.Get(ctx, valuePtr)
+		return workflowRun.GetWithOptions(ctx, valuePtr, options)
 	default:
 		err = fmt.Errorf("Unexpected event type %s when handling workflow execution result", closeEvent.GetEventType())
 	}
 	return err
 }
+
+func (workflowRun *workflowRunImpl) Signal(ctx context.Context, signalName string, arg interface{}) error {
+	return workflowRun.client.SignalWorkflow(ctx, workflowRun.workflowID, workflowRun.firstRunID, signalName, arg)
+}
+
+func (workflowRun *workflowRunImpl) Cancel(ctx context.Context) error {
+	return workflowRun.client.CancelWorkflow(ctx, workflowRun.workflowID, workflowRun.firstRunID)
+}
+
+func (workflowRun *workflowRunImpl) Terminate(ctx context.Context, reason string, details []byte) error {
+	return workflowRun.client.TerminateWorkflow(ctx, workflowRun.workflowID, workflowRun.firstRunID, reason, details)
+}
+
+func (workflowRun *workflowRunImpl) Query(ctx context.Context, queryType string, args ...interface{}) (encoded.Value, error) {
+	return workflowRun.client.QueryWorkflow(ctx, workflowRun.workflowID, workflowRun.firstRunID, queryType, args...)
+}
+
+func (workflowRun *workflowRunImpl) Describe(ctx context.Context) (*WorkflowExecutionDescription, error) {
+	return workflowRun.client.DescribeWorkflow(ctx, workflowRun.workflowID, workflowRun.firstRunID)
+}
+
+func (workflowRun *workflowRunImpl) History(ctx context.Context) HistoryEventIterator {
+	return workflowRun.client.GetWorkflowHistory(ctx, workflowRun.workflowID, workflowRun.firstRunID, false, s.HistoryEventFilterTypeAllEvent)
+}
@@ -22,8 +22,11 @@ package internal
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +35,8 @@ import (
 	"go.uber.org/cadence/internal/common"
 
 	"github.com/golang/mock/gomock"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/cadence/internal/common/metrics"
@@ -625,6 +630,89 @@ func (s *workflowRunSuite) TestExecuteWorkflow_NoDup_ContinueAsNew() {
 	s.Equal(workflowResult, decodedResult)
 }
 
+func (s *workflowRunSuite) TestGetWorkflow_DisableFollowingRuns() {
+	newRunID := "some other random run ID"
+	filterType := shared.HistoryEventFilterTypeCloseEvent
+	eventType := shared.EventTypeWorkflowExecutionContinuedAsNew
+	getRequest := getGetWorkflowExecutionHistoryRequest(filterType)
+	getResponse := &shared.GetWorkflowExecutionHistoryResponse{
+		History: &shared.History{
+			Events: []*shared.HistoryEvent{
+				&shared.HistoryEvent{
+					EventType: &eventType,
+					WorkflowExecutionContinuedAsNewEventAttributes: &shared.WorkflowExecutionContinuedAsNewEventAttributes{
+						NewExecutionRunId: common.StringPtr(newRunID),
+					},
+				},
+			},
+		},
+		NextPageToken: nil,
+	}
+	s.workflowServiceClient.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), getRequest, gomock.Any(), gomock.Any(), gomock.Any()).Return(getResponse, nil).Times(1)
+
+	workflowRun := s.workflowClient.GetWorkflow(context.Background(), workflowID, runID)
+	s.Equal(workflowID, workflowRun.GetID())
+	s.Equal(runID, workflowRun.GetRunID())
+	err := workflowRun.GetWithOptions(context.Background(), nil, WorkflowRunGetOptions{DisableFollowingRuns: true})
+	continuedErr, ok := err.(*WorkflowContinuedAsNewError)
+	s.True(ok)
+	s.Equal(newRunID, continuedErr.NewRunID())
+}
+
+func (s *workflowRunSuite) TestGetWorkflow_Operations() {
+	workflowRun := s.workflowClient.GetWorkflow(context.Background(), workflowID, runID)
+
+	s.workflowServiceClient.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).
+		Do(func(_ interface{}, req *shared.SignalWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(workflowID, req.WorkflowExecution.GetWorkflowId())
+			s.Equal(runID, req.WorkflowExecution.GetRunId())
+			s.Equal("signal1", req.GetSignalName())
+		})
+	s.NoError(workflowRun.Signal(context.Background(), "signal1", "data"))
+
+	s.workflowServiceClient.EXPECT().RequestCancelWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).
+		Do(func(_ interface{}, req *shared.RequestCancelWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(runID, req.WorkflowExecution.GetRunId())
+		})
+	s.NoError(workflowRun.Cancel(context.Background()))
+
+	s.workflowServiceClient.EXPECT().TerminateWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).
+		Do(func(_ interface{}, req *shared.TerminateWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(runID, req.WorkflowExecution.GetRunId())
+			s.Equal("reason", req.GetReason())
+		})
+	s.NoError(workflowRun.Terminate(context.Background(), "reason", nil))
+
+	queryResult, _ := encodeArg(getDefaultDataConverter(), "state")
+	s.workflowServiceClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.QueryWorkflowResponse{QueryResult: queryResult}, nil).
+		Do(func(_ interface{}, req *shared.QueryWorkflowRequest, _ ...interface{}) {
+			s.Equal(runID, req.Execution.GetRunId())
+			s.Equal("query1", req.Query.GetQueryType())
+		})
+	value, err := workflowRun.Query(context.Background(), "query1")
+	s.NoError(err)
+	var state string
+	s.NoError(value.Get(&state))
+	s.Equal("state", state)
+
+	events := []*shared.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{}),
+	}
+	s.workflowServiceClient.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.GetWorkflowExecutionHistoryResponse{History: &shared.History{Events: events}}, nil).
+		Do(func(_ interface{}, req *shared.GetWorkflowExecutionHistoryRequest, _ ...interface{}) {
+			s.Equal(runID, req.Execution.GetRunId())
+			s.False(req.GetWaitForNewEvent())
+		})
+	iter := workflowRun.History(context.Background())
+	s.True(iter.HasNext())
+	event, err := iter.Next()
+	s.NoError(err)
+	s.Equal(shared.EventTypeWorkflowExecutionStarted, event.GetEventType())
+	s.False(iter.HasNext())
+}
+
 func getGetWorkflowExecutionHistoryRequest(filterType shared.HistoryEventFilterType) *shared.GetWorkflowExecutionHistoryRequest {
 	isLongPoll := true
 
@@ -724,6 +812,47 @@ func (s *workflowClientTestSuite) TestSignalWithStartWorkflow_Error() {
 	s.Equal(createResponse.GetRunId(), resp.RunID)
 }
 
+func (s *workflowClientTestSuite) TestSignalWithStartWorkflowRun() {
+	signalName := "my signal"
+	options := StartWorkflowOptions{
+		TaskList:                     tasklist,
+		ExecutionStartToCloseTimeout: timeoutInSeconds * time.Second,
+		WorkflowIDReusePolicy:        WorkflowIDReusePolicyRejectDuplicate,
+		RequestID:                    "my request ID",
+	}
+
+	createResponse := &shared.StartWorkflowExecutionResponse{
+		RunId: common.StringPtr(runID),
+	}
+	s.service.EXPECT().SignalWithStartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(createResponse, nil).
+		Do(func(_ interface{}, req *shared.SignalWithStartWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(workflowID, req.GetWorkflowId())
+			s.Equal("my request ID", req.GetRequestId())
+			s.Equal(shared.WorkflowIdReusePolicyRejectDuplicate, req.GetWorkflowIdReusePolicy())
+		})
+
+	workflowRun, err := s.client.SignalWithStartWorkflowRun(context.Background(), workflowID, signalName, "signal input",
+		options, workflowType)
+	s.NoError(err)
+	s.Equal(workflowID, workflowRun.GetID())
+	s.Equal(runID, workflowRun.GetRunID())
+
+	options.ID = "other workflow ID"
+	_, err = s.client.SignalWithStartWorkflowRun(context.Background(), workflowID, signalName, "signal input",
+		options, workflowType)
+	s.EqualError(err, "options.ID other workflow ID does not match workflowID "+workflowID)
+
+	s.service.EXPECT().SignalWithStartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(createResponse, nil).
+		Do(func(_ interface{}, req *shared.SignalWithStartWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal("other workflow ID", req.GetWorkflowId())
+			s.Equal(shared.WorkflowIdReusePolicyAllowDuplicate, req.GetWorkflowIdReusePolicy())
+		})
+	execution, err := s.client.SignalWithStartWorkflow(context.Background(), "other workflow ID", signalName, "signal input",
+		options, workflowType)
+	s.NoError(err)
+	s.Equal("other workflow ID", execution.ID)
+}
+
 func (s *workflowClientTestSuite) TestStartWorkflow() {
 	client, ok := s.client.(*workflowClient)
 	s.True(ok)
@@ -748,6 +877,43 @@ func (s *workflowClientTestSuite) TestStartWorkflow() {
 	s.Equal(createResponse.GetRunId(), resp.RunID)
 }
 
+func (s *workflowClientTestSuite) TestStartWorkflow_RequestID() {
+	options := StartWorkflowOptions{
+		ID:                           workflowID,
+		TaskList:                     tasklist,
+		ExecutionStartToCloseTimeout: timeoutInSeconds * time.Second,
+		RequestID:                    "my request ID",
+		RetryStart:                   true,
+	}
+
+	gomock.InOrder(
+		s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, &shared.InternalServiceError{Message: "timeout"}).
+			Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+				s.Equal("my request ID", req.GetRequestId())
+			}),
+		s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, &shared.WorkflowExecutionAlreadyStartedError{
+				StartRequestId: common.StringPtr("my request ID"),
+				RunId:          common.StringPtr(runID),
+			}).
+			Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+				s.Equal("my request ID", req.GetRequestId())
+			}),
+	)
+	execution, err := s.client.StartWorkflow(context.Background(), options, workflowType)
+	s.NoError(err)
+	s.Equal(&WorkflowExecution{ID: workflowID, RunID: runID}, execution)
+
+	alreadyStartedErr := &shared.WorkflowExecutionAlreadyStartedError{
+		StartRequestId: common.StringPtr("other request ID"),
+		RunId:          common.StringPtr(runID),
+	}
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, alreadyStartedErr)
+	_, err = s.client.StartWorkflow(context.Background(), options, workflowType)
+	s.Equal(alreadyStartedErr, err)
+}
+
 func (s *workflowClientTestSuite) TestStartWorkflow_WithDataConverter() {
 	dc := newTestDataConverter()
 	s.client = NewClient(s.service, domain, &ClientOptions{DataConverter: dc})
@@ -782,3 +948,617 @@ func (s *workflowClientTestSuite) TestStartWorkflow_WithDataConverter() {
 	s.Nil(err)
 	s.Equal(createResponse.GetRunId(), resp.RunID)
 }
+
+type testTaskListInterceptor struct {
+	ClientOutboundInterceptorBase
+	calls []string
+}
+
+func (i *testTaskListInterceptor) InterceptClient(next ClientOutboundInterceptor) ClientOutboundInterceptor {
+	i.Next = next
+	return i
+}
+
+func (i *testTaskListInterceptor) StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error) {
+	i.calls = append(i.calls, fmt.Sprintf("StartWorkflow %v %v", workflow, args))
+	if options.TaskList == "" {
+		options.TaskList = tasklist
+	}
+	return i.Next.StartWorkflow(ctx, options, workflow, args...)
+}
+
+func (i *testTaskListInterceptor) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	i.calls = append(i.calls, fmt.Sprintf("SignalWorkflow %v %v", signalName, arg))
+	return i.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (s *workflowClientTestSuite) TestClientInterceptor() {
+	interceptor := &testTaskListInterceptor{}
+	s.client = NewClient(s.service, domain, &ClientOptions{Interceptors: []ClientInterceptor{interceptor}})
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+	}
+
+	createResponse := &shared.StartWorkflowExecutionResponse{
+		RunId: common.StringPtr(runID),
+	}
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(createResponse, nil).
+		Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+			s.Equal(tasklist, req.TaskList.GetName())
+		})
+	s.service.EXPECT().SignalWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	resp, err := s.client.StartWorkflow(context.Background(), options, workflowType, "arg")
+	s.NoError(err)
+	s.Equal(runID, resp.RunID)
+	s.NoError(s.client.SignalWorkflow(context.Background(), workflowID, runID, "my signal", "signal arg"))
+
+	s.Equal([]string{
+		fmt.Sprintf("StartWorkflow %v [arg]", workflowType),
+		"SignalWorkflow my signal signal arg",
+	}, interceptor.calls)
+}
+
+func (s *workflowClientTestSuite) TestStartWorkflow_WithTracer() {
+	tracer := mocktracer.New()
+	s.client = NewClient(s.service, domain, &ClientOptions{Tracer: tracer, EnableHeaderEnvelope: true})
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        tasklist,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+	}
+
+	createResponse := &shared.StartWorkflowExecutionResponse{
+		RunId: common.StringPtr(runID),
+	}
+	var header *shared.Header
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(createResponse, nil).
+		Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+			header, _ = decodeHeaderFromInput(req.Input)
+		})
+
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	_, err := s.client.StartWorkflow(ctx, options, workflowType)
+	s.NoError(err)
+	parent.Finish()
+
+	spans := tracer.FinishedSpans()
+	s.Equal(2, len(spans))
+	startSpan := spans[0]
+	s.Equal("StartWorkflow:"+workflowType, startSpan.OperationName)
+	s.Equal(parent.(*mocktracer.MockSpan).SpanContext.SpanID, startSpan.ParentID)
+
+	s.NotNil(header)
+	propagator := &tracingContextPropagator{tracer: tracer}
+	spanContext, err := propagator.extractSpanContext(NewHeaderReader(header))
+	s.NoError(err)
+	s.Equal(startSpan.SpanContext.SpanID, spanContext.(mocktracer.MockSpanContext).SpanID)
+}
+
+func (s *workflowClientTestSuite) TestStartWorkflow_HeaderEnvelopeDisabled() {
+	s.client = NewClient(s.service, domain, &ClientOptions{ContextPropagators: []ContextPropagator{&testTenantPropagator{}}})
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        tasklist,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+	}
+
+	createResponse := &shared.StartWorkflowExecutionResponse{
+		RunId: common.StringPtr(runID),
+	}
+	var input []byte
+	s.service.EXPECT().StartWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(createResponse, nil).
+		Do(func(_ interface{}, req *shared.StartWorkflowExecutionRequest, _ ...interface{}) {
+			input = req.Input
+		})
+
+	ctx := context.WithValue(context.Background(), testTenantContextKey{}, "tenant-a")
+	_, err := s.client.StartWorkflow(ctx, options, workflowType, "arg")
+	s.NoError(err)
+
+	expected, err := encodeArgs(getDefaultDataConverter(), []interface{}{"arg"})
+	s.NoError(err)
+	s.Equal(expected, input)
+}
+
+func (s *workflowClientTestSuite) TestStartWorkflow_CronScheduleRequiresHeaderEnvelope() {
+	options := StartWorkflowOptions{
+		ID:                              workflowID,
+		TaskList:                        tasklist,
+		ExecutionStartToCloseTimeout:    timeoutInSeconds,
+		DecisionTaskStartToCloseTimeout: timeoutInSeconds,
+		CronSchedule:                    "@hourly",
+	}
+
+	_, err := s.client.StartWorkflow(context.Background(), options, workflowType, "arg")
+	s.Error(err)
+	_, err = s.client.SignalWithStartWorkflow(context.Background(), workflowID, "signal", "value", options, workflowType)
+	s.Error(err)
+}
+
+func (s *workflowClientTestSuite) TestDescribeWorkflow() {
+	heartbeatDetails, err := encodeArgs(nil, []interface{}{"half done", 50})
+	s.NoError(err)
+	activityState := shared.PendingActivityStateStarted
+	describeResponse := &shared.DescribeWorkflowExecutionResponse{
+		ExecutionConfiguration: &shared.WorkflowExecutionConfiguration{
+			TaskList:                            &shared.TaskList{Name: common.StringPtr(tasklist)},
+			ExecutionStartToCloseTimeoutSeconds: common.Int32Ptr(timeoutInSeconds),
+			TaskStartToCloseTimeoutSeconds:      common.Int32Ptr(10),
+		},
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution:     &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+			Type:          &shared.WorkflowType{Name: common.StringPtr(workflowType)},
+			StartTime:     common.Int64Ptr(time.Unix(100, 0).UnixNano()),
+			HistoryLength: common.Int64Ptr(13),
+		},
+		PendingActivities: []*shared.PendingActivityInfo{
+			{
+				ActivityID:             common.StringPtr("activity1"),
+				ActivityType:           &shared.ActivityType{Name: common.StringPtr("upload")},
+				State:                  &activityState,
+				HeartbeatDetails:       heartbeatDetails,
+				LastHeartbeatTimestamp: common.Int64Ptr(time.Unix(200, 0).UnixNano()),
+			},
+		},
+	}
+	events := []*shared.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{}),
+		createTestEventActivityTaskScheduled(5, &shared.ActivityTaskScheduledEventAttributes{ActivityId: common.StringPtr("activity1")}),
+		{
+			EventId:   common.Int64Ptr(7),
+			EventType: common.EventTypePtr(shared.EventTypeStartChildWorkflowExecutionInitiated),
+			StartChildWorkflowExecutionInitiatedEventAttributes: &shared.StartChildWorkflowExecutionInitiatedEventAttributes{
+				WorkflowId:   common.StringPtr("child1"),
+				WorkflowType: &shared.WorkflowType{Name: common.StringPtr("childWorkflow")},
+			},
+		},
+		{
+			EventId:   common.Int64Ptr(8),
+			EventType: common.EventTypePtr(shared.EventTypeStartChildWorkflowExecutionInitiated),
+			StartChildWorkflowExecutionInitiatedEventAttributes: &shared.StartChildWorkflowExecutionInitiatedEventAttributes{
+				WorkflowId:   common.StringPtr("child2"),
+				WorkflowType: &shared.WorkflowType{Name: common.StringPtr("childWorkflow")},
+			},
+		},
+		{
+			EventId:   common.Int64Ptr(9),
+			EventType: common.EventTypePtr(shared.EventTypeChildWorkflowExecutionStarted),
+			ChildWorkflowExecutionStartedEventAttributes: &shared.ChildWorkflowExecutionStartedEventAttributes{
+				InitiatedEventId:  common.Int64Ptr(7),
+				WorkflowExecution: &shared.WorkflowExecution{WorkflowId: common.StringPtr("child1"), RunId: common.StringPtr("childRun1")},
+			},
+		},
+		{
+			EventId:   common.Int64Ptr(10),
+			EventType: common.EventTypePtr(shared.EventTypeStartChildWorkflowExecutionFailed),
+			StartChildWorkflowExecutionFailedEventAttributes: &shared.StartChildWorkflowExecutionFailedEventAttributes{
+				InitiatedEventId: common.Int64Ptr(8),
+			},
+		},
+	}
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+	s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.GetWorkflowExecutionHistoryResponse{History: &shared.History{Events: events}}, nil).
+		Do(func(_ interface{}, req *shared.GetWorkflowExecutionHistoryRequest, _ ...interface{}) {
+			s.Equal(runID, req.Execution.GetRunId())
+		})
+
+	description, err := s.client.DescribeWorkflowWithOptions(context.Background(), workflowID, "", DescribeWorkflowOptions{ReadHistory: true})
+	s.NoError(err)
+	s.True(description.IsRunning())
+	s.Equal(WorkflowExecution{ID: workflowID, RunID: runID}, description.Execution)
+	s.Equal(workflowType, description.WorkflowType.Name)
+	s.Equal(time.Unix(100, 0), description.StartTime)
+	s.True(description.CloseTime.IsZero())
+	s.Equal(int64(13), description.HistoryLength)
+	s.Equal(tasklist, description.TaskList)
+	s.Equal(timeoutInSeconds*time.Second, description.ExecutionStartToCloseTimeout)
+	s.Equal(10*time.Second, description.TaskStartToCloseTimeout)
+
+	s.Nil(description.PendingActivityByID("activity2"))
+	activity := description.PendingActivityByID("activity1")
+	s.NotNil(activity)
+	s.Equal("upload", activity.ActivityType.Name)
+	s.Equal(PendingActivityStateStarted, activity.State)
+	s.Equal(time.Unix(200, 0), activity.LastHeartbeatTime)
+	var progress string
+	var percent int
+	s.NoError(activity.HeartbeatDetails.Get(&progress, &percent))
+	s.Equal("half done", progress)
+	s.Equal(50, percent)
+
+	s.Equal([]PendingChildWorkflowInfo{
+		{WorkflowID: "child1", RunID: "childRun1", WorkflowType: WorkflowType{Name: "childWorkflow"}, InitiatedEventID: 7},
+	}, description.PendingChildren)
+	s.Nil(description.PendingChildByWorkflowID("child2"))
+
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+
+	description, err = s.client.DescribeWorkflow(context.Background(), workflowID, runID)
+	s.NoError(err)
+	s.True(description.IsRunning())
+	s.NotNil(description.PendingActivityByID("activity1"))
+	s.Empty(description.PendingChildren)
+
+	closeStatus := shared.WorkflowExecutionCloseStatusCompleted
+	describeResponse.WorkflowExecutionInfo.CloseStatus = &closeStatus
+	describeResponse.WorkflowExecutionInfo.CloseTime = common.Int64Ptr(time.Unix(300, 0).UnixNano())
+	describeResponse.PendingActivities = nil
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+
+	description, err = s.client.DescribeWorkflowWithOptions(context.Background(), workflowID, runID, DescribeWorkflowOptions{ReadHistory: true})
+	s.NoError(err)
+	s.False(description.IsRunning())
+	s.Equal(WorkflowExecutionStatusCompleted, description.Status)
+	s.Equal(time.Unix(300, 0), description.CloseTime)
+	s.Empty(description.PendingActivities)
+	s.Empty(description.PendingChildren)
+
+	unknownStatus := shared.WorkflowExecutionCloseStatus(100)
+	describeResponse.WorkflowExecutionInfo.CloseStatus = &unknownStatus
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+
+	description, err = s.client.DescribeWorkflow(context.Background(), workflowID, runID)
+	s.NoError(err)
+	s.False(description.IsRunning())
+	s.Equal(WorkflowExecutionStatusUnknown, description.Status)
+}
+
+func (s *workflowClientTestSuite) TestQueryWorkflowWithOptions_Rejected() {
+	closeStatus := shared.WorkflowExecutionCloseStatusFailed
+	describeResponse := &shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution:   &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+			CloseStatus: &closeStatus,
+		},
+	}
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+
+	resp, err := s.client.QueryWorkflowWithOptions(context.Background(), &QueryWorkflowWithOptionsRequest{
+		WorkflowID:           workflowID,
+		QueryType:            "state",
+		QueryRejectCondition: QueryRejectConditionNotCompletedCleanly,
+	})
+	s.NoError(err)
+	s.Nil(resp.QueryResult)
+	s.Equal(&QueryRejected{Status: WorkflowExecutionStatusFailed}, resp.QueryRejected)
+	s.Equal(WorkflowExecutionStatusFailed, resp.Status)
+}
+
+func (s *workflowClientTestSuite) TestQueryWorkflowWithOptions() {
+	describeResponse := &shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution:     &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+			HistoryLength: common.Int64Ptr(6),
+		},
+	}
+	pendingEvents := []*shared.HistoryEvent{
+		createTestEventWorkflowExecutionStarted(1, &shared.WorkflowExecutionStartedEventAttributes{}),
+		createTestEventDecisionTaskScheduled(2, &shared.DecisionTaskScheduledEventAttributes{}),
+		createTestEventDecisionTaskStarted(3),
+		createTestEventDecisionTaskCompleted(4, &shared.DecisionTaskCompletedEventAttributes{}),
+		createTestEventWorkflowExecutionSignaled(5, "signal1"),
+		createTestEventDecisionTaskScheduled(6, &shared.DecisionTaskScheduledEventAttributes{}),
+	}
+	processedEvents := []*shared.HistoryEvent{
+		createTestEventDecisionTaskStarted(7),
+		createTestEventDecisionTaskCompleted(8, &shared.DecisionTaskCompletedEventAttributes{}),
+	}
+
+	result, err := encodeArg(nil, "signaled")
+	s.NoError(err)
+	gomock.InOrder(
+		s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil),
+		s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&shared.GetWorkflowExecutionHistoryResponse{
+				History:       &shared.History{Events: pendingEvents},
+				NextPageToken: []byte("token"),
+			}, nil).
+			Do(func(_ interface{}, req *shared.GetWorkflowExecutionHistoryRequest, _ ...interface{}) {
+				s.True(req.GetWaitForNewEvent())
+				s.Nil(req.NextPageToken)
+			}),
+		// only the events recorded since the last page are read again
+		s.service.EXPECT().GetWorkflowExecutionHistory(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&shared.GetWorkflowExecutionHistoryResponse{
+				History:       &shared.History{Events: processedEvents},
+				NextPageToken: []byte("token"),
+			}, nil).
+			Do(func(_ interface{}, req *shared.GetWorkflowExecutionHistoryRequest, _ ...interface{}) {
+				s.Equal([]byte("token"), req.NextPageToken)
+			}),
+		s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&shared.QueryWorkflowResponse{QueryResult: result}, nil).
+			Do(func(_ interface{}, req *shared.QueryWorkflowRequest, _ ...interface{}) {
+				s.Equal(runID, req.Execution.GetRunId())
+				s.Equal("state", req.Query.GetQueryType())
+			}),
+	)
+
+	resp, err := s.client.QueryWorkflowWithOptions(context.Background(), &QueryWorkflowWithOptionsRequest{
+		WorkflowID:            workflowID,
+		QueryType:             "state",
+		QueryRejectCondition:  QueryRejectConditionNotOpen,
+		QueryConsistencyLevel: QueryConsistencyLevelStrong,
+	})
+	s.NoError(err)
+	s.Nil(resp.QueryRejected)
+	s.Equal(WorkflowExecutionStatusRunning, resp.Status)
+	var state string
+	s.NoError(resp.QueryResult.Get(&state))
+	s.Equal("signaled", state)
+}
+
+func (s *workflowClientTestSuite) TestQueryWorkflowWithOptions_Failed() {
+	message := encodeQueryFailure(NewCustomError("not-ready", "pending", 3), nil)
+	s.True(strings.HasPrefix(message, "not-ready\n"))
+	describeResponse := &shared.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &shared.WorkflowExecutionInfo{
+			Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+		},
+	}
+	s.service.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any(), gomock.Any()).Return(describeResponse, nil)
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, &shared.QueryFailedError{Message: message})
+
+	_, err := s.client.QueryWorkflowWithOptions(context.Background(), &QueryWorkflowWithOptionsRequest{
+		WorkflowID: workflowID,
+		QueryType:  "state",
+	})
+	queryErr, ok := err.(*QueryFailedError)
+	s.True(ok)
+	s.Equal("not-ready", queryErr.Error())
+	s.Equal("not-ready", queryErr.Reason())
+	var detail string
+	var count int
+	s.NoError(queryErr.Details(&detail, &count))
+	s.Equal("pending", detail)
+	s.Equal(3, count)
+
+	s.service.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, &shared.QueryFailedError{Message: message})
+	_, err = s.client.QueryWorkflow(context.Background(), workflowID, "", "state")
+	s.Equal(&shared.QueryFailedError{Message: "not-ready"}, err)
+
+	s.Equal("Workflow panic: boom", encodeQueryFailure(errors.New("Workflow panic: boom"), nil))
+	queryErr = decodeQueryFailure("Workflow panic: boom", nil)
+	s.Equal("Workflow panic: boom", queryErr.Error())
+	s.Equal("", queryErr.Reason())
+	s.Equal(ErrNoData, queryErr.Details())
+}
+
+func (s *workflowClientTestSuite) TestListWorkflows() {
+	startTime := time.Unix(100, 0)
+	closeTime := time.Unix(200, 0)
+	newInfo := func(id string, closeStatus shared.WorkflowExecutionCloseStatus) *shared.WorkflowExecutionInfo {
+		return &shared.WorkflowExecutionInfo{
+			Execution:     &shared.WorkflowExecution{WorkflowId: common.StringPtr(id), RunId: common.StringPtr(runID)},
+			Type:          &shared.WorkflowType{Name: common.StringPtr(workflowType)},
+			StartTime:     common.Int64Ptr(startTime.UnixNano()),
+			CloseTime:     common.Int64Ptr(closeTime.UnixNano()),
+			CloseStatus:   &closeStatus,
+			HistoryLength: common.Int64Ptr(10),
+		}
+	}
+	nextPageToken := []byte("next page")
+	s.service.EXPECT().ListClosedWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListClosedWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{
+				newInfo("wid1", shared.WorkflowExecutionCloseStatusFailed),
+				newInfo("wid2", shared.WorkflowExecutionCloseStatusCompleted),
+			},
+			NextPageToken: nextPageToken,
+		}, nil).
+		Do(func(_ interface{}, req *shared.ListClosedWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal(domain, req.GetDomain())
+			s.Nil(req.NextPageToken)
+			s.Equal(workflowType, req.TypeFilter.GetName())
+			s.Nil(req.StatusFilter)
+			s.Equal(startTime.UnixNano(), req.StartTimeFilter.GetEarliestTime())
+			s.Equal(int64(math.MaxInt64), req.StartTimeFilter.GetLatestTime())
+		})
+	s.service.EXPECT().ListClosedWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListClosedWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{
+				newInfo("wid3", shared.WorkflowExecutionCloseStatusTimedOut),
+			},
+		}, nil).
+		Do(func(_ interface{}, req *shared.ListClosedWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal(nextPageToken, req.NextPageToken)
+		})
+
+	iter := s.client.ListWorkflows(context.Background(), ListWorkflowsFilter{
+		Closed:       true,
+		WorkflowType: workflowType,
+		Statuses:     []WorkflowExecutionStatus{WorkflowExecutionStatusFailed, WorkflowExecutionStatusTimedOut},
+		EarliestTime: startTime,
+	})
+	var infos []*WorkflowExecutionInfo
+	for iter.HasNext() {
+		info, err := iter.Next()
+		s.NoError(err)
+		infos = append(infos, info)
+	}
+	s.Equal([]*WorkflowExecutionInfo{
+		{
+			Execution:     WorkflowExecution{ID: "wid1", RunID: runID},
+			WorkflowType:  WorkflowType{Name: workflowType},
+			StartTime:     startTime,
+			CloseTime:     closeTime,
+			Status:        WorkflowExecutionStatusFailed,
+			HistoryLength: 10,
+		},
+		{
+			Execution:     WorkflowExecution{ID: "wid3", RunID: runID},
+			WorkflowType:  WorkflowType{Name: workflowType},
+			StartTime:     startTime,
+			CloseTime:     closeTime,
+			Status:        WorkflowExecutionStatusTimedOut,
+			HistoryLength: 10,
+		},
+	}, infos)
+}
+
+func (s *workflowClientTestSuite) TestListWorkflows_Canceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.service.EXPECT().ListOpenWorkflowExecutions(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&shared.ListOpenWorkflowExecutionsResponse{
+			Executions: []*shared.WorkflowExecutionInfo{{
+				Execution: &shared.WorkflowExecution{WorkflowId: common.StringPtr(workflowID), RunId: common.StringPtr(runID)},
+				Type:      &shared.WorkflowType{Name: common.StringPtr(workflowType)},
+				StartTime: common.Int64Ptr(0),
+			}},
+			NextPageToken: []byte("next page"),
+		}, nil).
+		Do(func(_ interface{}, req *shared.ListOpenWorkflowExecutionsRequest, _ ...interface{}) {
+			s.Equal(workflowID, req.ExecutionFilter.GetWorkflowId())
+		})
+
+	iter := s.client.ListWorkflows(ctx, ListWorkflowsFilter{WorkflowID: workflowID})
+	s.True(iter.HasNext())
+	info, err := iter.Next()
+	s.NoError(err)
+	s.Equal(WorkflowExecutionStatusRunning, info.Status)
+	s.True(info.CloseTime.IsZero())
+
+	cancel()
+	s.True(iter.HasNext())
+	_, err = iter.Next()
+	s.Equal(context.Canceled, err)
+	s.False(iter.HasNext())
+}
+
+type domainClientTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	service  *workflowservicetest.MockClient
+	client   DomainClient
+}
+
+func TestDomainClientSuite(t *testing.T) {
+	suite.Run(t, new(domainClientTestSuite))
+}
+
+func (s *domainClientTestSuite) SetupTest() {
+	s.mockCtrl = gomock.NewController(s.T())
+	s.service = workflowservicetest.NewMockClient(s.mockCtrl)
+	s.client = NewDomainClient(s.service, nil)
+}
+
+func (s *domainClientTestSuite) TearDownTest() {
+	s.mockCtrl.Finish() // assert mock’s expectations
+}
+
+func newTestDescribeDomainResponse(name string, status shared.DomainStatus) *shared.DescribeDomainResponse {
+	return &shared.DescribeDomainResponse{
+		DomainInfo: &shared.DomainInfo{
+			Name:       common.StringPtr(name),
+			Status:     &status,
+			OwnerEmail: common.StringPtr("owner@example.com"),
+			Data:       map[string]string{"team": "payments"},
+		},
+		Configuration: &shared.DomainConfiguration{
+			WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(3),
+			EmitMetric:                             common.BoolPtr(true),
+		},
+		ReplicationConfiguration: &shared.DomainReplicationConfiguration{
+			ActiveClusterName: common.StringPtr("active"),
+			Clusters: []*shared.ClusterReplicationConfiguration{
+				{ClusterName: common.StringPtr("active")},
+				{ClusterName: common.StringPtr("standby")},
+			},
+		},
+		FailoverVersion: common.Int64Ptr(10),
+		IsGlobalDomain:  common.BoolPtr(true),
+	}
+}
+
+func (s *domainClientTestSuite) TestList() {
+	nextPageToken := []byte("next page")
+	s.service.EXPECT().ListDomains(gomock.Any(), &shared.ListDomainsRequest{}, gomock.Any()).
+		Return(&shared.ListDomainsResponse{
+			Domains:       []*shared.DescribeDomainResponse{newTestDescribeDomainResponse("domain1", shared.DomainStatusRegistered)},
+			NextPageToken: nextPageToken,
+		}, nil)
+	s.service.EXPECT().ListDomains(gomock.Any(), &shared.ListDomainsRequest{NextPageToken: nextPageToken}, gomock.Any()).
+		Return(&shared.ListDomainsResponse{
+			Domains: []*shared.DescribeDomainResponse{newTestDescribeDomainResponse("domain2", shared.DomainStatusDeprecated)},
+		}, nil)
+
+	iter := s.client.List(context.Background())
+	var domains []*DomainDescription
+	for iter.HasNext() {
+		domain, err := iter.Next()
+		s.NoError(err)
+		domains = append(domains, domain)
+	}
+	s.Equal([]*DomainDescription{
+		{
+			Info: DomainInfo{
+				Name:       "domain1",
+				Status:     DomainStatusRegistered,
+				OwnerEmail: "owner@example.com",
+				Data:       map[string]string{"team": "payments"},
+			},
+			Config:            DomainConfig{WorkflowExecutionRetentionPeriodInDays: 3, EmitMetric: true},
+			ReplicationConfig: DomainReplicationConfig{ActiveClusterName: "active", Clusters: []string{"active", "standby"}},
+			FailoverVersion:   10,
+			IsGlobalDomain:    true,
+		},
+		{
+			Info: DomainInfo{
+				Name:       "domain2",
+				Status:     DomainStatusDeprecated,
+				OwnerEmail: "owner@example.com",
+				Data:       map[string]string{"team": "payments"},
+			},
+			Config:            DomainConfig{WorkflowExecutionRetentionPeriodInDays: 3, EmitMetric: true},
+			ReplicationConfig: DomainReplicationConfig{ActiveClusterName: "active", Clusters: []string{"active", "standby"}},
+			FailoverVersion:   10,
+			IsGlobalDomain:    true,
+		},
+	}, domains)
+}
+
+func (s *domainClientTestSuite) TestDeprecate() {
+	s.service.EXPECT().DeprecateDomain(gomock.Any(), &shared.DeprecateDomainRequest{Name: common.StringPtr(domain)}, gomock.Any()).Return(nil)
+	s.NoError(s.client.Deprecate(context.Background(), domain))
+}
+
+func (s *domainClientTestSuite) TestUpdateDomain() {
+	expectedRequest := &shared.UpdateDomainRequest{
+		Name: common.StringPtr(domain),
+		UpdatedInfo: &shared.UpdateDomainInfo{
+			OwnerEmail: common.StringPtr("owner@example.com"),
+			Data:       map[string]string{"team": "payments"},
+		},
+		Configuration: &shared.DomainConfiguration{
+			WorkflowExecutionRetentionPeriodInDays: common.Int32Ptr(3),
+		},
+	}
+	response := newTestDescribeDomainResponse(domain, shared.DomainStatusRegistered)
+	s.service.EXPECT().UpdateDomain(gomock.Any(), expectedRequest, gomock.Any()).
+		Return(&shared.UpdateDomainResponse{
+			DomainInfo:               response.DomainInfo,
+			Configuration:            response.Configuration,
+			ReplicationConfiguration: response.ReplicationConfiguration,
+			FailoverVersion:          response.FailoverVersion,
+			IsGlobalDomain:           response.IsGlobalDomain,
+		}, nil)
+
+	update := NewDomainUpdate().SetOwnerEmail("owner@example.com").SetData("team", "payments").SetRetentionPeriodInDays(3)
+	description, err := s.client.UpdateDomain(context.Background(), domain, update)
+	s.NoError(err)
+	s.Equal(convertDomainDescription(response), description)
+}
+
+func (s *domainClientTestSuite) TestUnknownDomainStatus() {
+	response := newTestDescribeDomainResponse(domain, shared.DomainStatus(100))
+	s.Equal(DomainStatusUnknown, convertDomainDescription(response).Info.Status)
+}
@@ -110,6 +110,7 @@ type (
 		testSuite *WorkflowTestSuite
 
 		taskListSpecificActivities map[string]*taskListSpecificActivity
+		registry                   *hostEnvImpl
 
 		mock         *mock.Mock
 		service      workflowserviceclient.Interface
@@ -155,6 +156,7 @@ type (
 		workflowInfo   *WorkflowInfo
 		workflowDef    workflowDefinition
 		changeVersions map[string]Version
+		cronSchedule   string
 
 		workflowCancelHandler func()
 		signalHandler         func(name string, input []byte)
@@ -174,6 +176,7 @@ func newTestWorkflowEnvironmentImpl(s *WorkflowTestSuite) *testWorkflowEnvironme
 		testWorkflowEnvironmentShared: &testWorkflowEnvironmentShared{
 			testSuite:                  s,
 			taskListSpecificActivities: make(map[string]*taskListSpecificActivity),
+			registry:                   newWorkerHostEnvironment(),
 
 			logger:           s.logger,
 			metricsScope:     metrics.NewTaggedScope(s.scope),
@@ -317,6 +320,24 @@ func (env *testWorkflowEnvironmentImpl) newTestWorkflowEnvironmentForChild(param
 	return childEnv, nil
 }
 
+func (env *testWorkflowEnvironmentImpl) setStartWorkflowOptions(options StartWorkflowOptions) {
+	if len(options.TaskList) > 0 {
+		env.workflowInfo.TaskListName = options.TaskList
+	}
+	if options.ExecutionStartToCloseTimeout > 0 {
+		env.workflowInfo.ExecutionStartToCloseTimeoutSeconds = common.Int32Ceil(options.ExecutionStartToCloseTimeout.Seconds())
+	}
+	if options.DecisionTaskStartToCloseTimeout > 0 {
+		env.workflowInfo.TaskStartToCloseTimeoutSeconds = common.Int32Ceil(options.DecisionTaskStartToCloseTimeout.Seconds())
+	}
+	if len(options.CronSchedule) > 0 {
+		if _, err := parseCronSchedule(options.CronSchedule); err != nil {
+			panic(err)
+		}
+		env.cronSchedule = options.CronSchedule
+	}
+}
+
 func (env *testWorkflowEnvironmentImpl) setWorkerOptions(options WorkerOptions) {
 	if len(options.Identity) > 0 {
 		env.workerOptions.Identity = options.Identity
@@ -330,6 +351,21 @@ func (env *testWorkflowEnvironmentImpl) setWorkerOptions(options WorkerOptions)
 	if options.DataConverter != nil {
 		env.workerOptions.DataConverter = options.DataConverter
 	}
+	if len(options.ContextPropagators) > 0 {
+		env.workerOptions.ContextPropagators = options.ContextPropagators
+	}
+	if len(options.Interceptors) > 0 {
+		env.workerOptions.Interceptors = options.Interceptors
+	}
+	if options.EnableHeaderEnvelope {
+		env.workerOptions.EnableHeaderEnvelope = true
+	}
+	if options.UnhandledSignalPolicy != UnhandledSignalPolicyDrop {
+		env.workerOptions.UnhandledSignalPolicy = options.UnhandledSignalPolicy
+	}
+	if options.Tracer != nil {
+		env.workerOptions.Tracer = options.Tracer
+	}
 }
 
 func (env *testWorkflowEnvironmentImpl) setActivityTaskList(tasklist string, activityFns ...interface{}) {
@@ -345,7 +381,11 @@ func (env *testWorkflowEnvironmentImpl) setActivityTaskList(tasklist string, act
 }
 
 func (env *testWorkflowEnvironmentImpl) executeWorkflow(workflowFn interface{}, args ...interface{}) {
-	workflowType, input, err := getValidatedWorkflowFunction(workflowFn, args, env.GetDataConverter())
+	workflowType, input, err := getValidatedWorkflowFunction(workflowFn, args, env.GetDataConverter(), env.GetRegistry())
+	if err != nil {
+		panic(err)
+	}
+	input, err = withCronSchedule(input, env.cronSchedule)
 	if err != nil {
 		panic(err)
 	}
@@ -371,7 +411,7 @@ func (env *testWorkflowEnvironmentImpl) executeWorkflowInternal(workflowType str
 }
 
 func (env *testWorkflowEnvironmentImpl) getWorkflowDefinition(wt WorkflowType) (workflowDefinition, error) {
-	hostEnv := getHostEnvironment()
+	hostEnv := env.registry
 	wf, ok := hostEnv.getWorkflowFn(wt.Name)
 	if !ok {
 		supported := strings.Join(hostEnv.getRegisteredWorkflowTypes(), ", ")
@@ -388,7 +428,7 @@ func (env *testWorkflowEnvironmentImpl) executeActivity(
 	activityFn interface{},
 	args ...interface{},
 ) (encoded.Value, error) {
-	activityType, input, err := getValidatedActivityFunction(activityFn, args, env.GetDataConverter())
+	activityType, input, err := getValidatedActivityFunction(activityFn, args, env.GetDataConverter(), env.GetRegistry())
 	if err != nil {
 		panic(err)
 	}
@@ -451,7 +491,7 @@ func (env *testWorkflowEnvironmentImpl) executeLocalActivity(
 	task := &localActivityTask{
 		activityID: "test-local-activity",
 		params:     &params,
-		callback: func(result []byte, err error) {
+		callback: func(lar *localActivityResultWrapper) {
 		},
 	}
 	taskHandler := localActivityTaskHandler{
@@ -460,7 +500,7 @@ func (env *testWorkflowEnvironmentImpl) executeLocalActivity(
 		logger:       env.logger,
 	}
 
-	result := taskHandler.executeLocalActivityTask(task)
+	result := taskHandler.executeLocalActivityTask(task, 0)
 	if result.err != nil {
 		return nil, result.err
 	}
@@ -724,6 +764,26 @@ func (env *testWorkflowEnvironmentImpl) GetDataConverter() encoded.DataConverter
 	return env.workerOptions.DataConverter
 }
 
+func (env *testWorkflowEnvironmentImpl) GetContextPropagators() []ContextPropagator {
+	return withTracingContextPropagator(env.workerOptions.Tracer, env.workerOptions.ContextPropagators)
+}
+
+func (env *testWorkflowEnvironmentImpl) GetWorkerInterceptors() []WorkerInterceptor {
+	return withTracingWorkerInterceptor(env.workerOptions.Tracer, env.workerOptions.Interceptors)
+}
+
+func (env *testWorkflowEnvironmentImpl) IsHeaderEnvelopeEnabled() bool {
+	return env.workerOptions.EnableHeaderEnvelope
+}
+
+func (env *testWorkflowEnvironmentImpl) GetUnhandledSignalPolicy() UnhandledSignalPolicy {
+	return env.workerOptions.UnhandledSignalPolicy
+}
+
+func (env *testWorkflowEnvironmentImpl) GetRegistry() *hostEnvImpl {
+	return env.registry
+}
+
 func (env *testWorkflowEnvironmentImpl) ExecuteActivity(parameters executeActivityParams, callback resultHandler) *activityInfo {
 	var activityID string
 	if parameters.ActivityID == nil || *parameters.ActivityID == "" {
@@ -760,11 +820,11 @@ func (env *testWorkflowEnvironmentImpl) ExecuteActivity(parameters executeActivi
 	return activityInfo
 }
 
-func (env *testWorkflowEnvironmentImpl) ExecuteLocalActivity(params executeLocalActivityParams, callback resultHandler) *localActivityInfo {
+func (env *testWorkflowEnvironmentImpl) ExecuteLocalActivity(params executeLocalActivityParams, callback laResultHandler) *localActivityInfo {
 	activityID := getStringID(env.nextID())
 	wOptions := fillWorkerOptionsDefaults(env.workerOptions)
 	ae := &activityExecutor{name: getFunctionName(params.ActivityFn), fn: params.ActivityFn}
-	if at, _, _ := getValidatedActivityFunction(params.ActivityFn, params.InputArgs, wOptions.DataConverter); at != nil {
+	if at, _, _ := getValidatedActivityFunction(params.ActivityFn, params.InputArgs, wOptions.DataConverter, env.GetRegistry()); at != nil {
 		// local activity could be registered, if so use the registered name. This name is only used to find a mock.
 		ae.name = at.Name
 	}
@@ -779,19 +839,22 @@ func (env *testWorkflowEnvironmentImpl) ExecuteLocalActivity(params executeLocal
 		activityID: activityID,
 		params:     &params,
 		callback:   callback,
+		attempt:    params.Attempt,
 	}
 	taskHandler := localActivityTaskHandler{
-		userContext:   wOptions.BackgroundActivityContext,
-		metricsScope:  wOptions.MetricsScope,
-		logger:        wOptions.Logger,
-		dataConverter: wOptions.DataConverter,
+		userContext:        wOptions.BackgroundActivityContext,
+		metricsScope:       wOptions.MetricsScope,
+		logger:             wOptions.Logger,
+		dataConverter:      wOptions.DataConverter,
+		contextPropagators: env.GetContextPropagators(),
+		workerInterceptors: env.GetWorkerInterceptors(),
 	}
 
 	env.localActivities[activityID] = task
 	env.runningCount++
 
 	go func() {
-		result := taskHandler.executeLocalActivityTask(task)
+		result := taskHandler.executeLocalActivityTask(task, task.attempt)
 		env.postCallback(func() {
 			env.handleLocalActivityResult(result)
 			env.runningCount--
@@ -811,7 +874,7 @@ func (env *testWorkflowEnvironmentImpl) RequestCancelLocalActivity(activityID st
 	env.logger.Debug("RequestCancelLocalActivity", zap.String(tagActivityID, activityID))
 	delete(env.localActivities, activityID)
 	env.postCallback(func() {
-		task.callback(nil, NewCanceledError())
+		task.callback(&localActivityResultWrapper{err: NewCanceledError(), attempt: task.attempt, backoff: noRetryBackoff})
 		if env.onLocalActivityCanceledListener != nil {
 			env.onLocalActivityCanceledListener(activityInfo)
 		}
@@ -886,7 +949,13 @@ func (env *testWorkflowEnvironmentImpl) handleLocalActivityResult(result *localA
 	}
 
 	delete(env.localActivities, activityID)
-	task.callback(result.result, result.err)
+	// all retries go through the workflow timer, so the backoff is driven by the mock clock.
+	task.callback(&localActivityResultWrapper{
+		result:  result.result,
+		err:     result.err,
+		attempt: result.attempt,
+		backoff: getRetryBackoff(result, env.Now(), env.GetDataConverter()),
+	})
 	if env.onLocalActivityCompletedListener != nil {
 		if result.err != nil {
 			env.onLocalActivityCompletedListener(activityInfo, nil, result.err)
@@ -1173,16 +1242,18 @@ func (m *mockWrapper) executeMockWithActualArgs(ctx interface{}, inputArgs []int
 func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList string, dataConverter encoded.DataConverter) ActivityTaskHandler {
 	wOptions := fillWorkerOptionsDefaults(env.workerOptions)
 	params := workerExecutionParameters{
-		TaskList:      taskList,
-		Identity:      wOptions.Identity,
-		MetricsScope:  wOptions.MetricsScope,
-		Logger:        wOptions.Logger,
-		UserContext:   wOptions.BackgroundActivityContext,
-		DataConverter: dataConverter,
+		TaskList:           taskList,
+		Identity:           wOptions.Identity,
+		MetricsScope:       wOptions.MetricsScope,
+		Logger:             wOptions.Logger,
+		UserContext:        wOptions.BackgroundActivityContext,
+		DataConverter:      dataConverter,
+		ContextPropagators: env.GetContextPropagators(),
+		WorkerInterceptors: env.GetWorkerInterceptors(),
 	}
 	ensureRequiredParams(&params)
 
-	if len(getHostEnvironment().getRegisteredActivities()) == 0 {
+	if len(env.registry.getRegisteredActivities()) == 0 {
 		panic(fmt.Sprintf("no activity is registered for tasklist '%v'", taskList))
 	}
 
@@ -1196,7 +1267,7 @@ func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList stri
 			}
 		}
 
-		activity, ok := getHostEnvironment().getActivity(name)
+		activity, ok := env.registry.getActivity(name)
 		if !ok {
 			return nil
 		}
@@ -1204,7 +1275,7 @@ func (env *testWorkflowEnvironmentImpl) newTestActivityTaskHandler(taskList stri
 		return &activityExecutorWrapper{activityExecutor: ae, env: env}
 	}
 
-	taskHandler := newActivityTaskHandlerWithCustomProvider(env.service, params, getHostEnvironment(), getActivity)
+	taskHandler := newActivityTaskHandlerWithCustomProvider(env.service, params, env.registry, getActivity)
 	return taskHandler
 }
 
@@ -1463,6 +1534,10 @@ func (env *testWorkflowEnvironmentImpl) signalWorkflow(name string, input interf
 	if err != nil {
 		panic(err)
 	}
+	env.signalWorkflowWithData(name, data)
+}
+
+func (env *testWorkflowEnvironmentImpl) signalWorkflowWithData(name string, data []byte) {
 	env.postCallback(func() {
 		env.signalHandler(name, data)
 	}, true)
@@ -24,10 +24,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/cadence/.gen/go/shared"
@@ -251,6 +255,92 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowAutoForwardClock() {
 	s.Equal("hello_controlled_execution", result)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_Await() {
+	workflowFn := func(ctx Context) (string, error) {
+		var data string
+		Go(ctx, func(ctx Context) {
+			GetSignalChannel(ctx, "await-signal").Receive(ctx, &data)
+		})
+		if err := Await(ctx, func() bool { return data != "" }); err != nil {
+			return "", err
+		}
+		return data, nil
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("await-signal", "s1")
+	}, time.Hour)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("s1", result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_Await_Canceled() {
+	workflowFn := func(ctx Context) error {
+		return Await(ctx, func() bool { return false })
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.CancelWorkflow()
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	_, ok := env.GetWorkflowError().(*CanceledError)
+	s.True(ok)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_AwaitWithTimeout() {
+	workflowFn := func(ctx Context, timeout time.Duration) (bool, error) {
+		var signaled bool
+		Go(ctx, func(ctx Context) {
+			GetSignalChannel(ctx, "await-signal").Receive(ctx, nil)
+			signaled = true
+		})
+		start := Now(ctx)
+		ok, err := AwaitWithTimeout(ctx, timeout, func() bool { return signaled })
+		if err != nil {
+			return false, err
+		}
+		if !ok && Now(ctx).Sub(start) < timeout {
+			return false, errors.New("AwaitWithTimeout returned before the timeout expired")
+		}
+		return ok, nil
+	}
+	RegisterWorkflow(workflowFn)
+
+	// signal arrives before the timeout
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("await-signal", nil)
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn, time.Hour)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var ok bool
+	s.NoError(env.GetWorkflowResult(&ok))
+	s.True(ok)
+
+	// timeout expires first
+	env = s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("await-signal", nil)
+	}, time.Hour)
+	env.ExecuteWorkflow(workflowFn, time.Minute)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.NoError(env.GetWorkflowResult(&ok))
+	s.False(ok)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_WorkflowMixedClock() {
 	workflowFn := func(ctx Context) (string, error) {
 		// Schedule a long timer.
@@ -336,6 +426,162 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowActivityCancellation() {
 	s.Equal(activityMap["slow"], cancelledActivityID)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_CancellationScope() {
+	workflowFn := func(ctx Context) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		scope := NewCancellationScope(ctx, CancellationScopeOptions{WaitForCancellation: true})
+		f1 := ExecuteActivity(scope.Context(), testActivityHeartbeat, "slow", time.Second*3)
+		t1 := NewTimer(scope.Context(), time.Hour)
+		nested := NewCancellationScope(scope.Context(), CancellationScopeOptions{})
+		t2 := NewTimer(nested.Context(), time.Hour)
+		detached := NewDetachedCancellationScope(scope.Context(), CancellationScopeOptions{})
+
+		scope.Cancel(ctx)
+		for _, f := range []Future{f1, t1, t2} {
+			if !f.IsReady() {
+				return "", errors.New("future is not ready after Cancel")
+			}
+			if _, ok := f.Get(ctx, nil).(*CanceledError); !ok {
+				return "", errors.New("future is not canceled")
+			}
+		}
+		if !scope.IsCanceled() || !nested.IsCanceled() || detached.IsCanceled() || ctx.Err() != nil {
+			return "", errors.New("unexpected cancellation state")
+		}
+
+		var result string
+		err := ExecuteActivity(detached.Context(), testActivityHello, "detached").Get(ctx, &result)
+		return result, err
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	var canceledActivities int
+	env.SetOnActivityCanceledListener(func(activityInfo *ActivityInfo) {
+		canceledActivities++
+	})
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("hello_detached", result)
+	s.Equal(1, canceledActivities)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_CancellationScope_Detached() {
+	workflowFn := func(ctx Context) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		err := NewTimer(ctx, time.Hour).Get(ctx, nil)
+		if _, ok := err.(*CanceledError); !ok {
+			return "", errors.New("timer is not canceled")
+		}
+		if !NewCancellationScope(ctx, CancellationScopeOptions{}).IsCanceled() {
+			return "", errors.New("scope of canceled context is not canceled")
+		}
+
+		cleanup := NewDetachedCancellationScope(ctx, CancellationScopeOptions{})
+		var result string
+		err = ExecuteActivity(cleanup.Context(), testActivityHello, "cleanup").Get(ctx, &result)
+		return result, err
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.CancelWorkflow()
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("hello_cleanup", result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_Saga() {
+	workflowFn := func(ctx Context, options SagaOptions) error {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		saga := NewSaga(options)
+		saga.AddCompensation(testActivityHello, "c1")
+		saga.AddCompensation(testActivityHello, "c2")
+		saga.AddCompensation(testActivityHello, "c3")
+		return saga.Compensate(ctx)
+	}
+	RegisterWorkflow(workflowFn)
+
+	testCases := []struct {
+		name     string
+		options  SagaOptions
+		executed []string
+	}{
+		{"stop at first failure", SagaOptions{}, []string{"c3", "c2"}},
+		{"continue with error", SagaOptions{ContinueWithError: true}, []string{"c3", "c2", "c1"}},
+		{"parallel", SagaOptions{ParallelCompensation: true}, []string{"c3", "c2", "c1"}},
+	}
+	for _, tc := range testCases {
+		env := s.NewTestWorkflowEnvironment()
+		var lock sync.Mutex
+		var executed []string
+		env.SetOnActivityStartedListener(func(activityInfo *ActivityInfo, ctx context.Context, args encoded.Values) {
+			var msg string
+			s.NoError(args.Get(&msg))
+			lock.Lock()
+			defer lock.Unlock()
+			executed = append(executed, msg)
+		})
+		env.OnActivity(testActivityHello, mock.Anything, "c2").Return("", errors.New("c2 failed"))
+		env.OnActivity(testActivityHello, mock.Anything, mock.Anything).Return("compensated", nil)
+		env.ExecuteWorkflow(workflowFn, tc.options)
+
+		s.True(env.IsWorkflowCompleted(), tc.name)
+		s.Error(env.GetWorkflowError(), tc.name)
+		s.Contains(env.GetWorkflowError().Error(), "1 compensations failed: c2 failed", tc.name)
+		if tc.options.ParallelCompensation {
+			sort.Sort(sort.Reverse(sort.StringSlice(executed)))
+		}
+		s.Equal(tc.executed, executed, tc.name)
+	}
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_Saga_WorkflowCanceled() {
+	workflowFn := func(ctx Context) (err error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		saga := NewSaga(SagaOptions{})
+		defer func() {
+			if compensationErr := saga.Compensate(ctx); compensationErr != nil {
+				err = compensationErr
+			}
+		}()
+
+		if err := ExecuteActivity(ctx, testActivityHello, "step").Get(ctx, nil); err != nil {
+			return err
+		}
+		saga.AddCompensation(testActivityHello, "compensate-step")
+		return Sleep(ctx, time.Hour)
+	}
+	RegisterWorkflow(workflowFn)
+
+	env := s.NewTestWorkflowEnvironment()
+	var executed []string
+	env.SetOnActivityStartedListener(func(activityInfo *ActivityInfo, ctx context.Context, args encoded.Values) {
+		var msg string
+		s.NoError(args.Get(&msg))
+		executed = append(executed, msg)
+	})
+	env.RegisterDelayedCallback(func() {
+		env.CancelWorkflow()
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	_, ok := env.GetWorkflowError().(*CanceledError)
+	s.True(ok)
+	s.Equal([]string{"step", "compensate-step"}, executed)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_ActivityWithUserContext() {
 	testKey, testValue := testContextKey("test_key"), "test_value"
 	userCtx := context.WithValue(context.Background(), testKey, testValue)
@@ -1308,6 +1554,85 @@ func (s *WorkflowTestSuiteUnitTest) Test_QueryWorkflow() {
 	verifyStateWithQuery(stateDone)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_QueryWorkflow_QueryTypes() {
+	workflowFn := func(ctx Context) error {
+		err := SetQueryHandler(ctx, "state", func(prefix string, count int) (string, error) {
+			return prefix, nil
+		})
+		if err != nil {
+			return err
+		}
+		err = SetQueryHandler(ctx, "removed", func() (string, error) { return "", nil })
+		if err != nil {
+			return err
+		}
+		RemoveQueryHandler(ctx, "removed")
+		if err := SetQueryHandler(ctx, "invalid", func(ctx Context) (string, error) { return "", nil }); err == nil {
+			return errors.New("expected error for query handler with workflow context argument")
+		}
+		return nil
+	}
+	RegisterWorkflow(workflowFn)
+
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	encodedValue, err := env.QueryWorkflow(QueryTypeQueryTypes)
+	s.NoError(err)
+	var queryTypes []QueryTypeInfo
+	s.NoError(encodedValue.Get(&queryTypes))
+	s.Equal([]QueryTypeInfo{
+		{QueryType: QueryTypeQueryTypes, ArgTypes: []string{}},
+		{QueryType: QueryTypeStackTrace, ArgTypes: []string{}},
+		{QueryType: "state", ArgTypes: []string{"string", "int"}},
+	}, queryTypes)
+
+	_, err = env.QueryWorkflow("removed")
+	s.Error(err)
+	s.Contains(err.Error(), "unknown queryType removed")
+
+	_, err = env.QueryWorkflow("state", "prefix", "not an int")
+	s.Error(err)
+	s.Contains(err.Error(), "expected arguments: [string int]")
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_QueryWorkflow_ReadOnly() {
+	workflowFn := func(ctx Context) error {
+		ch := NewBufferedChannel(ctx, 1)
+		err := SetQueryHandler(ctx, "send", func() (bool, error) {
+			return ch.SendAsync("query"), nil
+		})
+		if err != nil {
+			return err
+		}
+		err = SetQueryHandler(ctx, "timer", func() (bool, error) {
+			return NewTimer(ctx, time.Minute).IsReady(), nil
+		})
+		if err != nil {
+			return err
+		}
+		return Sleep(ctx, time.Hour)
+	}
+	RegisterWorkflow(workflowFn)
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		_, err := env.QueryWorkflow("send")
+		s.Error(err)
+		s.Contains(err.Error(), "Channel.SendAsync is not allowed in a query handler")
+
+		_, err = env.QueryWorkflow("timer")
+		s.Error(err)
+		s.Contains(err.Error(), "NewTimer is not allowed in a query handler")
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivity() {
 	localActivityFn := func(ctx context.Context, name string) (string, error) {
 		return "hello " + name, nil
@@ -1332,6 +1657,104 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivity() {
 	s.Equal("hello local_activity", result)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_WorkflowWithLocalActivityRetry() {
+	var attempts []int
+	localActivityFn := func(ctx context.Context) (int, error) {
+		attempt := GetActivityInfo(ctx).Attempt
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return 0, NewCustomError("retry-me")
+		}
+		return attempt, nil
+	}
+
+	workflowFn := func(ctx Context, nonRetriableReason string) (int, error) {
+		lao := s.localActivityOptions
+		lao.RetryPolicy = &RetryPolicy{
+			InitialInterval:          time.Minute,
+			MaximumAttempts:          5,
+			NonRetriableErrorReasons: []string{nonRetriableReason},
+		}
+		ctx = WithLocalActivityOptions(ctx, lao)
+		var result int
+		err := ExecuteLocalActivity(ctx, localActivityFn).Get(ctx, &result)
+		return result, err
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	startTime := env.Now()
+	env.ExecuteWorkflow(workflowFn, "bad-request")
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result int
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal(2, result)
+	s.Equal([]int{0, 1, 2}, attempts)
+	// backoffs of 1 and 2 minutes are waited out on the workflow clock
+	s.True(env.Now().Sub(startTime) >= 3*time.Minute)
+
+	attempts = nil
+	env = s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(workflowFn, "retry-me")
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Equal([]int{0}, attempts)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_CronWorkflow() {
+	var runInfo WorkflowInfo
+	var runTime time.Time
+	cronWorkflowFn := func(ctx Context) error {
+		runInfo = *GetWorkflowInfo(ctx)
+		runTime = Now(ctx)
+		return nil
+	}
+
+	RegisterWorkflow(cronWorkflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.SetStartWorkflowOptions(StartWorkflowOptions{CronSchedule: "*/15 * * * *", ExecutionStartToCloseTimeout: time.Hour})
+	cron, err := parseCronSchedule("*/15 * * * *")
+	s.NoError(err)
+	scheduledTime := cron.Next(env.Now())
+	env.ExecuteWorkflow(cronWorkflowFn)
+	s.True(env.IsWorkflowCompleted())
+	_, ok := env.GetWorkflowError().(*ContinueAsNewError)
+	s.True(ok)
+
+	s.Equal("*/15 * * * *", runInfo.CronSchedule)
+	s.Equal(scheduledTime, runInfo.CronScheduledTime)
+	s.Equal("", runInfo.CronLastFailure)
+	s.False(runTime.Before(scheduledTime))
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_CronWorkflowFailure() {
+	cronFailingWorkflowFn := func(ctx Context) error {
+		return errors.New("cron run failed")
+	}
+
+	RegisterWorkflow(cronFailingWorkflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.SetStartWorkflowOptions(StartWorkflowOptions{CronSchedule: "@hourly", ExecutionStartToCloseTimeout: 2 * time.Hour})
+	env.ExecuteWorkflow(cronFailingWorkflowFn)
+	s.True(env.IsWorkflowCompleted())
+	continueAsNewErr, ok := env.GetWorkflowError().(*ContinueAsNewError)
+	s.True(ok)
+	header, _ := decodeHeaderFromInput(continueAsNewErr.params.input)
+	s.Equal("@hourly", getCronSchedule(header))
+	s.Equal("cron run failed", getCronHeader(header, cronLastFailureHeaderKey))
+
+	// the schedule does not fire within the timeout of the run
+	env = s.NewTestWorkflowEnvironment()
+	env.SetStartTime(time.Date(2019, time.May, 1, 12, 0, 0, 0, time.UTC))
+	env.SetStartWorkflowOptions(StartWorkflowOptions{CronSchedule: "@daily", ExecutionStartToCloseTimeout: time.Hour})
+	env.ExecuteWorkflow(cronFailingWorkflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	_, ok = env.GetWorkflowError().(*ContinueAsNewError)
+	s.False(ok)
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_LocalActivity() {
 	localActivityFn := func(ctx context.Context, name string) (string, error) {
 		return "hello " + name, nil
@@ -1412,6 +1835,185 @@ func (s *WorkflowTestSuiteUnitTest) Test_WorkflowLocalActivityWithMockAndListene
 	s.Equal("hello mock", result)
 }
 
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		// signals sent before the handler is set are buffered
+		if err := Sleep(ctx, time.Minute); err != nil {
+			return nil, err
+		}
+		var received []string
+		err := SetSignalHandler(ctx, "test-signal", func(ctx Context, data string) {
+			received = append(received, data)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := SetSignalHandler(ctx, "test-signal", func(ctx Context) {}); err == nil {
+			return nil, errors.New("expected error for duplicate signal handler")
+		}
+		if err := Await(ctx, func() bool { return len(received) == 3 }); err != nil {
+			return nil, err
+		}
+		return received, nil
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("test-signal", "s1")
+		env.SignalWorkflow("test-signal", "s2")
+	}, time.Second)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("test-signal", "s3")
+	}, time.Hour)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]string{"s1", "s2", "s3"}, result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_ContextPropagation() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		var tenants []string
+		err := SetSignalHandler(ctx, "tenant-signal", func(ctx Context, data string) {
+			tenant, _ := ctx.Value(testTenantContextKey{}).(string)
+			tenants = append(tenants, data+":"+tenant)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var data string
+		GetSignalChannel(ctx, "plain-signal").Receive(ctx, &data)
+		if err := Await(ctx, func() bool { return len(tenants) == 1 }); err != nil {
+			return nil, err
+		}
+		return append(tenants, data), nil
+	}
+
+	propagators := []ContextPropagator{&testTenantPropagator{}}
+	signalData := func(arg string) []byte {
+		input, err := encodeArg(getDefaultDataConverter(), arg)
+		s.NoError(err)
+		ctx := context.WithValue(context.Background(), testTenantContextKey{}, "tenant-a")
+		input, err = injectHeaderFromContext(ctx, propagators, input)
+		s.NoError(err)
+		return input
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{ContextPropagators: propagators})
+	env.RegisterDelayedCallback(func() {
+		env.impl.signalWorkflowWithData("tenant-signal", signalData("s1"))
+		env.impl.signalWorkflowWithData("plain-signal", signalData("s2"))
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]string{"s1:tenant-a", "s2"}, result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_BufferedSignals() {
+	workflowFn := func(ctx Context) ([]string, error) {
+		var first string
+		GetSignalChannel(ctx, "tenant-signal").Receive(ctx, &first)
+		if err := Sleep(ctx, time.Hour); err != nil {
+			return nil, err
+		}
+		handled := []string{first}
+		err := SetSignalHandler(ctx, "tenant-signal", func(ctx Context, data string) {
+			tenant, _ := ctx.Value(testTenantContextKey{}).(string)
+			handled = append(handled, data+":"+tenant)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := Await(ctx, func() bool { return len(handled) == 3 }); err != nil {
+			return nil, err
+		}
+		return handled, nil
+	}
+
+	propagators := []ContextPropagator{&testTenantPropagator{}}
+	signalData := func(arg, tenant string) []byte {
+		input, err := encodeArg(getDefaultDataConverter(), arg)
+		s.NoError(err)
+		ctx := context.WithValue(context.Background(), testTenantContextKey{}, tenant)
+		input, err = injectHeaderFromContext(ctx, propagators, input)
+		s.NoError(err)
+		return input
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{ContextPropagators: propagators})
+	env.RegisterDelayedCallback(func() {
+		env.impl.signalWorkflowWithData("tenant-signal", signalData("s1", "tenant-a"))
+		env.impl.signalWorkflowWithData("tenant-signal", signalData("s2", "tenant-b"))
+		env.impl.signalWorkflowWithData("tenant-signal", signalData("s3", "tenant-c"))
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result []string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal([]string{"s1", "s2:tenant-b", "s3:tenant-c"}, result)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_SignalHandler_InvalidHandler() {
+	workflowFn := func(ctx Context) error {
+		if err := SetSignalHandler(ctx, "test-signal", "not a function"); err == nil {
+			return errors.New("expected error for non function handler")
+		}
+		if err := SetSignalHandler(ctx, "test-signal", func(data string) {}); err == nil {
+			return errors.New("expected error for handler without context")
+		}
+		if err := SetSignalHandler(ctx, "test-signal", func(ctx Context, data string) error { return nil }); err == nil {
+			return errors.New("expected error for handler with return value")
+		}
+		return nil
+	}
+
+	RegisterWorkflow(workflowFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_UnhandledSignalPolicy() {
+	workflowFn := func(ctx Context) error {
+		return Sleep(ctx, time.Hour)
+	}
+	RegisterWorkflow(workflowFn)
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("test-signal", "s1")
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	env = s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{UnhandledSignalPolicy: UnhandledSignalPolicyFailDecision})
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("test-signal", "s1")
+	}, time.Minute)
+	env.ExecuteWorkflow(workflowFn)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "unhandled signals: [test-signal]")
+}
+
 func (s *WorkflowTestSuiteUnitTest) Test_SignalChildWorkflow() {
 	// This test will send signal from parent to child, and then child will send back signal to ack. No mock is needed.
 	signalName := "test-signal-name"
@@ -1792,3 +2394,257 @@ func (s *WorkflowTestSuiteUnitTest) Test_ContextMisuse() {
 	s.Error(env.GetWorkflowError())
 	s.Contains(env.GetWorkflowError().Error(), "block on coroutine which is already blocked")
 }
+
+func (s *WorkflowTestSuiteUnitTest) Test_RegisterWithEnvironment() {
+	activityFn := func(ctx context.Context, name string) (string, error) {
+		return "hello " + name, nil
+	}
+	workflowFn := func(ctx Context, name string) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var result string
+		err := ExecuteActivity(ctx, activityFn, name).Get(ctx, &result)
+		return result, err
+	}
+
+	env := s.NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(workflowFn, RegisterWorkflowOptions{Name: "env-workflow"})
+	env.RegisterActivityWithOptions(activityFn, RegisterActivityOptions{Name: "env-activity"})
+	var activityType string
+	env.SetOnActivityStartedListener(func(activityInfo *ActivityInfo, ctx context.Context, args encoded.Values) {
+		activityType = activityInfo.ActivityType.Name
+	})
+	env.ExecuteWorkflow(workflowFn, "env")
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("hello env", result)
+	s.Equal("env-activity", activityType)
+
+	// the registrations are not visible to other environments.
+	env = s.NewTestWorkflowEnvironment()
+	s.Panics(func() { env.ExecuteWorkflow("env-workflow", "env") })
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_ContextPropagation() {
+	tenantActivityFn := func(ctx context.Context) (string, error) {
+		tenant, _ := ctx.Value(testTenantContextKey{}).(string)
+		return tenant, nil
+	}
+	childWorkflowFn := func(ctx Context) (string, error) {
+		tenant, _ := ctx.Value(testTenantContextKey{}).(string)
+		return tenant, nil
+	}
+	workflowFn := func(ctx Context) (string, error) {
+		ctx = WithValue(ctx, testTenantContextKey{}, "tenant-a")
+
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var activityTenant string
+		if err := ExecuteActivity(ctx, tenantActivityFn).Get(ctx, &activityTenant); err != nil {
+			return "", err
+		}
+
+		ctx = WithLocalActivityOptions(ctx, s.localActivityOptions)
+		var localActivityTenant string
+		if err := ExecuteLocalActivity(ctx, tenantActivityFn).Get(ctx, &localActivityTenant); err != nil {
+			return "", err
+		}
+
+		ctx = WithChildWorkflowOptions(ctx, ChildWorkflowOptions{ExecutionStartToCloseTimeout: time.Minute})
+		var childTenant string
+		if err := ExecuteChildWorkflow(ctx, childWorkflowFn).Get(ctx, &childTenant); err != nil {
+			return "", err
+		}
+
+		return activityTenant + "," + localActivityTenant + "," + childTenant, nil
+	}
+
+	RegisterWorkflow(workflowFn)
+	RegisterWorkflow(childWorkflowFn)
+	RegisterActivity(tenantActivityFn)
+	env := s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{
+		ContextPropagators:   []ContextPropagator{&testTenantPropagator{}},
+		EnableHeaderEnvelope: true,
+	})
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("tenant-a,tenant-a,tenant-a", result)
+
+	// Without the header envelope only the local activity, which runs in the worker process, receives the header.
+	env = s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{ContextPropagators: []ContextPropagator{&testTenantPropagator{}}})
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal(",tenant-a,", result)
+}
+
+type testRecordingInterceptor struct {
+	calls []string
+}
+
+type testRecordingWorkflowInterceptor struct {
+	WorkflowInterceptorBase
+	recorder *testRecordingInterceptor
+}
+
+type testRecordingActivityInterceptor struct {
+	ActivityInterceptorBase
+	recorder *testRecordingInterceptor
+}
+
+func (r *testRecordingInterceptor) InterceptWorkflow(info *WorkflowInfo, next WorkflowInterceptor) WorkflowInterceptor {
+	return &testRecordingWorkflowInterceptor{WorkflowInterceptorBase{Next: next}, r}
+}
+
+func (r *testRecordingInterceptor) InterceptActivity(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor {
+	return &testRecordingActivityInterceptor{ActivityInterceptorBase{Next: next}, r}
+}
+
+func (i *testRecordingWorkflowInterceptor) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) (interface{}, error) {
+	i.recorder.calls = append(i.recorder.calls, "ExecuteWorkflow")
+	return i.Next.ExecuteWorkflow(ctx, workflowType, args...)
+}
+
+func (i *testRecordingWorkflowInterceptor) ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	i.recorder.calls = append(i.recorder.calls, "ExecuteActivity")
+	return i.Next.ExecuteActivity(ctx, activity, args...)
+}
+
+func (i *testRecordingActivityInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (interface{}, error) {
+	i.recorder.calls = append(i.recorder.calls, "Activity")
+	result, err := i.Next.ExecuteActivity(ctx, activityType, args...)
+	if s, ok := result.(string); ok {
+		result = s + " (intercepted)"
+	}
+	return result, err
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_WorkerInterceptor() {
+	greetActivityFn := func(ctx context.Context, name string) (string, error) {
+		return "hello " + name, nil
+	}
+	workflowFn := func(ctx Context, name string) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var greeting string
+		err := ExecuteActivity(ctx, greetActivityFn, name).Get(ctx, &greeting)
+		return greeting, err
+	}
+
+	RegisterWorkflow(workflowFn)
+	RegisterActivity(greetActivityFn)
+	recorder := &testRecordingInterceptor{}
+	env := s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{Interceptors: []WorkerInterceptor{recorder}})
+	env.ExecuteWorkflow(workflowFn, "cadence")
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("hello cadence (intercepted)", result)
+	s.Equal([]string{"ExecuteWorkflow", "ExecuteActivity", "Activity"}, recorder.calls)
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_Tracing() {
+	spanActivityFn := func(ctx context.Context) (string, error) {
+		span := opentracing.SpanFromContext(ctx)
+		if span == nil {
+			return "", errors.New("no span in activity context")
+		}
+		return span.(*mocktracer.MockSpan).OperationName, nil
+	}
+	workflowFn := func(ctx Context) (string, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var operation string
+		err := ExecuteActivity(ctx, spanActivityFn).Get(ctx, &operation)
+		return operation, err
+	}
+
+	RegisterWorkflow(workflowFn)
+	RegisterActivity(spanActivityFn)
+	tracer := mocktracer.New()
+	env := s.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(WorkerOptions{Tracer: tracer, EnableHeaderEnvelope: true})
+	// setting the tracer again must not report every span twice
+	env.SetWorkerOptions(WorkerOptions{Tracer: tracer})
+	startTime := env.Now()
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.True(strings.HasPrefix(result, "RunActivity:"))
+
+	s.Equal(3, len(tracer.FinishedSpans()))
+	spans := make(map[string]*mocktracer.MockSpan)
+	for _, span := range tracer.FinishedSpans() {
+		spans[strings.SplitN(span.OperationName, ":", 2)[0]] = span
+	}
+	s.Equal(3, len(spans))
+	s.Equal(0, spans["RunWorkflow"].ParentID)
+	s.Equal(0, spans["StartActivity"].ParentID)
+	s.Equal(spans["StartActivity"].SpanContext.SpanID, spans["RunActivity"].ParentID)
+	s.True(startTime.Equal(spans["RunWorkflow"].StartTime))
+	s.Equal(result, spans["RunActivity"].OperationName)
+}
+
+type testPaymentActivities struct {
+	fee int
+}
+
+func (a *testPaymentActivities) Charge(ctx context.Context, amount int) (int, error) {
+	return amount + a.fee, nil
+}
+
+func (a *testPaymentActivities) Refund(amount int) (int, error) {
+	return -amount, nil
+}
+
+// Describe is not an activity as it does not return an error.
+func (a *testPaymentActivities) Describe() string {
+	return "payments"
+}
+
+func (s *WorkflowTestSuiteUnitTest) Test_ActivityStruct() {
+	activities := &testPaymentActivities{fee: 3}
+	workflowFn := func(ctx Context) (int, error) {
+		ctx = WithActivityOptions(ctx, s.activityOptions)
+		var charged, refunded int
+		if err := ExecuteActivity(ctx, activities.Charge, 10).Get(ctx, &charged); err != nil {
+			return 0, err
+		}
+		if err := ExecuteActivity(ctx, "testPayment_Refund", 4).Get(ctx, &refunded); err != nil {
+			return 0, err
+		}
+		return charged + refunded, nil
+	}
+
+	RegisterWorkflow(workflowFn)
+	RegisterActivityWithOptions(activities, RegisterActivityOptions{Prefix: "testPayment_"})
+	env := s.NewTestWorkflowEnvironment()
+	var startedActivities []string
+	env.SetOnActivityStartedListener(func(activityInfo *ActivityInfo, ctx context.Context, args encoded.Values) {
+		startedActivities = append(startedActivities, activityInfo.ActivityType.Name)
+	})
+	env.ExecuteWorkflow(workflowFn)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result int
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal(9, result)
+	s.Equal([]string{"testPayment_Charge", "testPayment_Refund"}, startedActivities)
+
+	_, ok := getHostEnvironment().getActivity("testPayment_Describe")
+	s.False(ok)
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+type (
+	// Saga runs compensations for the steps of a workflow which already completed when a later step fails or the
+	// workflow is canceled. Every step registers the activity which compensates it with AddCompensation. Use NewSaga
+	// to create one.
+	Saga interface {
+		// AddCompensation registers activity to be executed with args by Compensate. The activity and args follow
+		// the same rules as for ExecuteActivity.
+		AddCompensation(activity interface{}, args ...interface{})
+
+		// Compensate executes the registered compensations, in the reverse order of registration unless
+		// SagaOptions.ParallelCompensation is set, and forgets them. The activities are executed in a context
+		// disconnected from ctx with NewDisconnectedContext, so they run even if ctx is canceled. The activity options
+		// of ctx are used. Compensate returns a *CompensationError if any compensation failed.
+		Compensate(ctx Context) error
+	}
+
+	// SagaOptions configures a Saga.
+	SagaOptions struct {
+		// ParallelCompensation - Whether the compensations are executed in parallel instead of one after the other
+		// in the reverse order of registration. All compensations are executed in that case.
+		// Optional: default false
+		ParallelCompensation bool
+
+		// ContinueWithError - Whether the remaining compensations are still executed after a compensation failed.
+		// Only used when the compensations are not executed in parallel.
+		// Optional: default false, the compensation stops at the first failure
+		ContinueWithError bool
+	}
+
+	sagaImpl struct {
+		options       SagaOptions
+		compensations []sagaCompensation
+	}
+
+	sagaCompensation struct {
+		activity interface{}
+		args     []interface{}
+	}
+)
+
+// NewSaga creates a new Saga instance.
+func NewSaga(options SagaOptions) Saga {
+	return &sagaImpl{options: options}
+}
+
+func (s *sagaImpl) AddCompensation(activity interface{}, args ...interface{}) {
+	s.compensations = append(s.compensations, sagaCompensation{activity: activity, args: args})
+}
+
+func (s *sagaImpl) Compensate(ctx Context) error {
+	compensations := s.compensations
+	s.compensations = nil
+	ctx, cancel := NewDisconnectedContext(ctx)
+	defer cancel()
+
+	var errs []error
+	if s.options.ParallelCompensation {
+		futures := make([]Future, 0, len(compensations))
+		for i := len(compensations) - 1; i >= 0; i-- {
+			futures = append(futures, ExecuteActivity(ctx, compensations[i].activity, compensations[i].args...))
+		}
+		for _, f := range futures {
+			if err := f.Get(ctx, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	} else {
+		for i := len(compensations) - 1; i >= 0; i-- {
+			err := ExecuteActivity(ctx, compensations[i].activity, compensations[i].args...).Get(ctx, nil)
+			if err != nil {
+				errs = append(errs, err)
+				if !s.options.ContinueWithError {
+					break
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &CompensationError{errors: errs}
+	}
+	return nil
+}
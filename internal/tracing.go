@@ -0,0 +1,317 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	// tracingHeaderKey is the header field which carries the span context encoded as a text map.
+	tracingHeaderKey = "cadence-tracing"
+
+	// spanContextKey holds the opentracing.SpanContext that outgoing calls are parented to.
+	spanContextKey contextKey = "spanContext"
+
+	tagWorkflowTypeTracing = "cadenceWorkflowType"
+	tagWorkflowIDTracing   = "cadenceWorkflowID"
+	tagRunIDTracing        = "cadenceRunID"
+	tagActivityTypeTracing = "cadenceActivityType"
+)
+
+type (
+	// tracingContextPropagator carries the span context through workflow and activity headers.
+	tracingContextPropagator struct {
+		tracer opentracing.Tracer
+	}
+
+	// tracingClientInterceptor starts a span for every call which starts or signals a workflow.
+	tracingClientInterceptor struct {
+		ClientOutboundInterceptorBase
+		tracer opentracing.Tracer
+	}
+
+	// tracingWorkerInterceptor creates the workflow and activity interceptors that report spans.
+	tracingWorkerInterceptor struct {
+		tracer opentracing.Tracer
+	}
+
+	// tracingWorkflowInterceptor reports a span for the workflow execution and for the activities and child workflows
+	// it starts. Spans are only reported when the workflow is not replaying, so every span is reported once. All the
+	// spans are children of the span context propagated to the workflow by its caller, which is the same whether the
+	// workflow is replayed or not.
+	tracingWorkflowInterceptor struct {
+		WorkflowInterceptorBase
+		tracer opentracing.Tracer
+		info   *WorkflowInfo
+	}
+
+	// tracingActivityInterceptor reports a span for the activity execution. The span is available to the activity
+	// through opentracing.SpanFromContext.
+	tracingActivityInterceptor struct {
+		ActivityInterceptorBase
+		tracer opentracing.Tracer
+		info   *ActivityInfo
+	}
+)
+
+var _ ContextPropagator = (*tracingContextPropagator)(nil)
+var _ ClientInterceptor = (*tracingClientInterceptor)(nil)
+var _ WorkerInterceptor = (*tracingWorkerInterceptor)(nil)
+
+// withTracingContextPropagator returns propagators with the tracing propagator added if tracer is set.
+func withTracingContextPropagator(tracer opentracing.Tracer, propagators []ContextPropagator) []ContextPropagator {
+	if tracer == nil {
+		return propagators
+	}
+	result := append([]ContextPropagator{}, propagators...)
+	return append(result, &tracingContextPropagator{tracer: tracer})
+}
+
+// withTracingClientInterceptor returns interceptors with the tracing interceptor added as the outermost one if
+// tracer is set.
+func withTracingClientInterceptor(tracer opentracing.Tracer, interceptors []ClientInterceptor) []ClientInterceptor {
+	if tracer == nil {
+		return interceptors
+	}
+	return append([]ClientInterceptor{&tracingClientInterceptor{tracer: tracer}}, interceptors...)
+}
+
+// withTracingWorkerInterceptor returns interceptors with the tracing interceptor added as the outermost one if
+// tracer is set.
+func withTracingWorkerInterceptor(tracer opentracing.Tracer, interceptors []WorkerInterceptor) []WorkerInterceptor {
+	if tracer == nil {
+		return interceptors
+	}
+	return append([]WorkerInterceptor{&tracingWorkerInterceptor{tracer: tracer}}, interceptors...)
+}
+
+func (t *tracingContextPropagator) Inject(ctx context.Context, writer HeaderWriter) error {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+	return t.injectSpanContext(span.Context(), writer)
+}
+
+func (t *tracingContextPropagator) Extract(ctx context.Context, reader HeaderReader) (context.Context, error) {
+	spanContext, err := t.extractSpanContext(reader)
+	if err != nil || spanContext == nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, spanContextKey, spanContext), nil
+}
+
+func (t *tracingContextPropagator) InjectFromWorkflow(ctx Context, writer HeaderWriter) error {
+	spanContext, ok := ctx.Value(spanContextKey).(opentracing.SpanContext)
+	if !ok {
+		return nil
+	}
+	return t.injectSpanContext(spanContext, writer)
+}
+
+func (t *tracingContextPropagator) ExtractToWorkflow(ctx Context, reader HeaderReader) (Context, error) {
+	spanContext, err := t.extractSpanContext(reader)
+	if err != nil || spanContext == nil {
+		return ctx, err
+	}
+	return WithValue(ctx, spanContextKey, spanContext), nil
+}
+
+func (t *tracingContextPropagator) injectSpanContext(spanContext opentracing.SpanContext, writer HeaderWriter) error {
+	carrier := opentracing.TextMapCarrier{}
+	if err := t.tracer.Inject(spanContext, opentracing.TextMap, carrier); err != nil {
+		return err
+	}
+	data, err := json.Marshal(carrier)
+	if err != nil {
+		return err
+	}
+	writer.Set(tracingHeaderKey, data)
+	return nil
+}
+
+func (t *tracingContextPropagator) extractSpanContext(reader HeaderReader) (opentracing.SpanContext, error) {
+	var spanContext opentracing.SpanContext
+	err := reader.ForEachKey(func(key string, value []byte) error {
+		if key != tracingHeaderKey {
+			return nil
+		}
+		carrier := opentracing.TextMapCarrier{}
+		if err := json.Unmarshal(value, &carrier); err != nil {
+			return err
+		}
+		var err error
+		spanContext, err = t.tracer.Extract(opentracing.TextMap, carrier)
+		return err
+	})
+	return spanContext, err
+}
+
+func (t *tracingClientInterceptor) InterceptClient(next ClientOutboundInterceptor) ClientOutboundInterceptor {
+	return &tracingClientInterceptor{ClientOutboundInterceptorBase: ClientOutboundInterceptorBase{Next: next}, tracer: t.tracer}
+}
+
+func (t *tracingClientInterceptor) StartWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (*WorkflowExecution, error) {
+	span, ctx := t.startSpan(ctx, "StartWorkflow", workflow, options.ID)
+	execution, err := t.Next.StartWorkflow(ctx, options, workflow, args...)
+	finishSpan(span, err)
+	return execution, err
+}
+
+func (t *tracingClientInterceptor) ExecuteWorkflow(ctx context.Context, options StartWorkflowOptions, workflow interface{}, args ...interface{}) (WorkflowRun, error) {
+	span, ctx := t.startSpan(ctx, "StartWorkflow", workflow, options.ID)
+	run, err := t.Next.ExecuteWorkflow(ctx, options, workflow, args...)
+	finishSpan(span, err)
+	return run, err
+}
+
+func (t *tracingClientInterceptor) SignalWorkflow(ctx context.Context, workflowID string, runID string, signalName string, arg interface{}) error {
+	span, ctx := t.startSpan(ctx, "SignalWorkflow", signalName, workflowID)
+	err := t.Next.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+	finishSpan(span, err)
+	return err
+}
+
+func (t *tracingClientInterceptor) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{},
+	options StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (*WorkflowExecution, error) {
+	span, ctx := t.startSpan(ctx, "SignalWithStartWorkflow", workflow, workflowID)
+	execution, err := t.Next.SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs...)
+	finishSpan(span, err)
+	return execution, err
+}
+
+func (t *tracingClientInterceptor) startSpan(ctx context.Context, operation string, target interface{}, workflowID string) (opentracing.Span, context.Context) {
+	var options []opentracing.StartSpanOption
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		options = append(options, opentracing.ChildOf(parent.Context()))
+	}
+	span := t.tracer.StartSpan(operation+":"+getTracingName(target, true, getHostEnvironment()), options...)
+	if workflowID != "" {
+		span.SetTag(tagWorkflowIDTracing, workflowID)
+	}
+	return span, opentracing.ContextWithSpan(ctx, span)
+}
+
+func (t *tracingWorkerInterceptor) InterceptWorkflow(info *WorkflowInfo, next WorkflowInterceptor) WorkflowInterceptor {
+	return &tracingWorkflowInterceptor{WorkflowInterceptorBase: WorkflowInterceptorBase{Next: next}, tracer: t.tracer, info: info}
+}
+
+func (t *tracingWorkerInterceptor) InterceptActivity(info *ActivityInfo, next ActivityInterceptor) ActivityInterceptor {
+	return &tracingActivityInterceptor{ActivityInterceptorBase: ActivityInterceptorBase{Next: next}, tracer: t.tracer, info: info}
+}
+
+func (t *tracingWorkflowInterceptor) ExecuteWorkflow(ctx Context, workflowType string, args ...interface{}) (interface{}, error) {
+	// The span is reported by the worker which completes the workflow, so that an execution which is evicted from
+	// the cache or moves to another worker is still reported once. It starts at the workflow time of the first
+	// decision, which is the same on every replay.
+	startTime := Now(ctx)
+	result, err := t.Next.ExecuteWorkflow(ctx, workflowType, args...)
+	if !IsReplaying(ctx) {
+		span := t.startSpan(ctx, "RunWorkflow:"+workflowType, opentracing.StartTime(startTime))
+		span.SetTag(tagWorkflowTypeTracing, workflowType)
+		span.SetTag(tagWorkflowIDTracing, t.info.WorkflowExecution.ID)
+		span.SetTag(tagRunIDTracing, t.info.WorkflowExecution.RunID)
+		finishSpan(span, err)
+	}
+	return result, err
+}
+
+func (t *tracingWorkflowInterceptor) ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	if IsReplaying(ctx) {
+		return t.Next.ExecuteActivity(ctx, activity, args...)
+	}
+	span := t.startSpan(ctx, "StartActivity:"+getTracingName(activity, false, getWorkflowEnvironment(ctx).GetRegistry()))
+	defer span.Finish()
+	return t.Next.ExecuteActivity(WithValue(ctx, spanContextKey, span.Context()), activity, args...)
+}
+
+func (t *tracingWorkflowInterceptor) ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	if IsReplaying(ctx) {
+		return t.Next.ExecuteLocalActivity(ctx, activity, args...)
+	}
+	span := t.startSpan(ctx, "StartLocalActivity:"+getTracingName(activity, false, getWorkflowEnvironment(ctx).GetRegistry()))
+	defer span.Finish()
+	return t.Next.ExecuteLocalActivity(WithValue(ctx, spanContextKey, span.Context()), activity, args...)
+}
+
+func (t *tracingWorkflowInterceptor) ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interface{}) ChildWorkflowFuture {
+	if IsReplaying(ctx) {
+		return t.Next.ExecuteChildWorkflow(ctx, childWorkflow, args...)
+	}
+	span := t.startSpan(ctx, "StartChildWorkflow:"+getTracingName(childWorkflow, true, getWorkflowEnvironment(ctx).GetRegistry()))
+	defer span.Finish()
+	return t.Next.ExecuteChildWorkflow(WithValue(ctx, spanContextKey, span.Context()), childWorkflow, args...)
+}
+
+func (t *tracingWorkflowInterceptor) startSpan(ctx Context, operation string, options ...opentracing.StartSpanOption) opentracing.Span {
+	if parent, ok := ctx.Value(spanContextKey).(opentracing.SpanContext); ok {
+		options = append(options, opentracing.ChildOf(parent))
+	}
+	return t.tracer.StartSpan(operation, options...)
+}
+
+func (t *tracingActivityInterceptor) ExecuteActivity(ctx context.Context, activityType string, args ...interface{}) (interface{}, error) {
+	var options []opentracing.StartSpanOption
+	if parent, ok := ctx.Value(spanContextKey).(opentracing.SpanContext); ok {
+		options = append(options, opentracing.ChildOf(parent))
+	}
+	span := t.tracer.StartSpan("RunActivity:"+activityType, options...)
+	span.SetTag(tagActivityTypeTracing, activityType)
+	span.SetTag(tagWorkflowIDTracing, t.info.WorkflowExecution.ID)
+	span.SetTag(tagRunIDTracing, t.info.WorkflowExecution.RunID)
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	result, err := t.Next.ExecuteActivity(ctx, activityType, args...)
+	finishSpan(span, err)
+	return result, err
+}
+
+func finishSpan(span opentracing.Span, err error) {
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("errorMessage", err.Error())
+	}
+	span.Finish()
+}
+
+// getTracingName returns the workflow or activity type name used in span names.
+func getTracingName(f interface{}, isWorkflow bool, registry *hostEnvImpl) string {
+	if name, ok := f.(string); ok {
+		return name
+	}
+	if getKind(reflect.TypeOf(f)) != reflect.Func {
+		return ""
+	}
+	if !isWorkflow {
+		return getActivityFunctionName(registry, f)
+	}
+	fnName := getFunctionName(f)
+	if alias, ok := registry.getWorkflowAlias(fnName); ok {
+		return alias
+	}
+	return fnName
+}
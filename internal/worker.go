@@ -21,6 +21,7 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"math"
@@ -28,6 +29,7 @@ import (
 
 	"encoding/json"
 	"github.com/golang/mock/gomock"
+	"github.com/opentracing/opentracing-go"
 	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 	"go.uber.org/cadence/.gen/go/cadence/workflowserviceclient"
@@ -40,6 +42,41 @@ import (
 )
 
 type (
+	// Registry registers workflows and activities with a single worker. The worker returned by NewWorker implements
+	// it:
+	//  w := worker.New(service, domain, taskList, worker.Options{})
+	//  w.(worker.Registry).RegisterWorkflow(MyWorkflow)
+	// The registrations of a worker replace the global ones instead of adding to them, separately for workflows and
+	// activities: a worker without workflows (activities) of its own runs every workflow (activity) registered with
+	// the global RegisterWorkflow (RegisterActivity), and a worker with at least one only runs its own. A worker which
+	// registers one workflow or activity must therefore register all the workflows or activities it runs, so that
+	// unrelated task lists hosted in one process stay isolated.
+	Registry interface {
+		// RegisterWorkflow registers the workflow with this worker only, it is not available to other workers of the
+		// process. Once a workflow is registered with the worker, the worker only runs the workflows registered with
+		// it. Otherwise it runs the workflows registered with the global RegisterWorkflow. Must be called before Start.
+		// A workflow function registered under an alias can still be started or executed as a child workflow by
+		// function, it is resolved to the alias as long as all the workers of the process register it under the
+		// same name.
+		// This method calls panic if the workflow doesn't comply with the expected format or is already registered
+		// with the worker.
+		RegisterWorkflow(w interface{})
+
+		// RegisterWorkflowWithOptions registers the workflow with this worker only, see RegisterWorkflow.
+		RegisterWorkflowWithOptions(w interface{}, options RegisterWorkflowOptions)
+
+		// RegisterActivity registers the activity with this worker only, it is not available to other workers of the
+		// process. Once an activity is registered with the worker, the worker only runs the activities registered with
+		// it. Otherwise it runs the activities registered with the global RegisterActivity. Must be called before
+		// Start.
+		// This method calls panic if the activity doesn't comply with the expected format or is already registered
+		// with the worker.
+		RegisterActivity(a interface{})
+
+		// RegisterActivityWithOptions registers the activity with this worker only, see RegisterActivity.
+		RegisterActivityWithOptions(a interface{}, options RegisterActivityOptions)
+	}
+
 	// Worker represents objects that can be started and stopped.
 	Worker interface {
 		// Start starts the worker in a non-blocking fashion
@@ -51,7 +88,8 @@ type (
 		Stop()
 	}
 
-	// WorkerOptions is used to configure a worker instance.
+	// WorkerOptions is used to configure a worker instance. The workflows and activities run by a worker are either
+	// registered with it, see Registry, or registered globally.
 	// The current timeout resolution implementation is in seconds and uses math.Ceil(d.Seconds()) as the duration. But is
 	// subjected to change in the future.
 	WorkerOptions struct {
@@ -156,9 +194,46 @@ type (
 		// default: NonDeterministicWorkflowPolicyBlockWorkflow, which just logs error but reply nothing back to server
 		NonDeterministicWorkflowPolicy NonDeterministicWorkflowPolicy
 
+		// Optional: Sets how decision worker deals with signals which were never received by the workflow when it
+		// completes.
+		// default: UnhandledSignalPolicyDrop, which logs the signal names and drops the signals
+		UnhandledSignalPolicy UnhandledSignalPolicy
+
 		// Optional: Sets DataConverter to customize serialization/deserialization of arguments in Cadence
 		// default: defaultDataConverter, an combination of thriftEncoder and jsonEncoder
 		DataConverter encoded.DataConverter
+
+		// Optional: Sets ContextPropagators that allows users to control the context information passed through a workflow
+		// Headers received by the worker are always extracted, but activities, child workflows and continued runs
+		// started by a workflow only receive the header when EnableHeaderEnvelope is set. Local activities always
+		// receive it.
+		// default: nil
+		ContextPropagators []ContextPropagator
+
+		// Optional: Sets whether the header written by ContextPropagators and the Tracer is sent along with the
+		// activities, child workflows and continued runs started by workflows of this worker.
+		// WARNING: the cadence service has no header field for these requests, so the header is carried in an
+		// envelope in front of the encoded input. This changes the input stored by the server: workers written in
+		// other languages, the CLI and tools reading the input from the history see the envelope as part of the
+		// input. Only enable it when every worker of the domain is a Go worker of a version which strips the envelope.
+		// default: false, no header is sent
+		EnableHeaderEnvelope bool
+
+		// Optional: Sets interceptors that wrap workflow and activity executions of this worker. The first interceptor
+		// in the list is the outermost one.
+		// default: nil
+		Interceptors []WorkerInterceptor
+
+		// Optional: Sets an opentracing Tracer. The worker reports a span for every workflow and activity execution
+		// and for every activity and child workflow started by a workflow. Spans are not reported while a workflow is
+		// replaying. The span of a workflow is reported when it completes, by the worker which completes it, and
+		// starts at the workflow time of its first decision; executions which are terminated or time out are not
+		// reported. The spans of the activities and child workflows started by a workflow are siblings of the span of
+		// the workflow, children of the span propagated by the caller of the workflow. The span of an activity is
+		// available to it through opentracing.SpanFromContext and is a child of the span which started the activity
+		// if EnableHeaderEnvelope is set.
+		// default: nil, no spans are reported
+		Tracer opentracing.Tracer
 	}
 )
 
@@ -181,6 +256,20 @@ const (
 	NonDeterministicWorkflowPolicyFailWorkflow
 )
 
+// UnhandledSignalPolicy is an enum for configuring how client's decision task handler deals with signals which are
+// still buffered in their signal channels when the workflow completes.
+type UnhandledSignalPolicy int
+
+const (
+	// UnhandledSignalPolicyDrop is the default policy for handling unhandled signals. The workflow completes, the
+	// names of the unhandled signals are logged and the UnhandledSignalsCounter metric is incremented.
+	UnhandledSignalPolicyDrop UnhandledSignalPolicy = iota
+	// UnhandledSignalPolicyFailDecision fails the decision task which completes the workflow instead of dropping the
+	// signals. The workflow stays open and its decision task is retried, which gives the chance to deploy a worker
+	// that handles the signals.
+	UnhandledSignalPolicyFailDecision
+)
+
 // NewWorker creates an instance of worker for managing workflow and activity executions.
 // service 	- thrift connection to the cadence server.
 // domain - the name of the cadence domain.
@@ -259,21 +348,41 @@ func ReplayWorkflowHistoryFromJSONFile(logger *zap.Logger, jsonfileName string)
 }
 
 func replayWorkflowHistory(logger *zap.Logger, service workflowserviceclient.Interface, domain string, history *shared.History) error {
-	taskList := "ReplayTaskList"
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	params := workerExecutionParameters{
+		TaskList: "ReplayTaskList",
+		Identity: "replayID",
+		Logger:   logger,
+	}
+	_, err := replayHistory(service, domain, history, params, getHostEnvironment())
+	return err
+}
+
+// replayHistory executes a single decision task for the history with the workflows of registry and returns the
+// response the decision task handler produced for it.
+func replayHistory(
+	service workflowserviceclient.Interface,
+	domain string,
+	history *shared.History,
+	params workerExecutionParameters,
+	registry *hostEnvImpl,
+) (interface{}, error) {
 	events := history.Events
 	if events == nil {
-		return errors.New("empty events")
+		return nil, errors.New("empty events")
 	}
 	if len(events) < 3 {
-		return errors.New("at least 3 events expected in the history")
+		return nil, errors.New("at least 3 events expected in the history")
 	}
 	first := events[0]
 	if first.GetEventType() != shared.EventTypeWorkflowExecutionStarted {
-		return errors.New("first event is not WorkflowExecutionStarted")
+		return nil, errors.New("first event is not WorkflowExecutionStarted")
 	}
 	attr := first.WorkflowExecutionStartedEventAttributes
 	if attr == nil {
-		return errors.New("corrupted WorkflowExecutionStarted")
+		return nil, errors.New("corrupted WorkflowExecutionStarted")
 	}
 	workflowType := attr.WorkflowType
 	execution := &shared.WorkflowExecution{
@@ -288,27 +397,19 @@ func replayWorkflowHistory(logger *zap.Logger, service workflowserviceclient.Int
 		History:                history,
 		PreviousStartedEventId: common.Int64Ptr(math.MaxInt64),
 	}
-	if logger == nil {
-		logger = zap.NewNop()
-	}
 
 	metricScope := tally.NoopScope
 	iterator := &historyIteratorImpl{
 		nextPageToken: task.NextPageToken,
 		execution:     task.WorkflowExecution,
-		domain:        "ReplayDomain",
+		domain:        domain,
 		service:       service,
 		metricsScope:  metricScope,
 		maxEventID:    task.GetStartedEventId(),
 	}
-	params := workerExecutionParameters{
-		TaskList: taskList,
-		Identity: "replayID",
-		Logger:   logger,
-	}
-	taskHandler := newWorkflowTaskHandler(domain, params, nil, getHostEnvironment())
-	_, _, err := taskHandler.ProcessWorkflowTask(task, iterator)
-	return err
+	taskHandler := newWorkflowTaskHandler(domain, params, nil, registry)
+	response, _, err := taskHandler.ProcessWorkflowTask(task, iterator)
+	return response, err
 }
 
 func extractHistoryFromFile(jsonfileName string) (*shared.History, error) {
@@ -321,7 +422,12 @@ func extractHistoryFromFile(jsonfileName string) (*shared.History, error) {
 	err = json.Unmarshal(raw, &deserializedEvents)
 
 	if err != nil {
-		return nil, err
+		// not a history downloaded with the cli, it may have been written by a HistoryWriter
+		reader, readerErr := NewHistoryReader(bytes.NewReader(raw))
+		if readerErr != nil {
+			return nil, err
+		}
+		return reader.ReadHistory()
 	}
 	history := &shared.History{Events: deserializedEvents}
 
@@ -180,6 +180,10 @@ type (
 		// RetryPolicy specify how to retry child workflow if error happens.
 		// Optional: default is no retry
 		RetryPolicy *RetryPolicy
+
+		// CronSchedule - Optional cron schedule for the child workflow, see StartWorkflowOptions.CronSchedule. Requires
+		// WorkerOptions.EnableHeaderEnvelope, starting the child workflow fails otherwise.
+		CronSchedule string
 	}
 
 	// ChildWorkflowPolicy defines child workflow behavior when parent workflow is terminated.
@@ -247,20 +251,23 @@ func NewChannel(ctx Context) Channel {
 // Name appears in stack traces that are blocked on this channel.
 func NewNamedChannel(ctx Context, name string) Channel {
 	env := getWorkflowEnvironment(ctx)
-	return &channelImpl{name: name, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger()}
+	return &channelImpl{name: name, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger(),
+		dispatcher: getDispatcher(ctx)}
 }
 
 // NewBufferedChannel create new buffered Channel instance
 func NewBufferedChannel(ctx Context, size int) Channel {
 	env := getWorkflowEnvironment(ctx)
-	return &channelImpl{size: size, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger()}
+	return &channelImpl{size: size, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger(),
+		dispatcher: getDispatcher(ctx)}
 }
 
 // NewNamedBufferedChannel create new BufferedChannel instance with a given human readable name.
 // Name appears in stack traces that are blocked on this Channel.
 func NewNamedBufferedChannel(ctx Context, name string, size int) Channel {
 	env := getWorkflowEnvironment(ctx)
-	return &channelImpl{name: name, size: size, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger()}
+	return &channelImpl{name: name, size: size, dataConverter: getDataConverterFromWorkflowContext(ctx), scope: env.GetMetricsScope(), logger: env.GetLogger(),
+		dispatcher: getDispatcher(ctx)}
 }
 
 // NewSelector creates a new Selector instance.
@@ -320,10 +327,25 @@ func NewFuture(ctx Context) (Future, Settable) {
 //
 // ExecuteActivity returns Future with activity result or failure.
 func ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	panicIfReadOnly(ctx, "ExecuteActivity")
+	if s := getCancellationScope(ctx); s != nil && s.waitForCancellation {
+		ctx = WithWaitForCancellation(ctx, true)
+	}
+	future := getWorkflowInterceptor(ctx).ExecuteActivity(ctx, activity, args...)
+	trackFuture(ctx, future)
+	return future
+}
+
+func (wc *workflowEnvironmentInterceptor) ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Future {
 	// Validate type and its arguments.
 	dataConverter := getDataConverterFromWorkflowContext(ctx)
 	future, settable := newDecodeFuture(ctx, activity)
-	activityType, input, err := getValidatedActivityFunction(activity, args, dataConverter)
+	activityType, input, err := getValidatedActivityFunction(activity, args, dataConverter, getWorkflowEnvironment(ctx).GetRegistry())
+	if err != nil {
+		settable.Set(nil, err)
+		return future
+	}
+	input, err = injectHeaderFromWorkflowContext(ctx, input)
 	if err != nil {
 		settable.Set(nil, err)
 		return future
@@ -377,10 +399,11 @@ func ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Fut
 // * Local activity is for short living activities (usually finishes within seconds).
 // * Local activity cannot heartbeat.
 //
-// Context can be used to pass the settings for this local activity.
-// For now there is only one setting for timeout to be set:
+// Context can be used to pass the settings for this local activity, the timeout of each attempt and an optional
+// retry policy:
 //  lao := LocalActivityOptions{
 // 	    ScheduleToCloseTimeout: 5 * time.Second,
+// 	    RetryPolicy: &RetryPolicy{InitialInterval: time.Second, MaximumAttempts: 5},
 // 	}
 //	ctx := WithLocalActivityOptions(ctx, lao)
 // The timeout here should be relative shorter than the DecisionTaskStartToCloseTimeout of the workflow. If you need a
@@ -400,6 +423,13 @@ func ExecuteActivity(ctx Context, activity interface{}, args ...interface{}) Fut
 //
 // ExecuteLocalActivity returns Future with local activity result or failure.
 func ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}) Future {
+	panicIfReadOnly(ctx, "ExecuteLocalActivity")
+	future := getWorkflowInterceptor(ctx).ExecuteLocalActivity(ctx, activity, args...)
+	trackFuture(ctx, future)
+	return future
+}
+
+func (wc *workflowEnvironmentInterceptor) ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}) Future {
 	future, settable := newDecodeFuture(ctx, activity)
 
 	if err := validateFunctionArgs(activity, args, false); err != nil {
@@ -411,6 +441,11 @@ func ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}
 		settable.Set(nil, err)
 		return future
 	}
+	header, err := workflowHeader(ctx, getWorkflowEnvironment(ctx).GetContextPropagators())
+	if err != nil {
+		settable.Set(nil, err)
+		return future
+	}
 
 	params := executeLocalActivityParams{
 		localActivityOptions: *options,
@@ -418,16 +453,38 @@ func ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}
 		InputArgs:            args,
 		WorkflowInfo:         GetWorkflowInfo(ctx),
 		DataConverter:        getDataConverterFromWorkflowContext(ctx),
+		Header:               header,
+		ScheduledTime:        Now(ctx),
 	}
 
+	scheduleLocalActivity(ctx, params, settable)
+	return future
+}
+
+// scheduleLocalActivity schedules a single attempt of the local activity and sets the settable with its result. When
+// the attempt fails with a backoff that is too long to be waited out by the worker, the next attempt is scheduled
+// after a workflow timer for that backoff fires.
+func scheduleLocalActivity(ctx Context, params executeLocalActivityParams, settable Settable) {
 	ctxDone, cancellable := ctx.Done().(*channelImpl)
 	cancellationCallback := &receiveCallback{}
-	la := getWorkflowEnvironment(ctx).ExecuteLocalActivity(params, func(r []byte, e error) {
-		settable.Set(r, e)
+	la := getWorkflowEnvironment(ctx).ExecuteLocalActivity(params, func(lar *localActivityResultWrapper) {
 		if cancellable {
-			// future is done, we don't need cancellation anymore
+			// attempt is done, we don't need cancellation anymore
 			ctxDone.removeReceiveCallback(cancellationCallback)
 		}
+		if lar.err == nil || lar.backoff <= 0 {
+			settable.Set(lar.result, lar.err)
+			return
+		}
+
+		Go(ctx, func(ctx Context) {
+			if err := Sleep(ctx, lar.backoff); err != nil {
+				settable.Set(nil, err)
+				return
+			}
+			params.Attempt = lar.attempt + 1
+			scheduleLocalActivity(ctx, params, settable)
+		})
 	})
 
 	if cancellable {
@@ -442,8 +499,6 @@ func ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}
 			cancellationCallback.fn(nil, more)
 		}
 	}
-
-	return future
 }
 
 // ExecuteChildWorkflow requests child workflow execution in the context of a workflow.
@@ -463,6 +518,17 @@ func ExecuteLocalActivity(ctx Context, activity interface{}, args ...interface{}
 // error CanceledError.
 // ExecuteChildWorkflow returns ChildWorkflowFuture.
 func ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interface{}) ChildWorkflowFuture {
+	panicIfReadOnly(ctx, "ExecuteChildWorkflow")
+	if s := getCancellationScope(ctx); s != nil && s.waitForCancellation {
+		ctx = setWorkflowEnvOptionsIfNotExist(ctx)
+		getWorkflowEnvOptions(ctx).waitForCancellation = true
+	}
+	future := getWorkflowInterceptor(ctx).ExecuteChildWorkflow(ctx, childWorkflow, args...)
+	trackFuture(ctx, future)
+	return future
+}
+
+func (wc *workflowEnvironmentInterceptor) ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interface{}) ChildWorkflowFuture {
 	mainFuture, mainSettable := newDecodeFuture(ctx, childWorkflow)
 	executionFuture, executionSettable := NewFuture(ctx)
 	result := &childWorkflowFutureImpl{
@@ -470,7 +536,13 @@ func ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interf
 		executionFuture:  executionFuture.(*futureImpl),
 	}
 	dc := getWorkflowEnvOptions(ctx).dataConverter
-	wfType, input, err := getValidatedWorkflowFunction(childWorkflow, args, dc)
+	wfType, input, err := getValidatedWorkflowFunction(childWorkflow, args, dc, getWorkflowEnvironment(ctx).GetRegistry())
+	if err != nil {
+		executionSettable.Set(nil, err)
+		mainSettable.Set(nil, err)
+		return result
+	}
+	input, err = injectHeaderFromWorkflowContext(ctx, input)
 	if err != nil {
 		executionSettable.Set(nil, err)
 		mainSettable.Set(nil, err)
@@ -483,6 +555,16 @@ func ExecuteChildWorkflow(ctx Context, childWorkflow interface{}, args ...interf
 		return result
 	}
 	options.dataConverter = dc
+	if options.cronSchedule != "" && !getWorkflowEnvironment(ctx).IsHeaderEnvelopeEnabled() {
+		err = errors.New("CronSchedule requires WorkerOptions.EnableHeaderEnvelope")
+	} else {
+		input, err = withCronSchedule(input, options.cronSchedule)
+	}
+	if err != nil {
+		executionSettable.Set(nil, err)
+		mainSettable.Set(nil, err)
+		return result
+	}
 
 	params := executeWorkflowParams{
 		workflowOptions: *options,
@@ -539,6 +621,9 @@ type WorkflowInfo struct {
 	TaskStartToCloseTimeoutSeconds      int32
 	Domain                              string
 	Attempt                             int32 // Attempt starts from 0 and increased by 1 for every retry if retry policy is specified.
+	CronSchedule                        string
+	CronScheduledTime                   time.Time // Fire time of the cron schedule this run belongs to.
+	CronLastFailure                     string    // Error message of the previous run of the cron schedule if it failed.
 }
 
 // GetWorkflowInfo extracts info of a current workflow from a context.
@@ -569,6 +654,13 @@ func Now(ctx Context) time.Time {
 // The current timer resolution implementation is in seconds and uses math.Ceil(d.Seconds()) as the duration. But is
 // subjected to change in the future.
 func NewTimer(ctx Context, d time.Duration) Future {
+	panicIfReadOnly(ctx, "NewTimer")
+	future := getWorkflowInterceptor(ctx).NewTimer(ctx, d)
+	trackFuture(ctx, future)
+	return future
+}
+
+func (wc *workflowEnvironmentInterceptor) NewTimer(ctx Context, d time.Duration) Future {
 	future, settable := NewFuture(ctx)
 	if d <= 0 {
 		settable.Set(true, nil)
@@ -614,6 +706,56 @@ func Sleep(ctx Context, d time.Duration) (err error) {
 	return
 }
 
+// Await blocks the calling coroutine until condition() returns true. The condition is evaluated again every time the
+// workflow makes progress, for example after a signal is received, an activity completes or another coroutine runs,
+// so it may read any state of the workflow. The condition must be deterministic, must not block and must not have
+// side effects.
+// Await returns nil once the condition is true, or it returns *CanceledError if the ctx is canceled first.
+func Await(ctx Context, condition func() bool) error {
+	state := getState(ctx)
+	defer state.unblocked()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if condition() {
+			return nil
+		}
+		state.yield("blocked on Await")
+	}
+}
+
+// AwaitWithTimeout blocks the calling coroutine until condition() returns true or the timeout expires. It has the same
+// semantic as Await, the timeout is a durable timer created with NewTimer which is canceled if the condition becomes
+// true first. No timer is created if the condition is already true when AwaitWithTimeout is called.
+// AwaitWithTimeout returns true if the condition was met and false if the timeout expired. It returns *CanceledError
+// if the ctx is canceled first.
+func AwaitWithTimeout(ctx Context, timeout time.Duration, condition func() bool) (ok bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if condition() {
+		return true, nil
+	}
+	state := getState(ctx)
+	defer state.unblocked()
+	timerCtx, cancelTimer := WithCancel(ctx)
+	defer cancelTimer()
+	timer := NewTimer(timerCtx, timeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if condition() {
+			return true, nil
+		}
+		if timer.IsReady() {
+			return false, nil
+		}
+		state.yield("blocked on AwaitWithTimeout")
+	}
+}
+
 // RequestCancelExternalWorkflow can be used to request cancellation of an external workflow.
 // Input workflowID is the workflow ID of target workflow.
 // Input runID indicates the instance of a workflow. Input runID is optional (default is ""). When runID is not specified,
@@ -623,6 +765,11 @@ func Sleep(ctx Context, d time.Duration) (err error) {
 //	ctx := WithWorkflowDomain(ctx, "domain-name")
 // RequestCancelExternalWorkflow return Future with failure or empty success result.
 func RequestCancelExternalWorkflow(ctx Context, workflowID, runID string) Future {
+	panicIfReadOnly(ctx, "RequestCancelExternalWorkflow")
+	return getWorkflowInterceptor(ctx).RequestCancelExternalWorkflow(ctx, workflowID, runID)
+}
+
+func (wc *workflowEnvironmentInterceptor) RequestCancelExternalWorkflow(ctx Context, workflowID, runID string) Future {
 	ctx1 := setWorkflowEnvOptionsIfNotExist(ctx)
 	options := getWorkflowEnvOptions(ctx1)
 	future, settable := NewFuture(ctx1)
@@ -660,6 +807,11 @@ func RequestCancelExternalWorkflow(ctx Context, workflowID, runID string) Future
 //	ctx := WithWorkflowDomain(ctx, "domain-name")
 // SignalExternalWorkflow return Future with failure or empty success result.
 func SignalExternalWorkflow(ctx Context, workflowID, runID, signalName string, arg interface{}) Future {
+	panicIfReadOnly(ctx, "SignalExternalWorkflow")
+	return getWorkflowInterceptor(ctx).SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (wc *workflowEnvironmentInterceptor) SignalExternalWorkflow(ctx Context, workflowID, runID, signalName string, arg interface{}) Future {
 	childWorkflowOnly := false // this means we are not limited to child workflow
 	return signalExternalWorkflow(ctx, workflowID, runID, signalName, arg, childWorkflowOnly)
 }
@@ -717,6 +869,7 @@ func WithChildWorkflowOptions(ctx Context, cwo ChildWorkflowOptions) Context {
 	wfOptions.waitForCancellation = cwo.WaitForCancellation
 	wfOptions.workflowIDReusePolicy = cwo.WorkflowIDReusePolicy
 	wfOptions.retryPolicy = convertRetryPolicy(cwo.RetryPolicy)
+	wfOptions.cronSchedule = cwo.CronSchedule
 
 	return ctx1
 }
@@ -777,11 +930,37 @@ func WithDataConverter(ctx Context, dc encoded.DataConverter) Context {
 	return ctx1
 }
 
-// GetSignalChannel returns channel corresponding to the signal name.
+// GetSignalChannel returns channel corresponding to the signal name. Receiving from the channel yields the signal
+// argument only, use SetSignalHandler to receive the context propagated along with a signal.
 func GetSignalChannel(ctx Context, signalName string) Channel {
 	return getWorkflowEnvOptions(ctx).getSignalChannel(ctx, signalName)
 }
 
+// SetSignalHandler sets the handler which is called for every signal with the given name. The handler must be a
+// function which accepts a workflow.Context and optionally one serializable signal argument, and returns nothing:
+//	func(ctx workflow.Context)
+//	func(ctx workflow.Context, arg MySignalArg)
+// Every signal is handled in its own coroutine, started in the order the signals are received, so a handler may
+// block, for example to execute an activity. Signals received before the handler is set are handled first. A signal
+// whose argument cannot be decoded is logged and dropped. The header sent along with a signal is extracted by the
+// ContextPropagators of the worker into the context passed to the handler.
+// Once the handler is set the signals are no longer sent to the signal channel of signalName. SetSignalHandler
+// returns an error if the handler is not a valid function or if a handler is already set for signalName.
+// Example:
+//  func MyWorkflow(ctx workflow.Context) error {
+//    var approvals []string
+//    err := workflow.SetSignalHandler(ctx, "approve", func(ctx workflow.Context, approver string) {
+//      approvals = append(approvals, approver)
+//    })
+//    if err != nil {
+//      return err
+//    }
+//    return workflow.Await(ctx, func() bool { return len(approvals) >= 2 })
+//  }
+func SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	return setSignalHandler(ctx, signalName, handler)
+}
+
 func newEncodedValue(value []byte, dc encoded.DataConverter) encoded.Value {
 	if dc == nil {
 		dc = getDefaultDataConverter()
@@ -839,6 +1018,11 @@ func (b EncodedValue) HasValue() bool {
 //         ....
 //  }
 func SideEffect(ctx Context, f func(ctx Context) interface{}) encoded.Value {
+	panicIfReadOnly(ctx, "SideEffect")
+	return getWorkflowInterceptor(ctx).SideEffect(ctx, f)
+}
+
+func (wc *workflowEnvironmentInterceptor) SideEffect(ctx Context, f func(ctx Context) interface{}) encoded.Value {
 	dc := getDataConverterFromWorkflowContext(ctx)
 	future, settable := NewFuture(ctx)
 	wrapperFunc := func() ([]byte, error) {
@@ -872,6 +1056,7 @@ func SideEffect(ctx Context, f func(ctx Context) interface{}) encoded.Value {
 //
 // One good use case of MutableSideEffect() is to access dynamically changing config without breaking determinism.
 func MutableSideEffect(ctx Context, id string, f func(ctx Context) interface{}, equals func(a, b interface{}) bool) encoded.Value {
+	panicIfReadOnly(ctx, "MutableSideEffect")
 	wrapperFunc := func() interface{} {
 		return f(ctx)
 	}
@@ -939,6 +1124,7 @@ const DefaultVersion Version = -1
 //    err = workflow.ExecuteActivity(ctx, qux, data).Get(ctx, nil)
 //  }
 func GetVersion(ctx Context, changeID string, minSupported, maxSupported Version) Version {
+	panicIfReadOnly(ctx, "GetVersion")
 	return getWorkflowEnvironment(ctx).GetVersion(changeID, minSupported, maxSupported)
 }
 
@@ -951,7 +1137,10 @@ func GetVersion(ctx Context, changeID string, minSupported, maxSupported Version
 // The query handler will be invoked out of the context of the workflow, meaning that the handler code must not use cadence
 // context to do things like workflow.NewChannel(), workflow.Go() or to call any workflow blocking functions like
 // Channel.Get() or Future.Get(). Trying to do so in query handler code will fail the query and client will receive
-// QueryFailedError.
+// QueryFailedError. The query handler must not change the state of the workflow either, calls like ExecuteActivity(),
+// NewTimer(), SideEffect() or Channel.Send() panic while a query handler runs and fail the query.
+// The query types a workflow supports and the argument types of their handlers are returned by the built-in
+// QueryTypeQueryTypes query.
 // Example of workflow code that support query type "current_state":
 //  func MyWorkflow(ctx workflow.Context, input string) error {
 //    currentState := "started" // this could be any serializable struct
@@ -987,6 +1176,12 @@ func SetQueryHandler(ctx Context, queryType string, handler interface{}) error {
 	return setQueryHandler(ctx, queryType, handler)
 }
 
+// RemoveQueryHandler removes the query handler set for queryType with SetQueryHandler. Queries of queryType fail
+// afterwards as unknown query types. Removing a query type which has no handler does nothing.
+func RemoveQueryHandler(ctx Context, queryType string) {
+	removeQueryHandler(ctx, queryType)
+}
+
 // IsReplaying returns whether the current workflow code is replaying.
 //
 // Warning! Never make decisions, like schedule activity/childWorkflow/timer or send/wait on future/channel, based on
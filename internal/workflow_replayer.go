@@ -0,0 +1,270 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	"go.uber.org/cadence/.gen/go/cadence/workflowservicetest"
+	"go.uber.org/cadence/.gen/go/shared"
+	"go.uber.org/cadence/encoded"
+	"go.uber.org/zap"
+)
+
+type (
+	// WorkflowReplayer replays saved workflow histories against the workflows registered on it, to verify that a
+	// change of the workflow code is compatible with the executions already recorded. Workflows and activities
+	// registered on the replayer are only visible to it, the ones registered globally are used as a fallback.
+	WorkflowReplayer struct {
+		registry *hostEnvImpl
+		options  WorkflowReplayerOptions
+	}
+
+	// WorkflowReplayerOptions are optional parameters of a WorkflowReplayer. They should match the WorkerOptions of
+	// the workers that ran the replayed workflows.
+	WorkflowReplayerOptions struct {
+		// Optional: Logger of the replayed workflows.
+		// default: no logging
+		Logger *zap.Logger
+
+		// Optional: DataConverter the replayed workflows were executed with.
+		// default: JSON data converter
+		DataConverter encoded.DataConverter
+
+		// Optional: ContextPropagators the replayed workflows were executed with.
+		ContextPropagators []ContextPropagator
+
+		// Optional: Interceptors the replayed workflows were executed with.
+		Interceptors []WorkerInterceptor
+	}
+
+	// ReplayHistoryIterator iterates over workflow histories to replay.
+	ReplayHistoryIterator interface {
+		HasNext() bool
+		// Next returns the next history and the name identifying it in the WorkflowReplayReport.
+		Next() (name string, history *shared.History, err error)
+	}
+
+	// WorkflowReplayResult is the outcome of replaying a single history.
+	WorkflowReplayResult struct {
+		// Name identifies the history, it is the file name for histories read from files.
+		Name string
+
+		// WorkflowType of the replayed workflow.
+		WorkflowType string
+
+		// Err is nil if the history replayed successfully.
+		Err error
+
+		// MismatchEventID is the ID of the first history event which does not match the decisions produced by the
+		// replay, 0 if no mismatch was found or the replay produced a decision past the end of the history.
+		MismatchEventID int64
+
+		// ExpectedEvent is the mismatching history event, formatted by util.HistoryEventToString.
+		ExpectedEvent string
+
+		// ProducedDecision is the decision the replay produced instead, formatted by util.DecisionToString.
+		ProducedDecision string
+
+		// StackTrace of the workflow at the time the replay failed.
+		StackTrace string
+	}
+
+	// WorkflowReplayReport holds the results of replaying a set of histories, in the order they were replayed.
+	WorkflowReplayReport struct {
+		Results []*WorkflowReplayResult
+	}
+)
+
+// NewWorkflowReplayer creates a WorkflowReplayer.
+func NewWorkflowReplayer(options WorkflowReplayerOptions) *WorkflowReplayer {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	if options.DataConverter == nil {
+		options.DataConverter = getDefaultDataConverter()
+	}
+	return &WorkflowReplayer{
+		registry: newWorkerHostEnvironment(),
+		options:  options,
+	}
+}
+
+// RegisterWorkflow registers a workflow function with the replayer, see RegisterWorkflow.
+func (r *WorkflowReplayer) RegisterWorkflow(w interface{}) {
+	r.RegisterWorkflowWithOptions(w, RegisterWorkflowOptions{})
+}
+
+// RegisterWorkflowWithOptions registers a workflow function with the replayer, see RegisterWorkflowWithOptions.
+func (r *WorkflowReplayer) RegisterWorkflowWithOptions(w interface{}, options RegisterWorkflowOptions) {
+	if err := r.registry.RegisterWorkflowWithOptions(w, options); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterActivity registers an activity with the replayer, see RegisterActivity. Activities are never executed
+// on replay, registering them is only needed if the workflows schedule them under a name set on registration.
+func (r *WorkflowReplayer) RegisterActivity(a interface{}) {
+	r.RegisterActivityWithOptions(a, RegisterActivityOptions{})
+}
+
+// RegisterActivityWithOptions registers an activity with the replayer, see RegisterActivityWithOptions.
+func (r *WorkflowReplayer) RegisterActivityWithOptions(a interface{}, options RegisterActivityOptions) {
+	if err := r.registry.RegisterActivityWithOptions(a, options); err != nil {
+		panic(err)
+	}
+}
+
+// ReplayWorkflowHistory replays a single history. The name identifies the history in the result.
+func (r *WorkflowReplayer) ReplayWorkflowHistory(name string, history *shared.History) *WorkflowReplayResult {
+	result := &WorkflowReplayResult{Name: name}
+	if history != nil && len(history.Events) > 0 {
+		result.WorkflowType = history.Events[0].GetWorkflowExecutionStartedEventAttributes().GetWorkflowType().GetName()
+	} else {
+		history = &shared.History{}
+	}
+
+	controller := gomock.NewController(r.options.Logger.Sugar())
+	service := workflowservicetest.NewMockClient(controller)
+	params := workerExecutionParameters{
+		TaskList:               "ReplayTaskList",
+		Identity:               "replayID",
+		Logger:                 r.options.Logger,
+		DataConverter:          r.options.DataConverter,
+		ContextPropagators:     r.options.ContextPropagators,
+		WorkerInterceptors:     r.options.Interceptors,
+		DisableStickyExecution: true,
+	}
+	response, err := replayHistory(service, "ReplayDomain", history, params, r.registry)
+	if err != nil {
+		result.Err = err
+		if nde, ok := err.(*nondeterministicError); ok {
+			result.MismatchEventID = nde.eventID
+			result.ExpectedEvent = nde.expected
+			result.ProducedDecision = nde.produced
+			result.StackTrace = nde.stackTrace
+		}
+		return result
+	}
+	if failed, ok := response.(*shared.RespondDecisionTaskFailedRequest); ok {
+		// the decision task only fails on replay if the workflow code panicked.
+		result.Err = constructError(errReasonPanic, failed.Details, nil)
+		if panicErr, ok := result.Err.(*PanicError); ok {
+			result.StackTrace = panicErr.StackTrace()
+		}
+	}
+	return result
+}
+
+// ReplayWorkflowHistoryFromJSONFile replays a history file downloaded with the cli:
+// cadence workflow showid <workflow_id> -of <output_filename>
+// or written by a HistoryWriter.
+func (r *WorkflowReplayer) ReplayWorkflowHistoryFromJSONFile(jsonfileName string) *WorkflowReplayResult {
+	history, err := extractHistoryFromFile(jsonfileName)
+	if err != nil {
+		return &WorkflowReplayResult{Name: jsonfileName, Err: err}
+	}
+	return r.ReplayWorkflowHistory(jsonfileName, history)
+}
+
+// ReplayWorkflowHistoriesFromDirectory replays all the .json history files of a directory, in file name order.
+// The returned error is only set if the directory cannot be read, replay failures are reported per history.
+func (r *WorkflowReplayer) ReplayWorkflowHistoriesFromDirectory(dir string) (*WorkflowReplayReport, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	report := &WorkflowReplayReport{}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		report.Results = append(report.Results, r.ReplayWorkflowHistoryFromJSONFile(filepath.Join(dir, file.Name())))
+	}
+	return report, nil
+}
+
+// ReplayWorkflowHistories replays all the histories of the iterator. The returned error is only set if the iterator
+// fails, the report then holds the results of the histories replayed so far.
+func (r *WorkflowReplayer) ReplayWorkflowHistories(iterator ReplayHistoryIterator) (*WorkflowReplayReport, error) {
+	report := &WorkflowReplayReport{}
+	for iterator.HasNext() {
+		name, history, err := iterator.Next()
+		if err != nil {
+			return report, err
+		}
+		report.Results = append(report.Results, r.ReplayWorkflowHistory(name, history))
+	}
+	return report, nil
+}
+
+// Failures returns the results of the histories which failed to replay.
+func (r *WorkflowReplayReport) Failures() []*WorkflowReplayResult {
+	var failures []*WorkflowReplayResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Err returns an error describing all the failed histories, or nil if all of them replayed successfully.
+func (r *WorkflowReplayReport) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d of %d workflow histories failed to replay", len(failures), len(r.Results))
+	for _, failure := range failures {
+		buf.WriteString("\n")
+		buf.WriteString(failure.String())
+	}
+	return fmt.Errorf("%s", buf.String())
+}
+
+// String returns a human readable description of the result.
+func (r *WorkflowReplayResult) String() string {
+	if r.Err == nil {
+		return fmt.Sprintf("%s (%s): ok", r.Name, r.WorkflowType)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s (%s): %v", r.Name, r.WorkflowType, r.Err)
+	if r.MismatchEventID > 0 {
+		fmt.Fprintf(&buf, "\n\tfirst mismatching event ID: %d", r.MismatchEventID)
+	}
+	if r.ExpectedEvent != "" {
+		fmt.Fprintf(&buf, "\n\texpected: %s", r.ExpectedEvent)
+	}
+	if r.ProducedDecision != "" {
+		fmt.Fprintf(&buf, "\n\tproduced: %s", r.ProducedDecision)
+	}
+	if r.StackTrace != "" {
+		fmt.Fprintf(&buf, "\n\tstack trace:\n\t\t%s", strings.Replace(strings.TrimSpace(r.StackTrace), "\n", "\n\t\t", -1))
+	}
+	return buf.String()
+}
@@ -138,6 +138,20 @@ func (s *WorkflowTestSuite) SetMetricsScope(scope tally.Scope) {
 	s.scope = scope
 }
 
+// RegisterActivity registers the activity with this TestActivityEnvironment only, the same way it is registered with
+// a worker: once an activity is registered with the environment, the activities registered with the global
+// RegisterActivity are no longer available to it. See worker.Registry.
+func (t *TestActivityEnvironment) RegisterActivity(a interface{}) {
+	t.RegisterActivityWithOptions(a, RegisterActivityOptions{})
+}
+
+// RegisterActivityWithOptions registers the activity with this TestActivityEnvironment only, see RegisterActivity.
+func (t *TestActivityEnvironment) RegisterActivityWithOptions(a interface{}, options RegisterActivityOptions) {
+	if err := t.impl.registry.RegisterActivityWithOptions(a, options); err != nil {
+		panic(err)
+	}
+}
+
 // ExecuteActivity executes an activity. The tested activity will be executed synchronously in the calling goroutinue.
 // Caller should use encoded.Value.Get() to extract strong typed result value.
 func (t *TestActivityEnvironment) ExecuteActivity(activityFn interface{}, args ...interface{}) (encoded.Value, error) {
@@ -151,13 +165,51 @@ func (t *TestActivityEnvironment) ExecuteLocalActivity(activityFn interface{}, a
 }
 
 // SetWorkerOptions sets the WorkerOptions that will be use by TestActivityEnvironment. TestActivityEnvironment will
-// use options of Identity, MetricsScope and BackgroundActivityContext on the WorkerOptions. Other options are ignored.
+// use options of Identity, MetricsScope, BackgroundActivityContext, DataConverter and ContextPropagators on the
+// WorkerOptions. Other options are ignored.
 // Note: WorkerOptions is defined in internal package, use public type worker.Options instead.
 func (t *TestActivityEnvironment) SetWorkerOptions(options WorkerOptions) *TestActivityEnvironment {
 	t.impl.setWorkerOptions(options)
 	return t
 }
 
+// RegisterWorkflow registers the workflow with this TestWorkflowEnvironment only, the same way it is registered with
+// a worker: once a workflow is registered with the environment, the workflows registered with the global
+// RegisterWorkflow are no longer available to it. See worker.Registry.
+func (t *TestWorkflowEnvironment) RegisterWorkflow(w interface{}) {
+	t.RegisterWorkflowWithOptions(w, RegisterWorkflowOptions{})
+}
+
+// RegisterWorkflowWithOptions registers the workflow with this TestWorkflowEnvironment only, see RegisterWorkflow.
+func (t *TestWorkflowEnvironment) RegisterWorkflowWithOptions(w interface{}, options RegisterWorkflowOptions) {
+	if err := t.impl.registry.RegisterWorkflowWithOptions(w, options); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterActivity registers the activity with this TestWorkflowEnvironment only, the same way it is registered with
+// a worker: once an activity is registered with the environment, the activities registered with the global
+// RegisterActivity are no longer available to it. See worker.Registry.
+func (t *TestWorkflowEnvironment) RegisterActivity(a interface{}) {
+	t.RegisterActivityWithOptions(a, RegisterActivityOptions{})
+}
+
+// RegisterActivityWithOptions registers the activity with this TestWorkflowEnvironment only, see RegisterActivity.
+func (t *TestWorkflowEnvironment) RegisterActivityWithOptions(a interface{}, options RegisterActivityOptions) {
+	if err := t.impl.registry.RegisterActivityWithOptions(a, options); err != nil {
+		panic(err)
+	}
+}
+
+// SetStartWorkflowOptions sets the StartWorkflowOptions for the workflow under test. TestWorkflowEnvironment will use
+// options of TaskList, ExecutionStartToCloseTimeout, DecisionTaskStartToCloseTimeout and CronSchedule. Other options
+// are ignored. A cron workflow waits for its next fire time on the mock clock, runs once and then completes with
+// ContinueAsNewError.
+func (t *TestWorkflowEnvironment) SetStartWorkflowOptions(options StartWorkflowOptions) *TestWorkflowEnvironment {
+	t.impl.setStartWorkflowOptions(options)
+	return t
+}
+
 // SetTestTimeout sets the wall clock timeout for this activity test run. When test timeout happen, it means activity is
 // taking too long.
 func (t *TestActivityEnvironment) SetTestTimeout(idleTimeout time.Duration) *TestActivityEnvironment {
@@ -193,11 +245,7 @@ func (t *TestWorkflowEnvironment) OnActivity(activity interface{}, args ...inter
 		if err := validateFnFormat(fnType, false); err != nil {
 			panic(err)
 		}
-		fnName := getFunctionName(activity)
-		if alias, ok := getHostEnvironment().getActivityAlias(fnName); ok {
-			fnName = alias
-		}
-		call = t.Mock.On(fnName, args...)
+		call = t.Mock.On(getActivityFunctionName(t.impl.registry, activity), args...)
 
 	case reflect.String:
 		call = t.Mock.On(activity.(string), args...)
@@ -237,7 +285,7 @@ func (t *TestWorkflowEnvironment) OnWorkflow(workflow interface{}, args ...inter
 			panic(err)
 		}
 		fnName := getFunctionName(workflow)
-		if alias, ok := getHostEnvironment().getWorkflowAlias(fnName); ok {
+		if alias, ok := t.impl.registry.getWorkflowAlias(fnName); ok {
 			fnName = alias
 		}
 		call = t.Mock.On(fnName, args...)
@@ -361,7 +409,8 @@ func (t *TestWorkflowEnvironment) Now() time.Time {
 }
 
 // SetWorkerOptions sets the WorkerOptions for TestWorkflowEnvironment. TestWorkflowEnvironment will use options set by
-// use options of Identity, MetricsScope and BackgroundActivityContext on the WorkerOptions. Other options are ignored.
+// use options of Identity, MetricsScope, BackgroundActivityContext, DataConverter and ContextPropagators on the
+// WorkerOptions. Other options are ignored.
 // Note: WorkerOptions is defined in internal package, use public type worker.Options instead.
 func (t *TestWorkflowEnvironment) SetWorkerOptions(options WorkerOptions) *TestWorkflowEnvironment {
 	t.impl.setWorkerOptions(options)
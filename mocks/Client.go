@@ -36,6 +36,75 @@ type Client struct {
 	mock.Mock
 }
 
+// BatchCancel provides a mock function with given fields: ctx, filter, options
+func (_m *Client) BatchCancel(ctx context.Context, filter client.ListWorkflowsFilter, options client.BatchOptions) (client.BatchOperation, error) {
+	ret := _m.Called(ctx, filter, options)
+
+	var r0 client.BatchOperation
+	if rf, ok := ret.Get(0).(func(context.Context, client.ListWorkflowsFilter, client.BatchOptions) client.BatchOperation); ok {
+		r0 = rf(ctx, filter, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.BatchOperation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, client.ListWorkflowsFilter, client.BatchOptions) error); ok {
+		r1 = rf(ctx, filter, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchSignal provides a mock function with given fields: ctx, filter, signalName, arg, options
+func (_m *Client) BatchSignal(ctx context.Context, filter client.ListWorkflowsFilter, signalName string, arg interface{}, options client.BatchOptions) (client.BatchOperation, error) {
+	ret := _m.Called(ctx, filter, signalName, arg, options)
+
+	var r0 client.BatchOperation
+	if rf, ok := ret.Get(0).(func(context.Context, client.ListWorkflowsFilter, string, interface{}, client.BatchOptions) client.BatchOperation); ok {
+		r0 = rf(ctx, filter, signalName, arg, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.BatchOperation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, client.ListWorkflowsFilter, string, interface{}, client.BatchOptions) error); ok {
+		r1 = rf(ctx, filter, signalName, arg, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchTerminate provides a mock function with given fields: ctx, filter, reason, details, options
+func (_m *Client) BatchTerminate(ctx context.Context, filter client.ListWorkflowsFilter, reason string, details []byte, options client.BatchOptions) (client.BatchOperation, error) {
+	ret := _m.Called(ctx, filter, reason, details, options)
+
+	var r0 client.BatchOperation
+	if rf, ok := ret.Get(0).(func(context.Context, client.ListWorkflowsFilter, string, []byte, client.BatchOptions) client.BatchOperation); ok {
+		r0 = rf(ctx, filter, reason, details, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.BatchOperation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, client.ListWorkflowsFilter, string, []byte, client.BatchOptions) error); ok {
+		r1 = rf(ctx, filter, reason, details, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CancelWorkflow provides a mock function with given fields: ctx, workflowID, runID
 func (_m *Client) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
 	ret := _m.Called(ctx, workflowID, runID)
@@ -101,6 +170,52 @@ func (_m *Client) DescribeTaskList(ctx context.Context, tasklist string, tasklis
 	return r0, r1
 }
 
+// DescribeWorkflow provides a mock function with given fields: ctx, workflowID, runID
+func (_m *Client) DescribeWorkflow(ctx context.Context, workflowID string, runID string) (*client.WorkflowExecutionDescription, error) {
+	ret := _m.Called(ctx, workflowID, runID)
+
+	var r0 *client.WorkflowExecutionDescription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *client.WorkflowExecutionDescription); ok {
+		r0 = rf(ctx, workflowID, runID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.WorkflowExecutionDescription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, workflowID, runID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DescribeWorkflowWithOptions provides a mock function with given fields: ctx, workflowID, runID, options
+func (_m *Client) DescribeWorkflowWithOptions(ctx context.Context, workflowID string, runID string, options client.DescribeWorkflowOptions) (*client.WorkflowExecutionDescription, error) {
+	ret := _m.Called(ctx, workflowID, runID, options)
+
+	var r0 *client.WorkflowExecutionDescription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, client.DescribeWorkflowOptions) *client.WorkflowExecutionDescription); ok {
+		r0 = rf(ctx, workflowID, runID, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.WorkflowExecutionDescription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, client.DescribeWorkflowOptions) error); ok {
+		r1 = rf(ctx, workflowID, runID, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DescribeWorkflowExecution provides a mock function with given fields: ctx, workflowID, runID
 func (_m *Client) DescribeWorkflowExecution(ctx context.Context, workflowID string, runID string) (*shared.DescribeWorkflowExecutionResponse, error) {
 	ret := _m.Called(ctx, workflowID, runID)
@@ -150,6 +265,22 @@ func (_m *Client) ExecuteWorkflow(ctx context.Context, options client.StartWorkf
 	return r0, r1
 }
 
+// GetWorkflow provides a mock function with given fields: ctx, workflowID, runID
+func (_m *Client) GetWorkflow(ctx context.Context, workflowID string, runID string) client.WorkflowRun {
+	ret := _m.Called(ctx, workflowID, runID)
+
+	var r0 client.WorkflowRun
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) client.WorkflowRun); ok {
+		r0 = rf(ctx, workflowID, runID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.WorkflowRun)
+		}
+	}
+
+	return r0
+}
+
 // GetWorkflowHistory provides a mock function with given fields: ctx, workflowID, runID, isLongPoll, filterType
 func (_m *Client) GetWorkflowHistory(ctx context.Context, workflowID string, runID string, isLongPoll bool, filterType shared.HistoryEventFilterType) client.HistoryEventIterator {
 	ret := _m.Called(ctx, workflowID, runID, isLongPoll, filterType)
@@ -212,6 +343,22 @@ func (_m *Client) ListOpenWorkflow(ctx context.Context, request *shared.ListOpen
 	return r0, r1
 }
 
+// ListWorkflows provides a mock function with given fields: ctx, filter
+func (_m *Client) ListWorkflows(ctx context.Context, filter client.ListWorkflowsFilter) client.WorkflowExecutionIterator {
+	ret := _m.Called(ctx, filter)
+
+	var r0 client.WorkflowExecutionIterator
+	if rf, ok := ret.Get(0).(func(context.Context, client.ListWorkflowsFilter) client.WorkflowExecutionIterator); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.WorkflowExecutionIterator)
+		}
+	}
+
+	return r0
+}
+
 // QueryWorkflow provides a mock function with given fields: ctx, workflowID, runID, queryType, args
 func (_m *Client) QueryWorkflow(ctx context.Context, workflowID string, runID string, queryType string, args ...interface{}) (encoded.Value, error) {
 	var _ca []interface{}
@@ -238,6 +385,29 @@ func (_m *Client) QueryWorkflow(ctx context.Context, workflowID string, runID st
 	return r0, r1
 }
 
+// QueryWorkflowWithOptions provides a mock function with given fields: ctx, request
+func (_m *Client) QueryWorkflowWithOptions(ctx context.Context, request *client.QueryWorkflowWithOptionsRequest) (*client.QueryWorkflowWithOptionsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *client.QueryWorkflowWithOptionsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *client.QueryWorkflowWithOptionsRequest) *client.QueryWorkflowWithOptionsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.QueryWorkflowWithOptionsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *client.QueryWorkflowWithOptionsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RecordActivityHeartbeat provides a mock function with given fields: ctx, taskToken, details
 func (_m *Client) RecordActivityHeartbeat(ctx context.Context, taskToken []byte, details ...interface{}) error {
 	var _ca []interface{}
@@ -315,6 +485,35 @@ func (_m *Client) SignalWithStartWorkflow(ctx context.Context,
 	return r0, r1
 }
 
+// SignalWithStartWorkflowRun provides a mock function with given fields: ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs
+func (_m *Client) SignalWithStartWorkflowRun(ctx context.Context,
+	workflowID string, signalName string, signalArg interface{},
+	options client.StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (client.WorkflowRun, error) {
+
+	var _ca []interface{}
+	_ca = append(_ca, ctx, workflowID, signalName, signalArg, options, workflow)
+	_ca = append(_ca, workflowArgs...)
+	ret := _m.Called(_ca...)
+
+	var r0 client.WorkflowRun
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, interface{}, client.StartWorkflowOptions, interface{}, ...interface{}) client.WorkflowRun); ok {
+		r0 = rf(ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.WorkflowRun)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, interface{}, client.StartWorkflowOptions, interface{}, ...interface{}) error); ok {
+		r1 = rf(ctx, workflowID, signalName, signalArg, options, workflow, workflowArgs...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartWorkflow provides a mock function with given fields: ctx, options, workflow, args
 func (_m *Client) StartWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (*workflow.Execution, error) {
 	var _ca []interface{}
@@ -21,6 +21,7 @@
 // Code generated by mockery v1.0.0
 package mocks
 
+import client "go.uber.org/cadence/client"
 import context "context"
 import mock "github.com/stretchr/testify/mock"
 import shared "go.uber.org/cadence/.gen/go/shared"
@@ -30,6 +31,20 @@ type DomainClient struct {
 	mock.Mock
 }
 
+// Deprecate provides a mock function with given fields: ctx, name
+func (_m *DomainClient) Deprecate(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Describe provides a mock function with given fields: ctx, name
 func (_m *DomainClient) Describe(ctx context.Context, name string) (*shared.DescribeDomainResponse, error) {
 	ret := _m.Called(ctx, name)
@@ -53,6 +68,45 @@ func (_m *DomainClient) Describe(ctx context.Context, name string) (*shared.Desc
 	return r0, r1
 }
 
+// DescribeDomain provides a mock function with given fields: ctx, name
+func (_m *DomainClient) DescribeDomain(ctx context.Context, name string) (*client.DomainDescription, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *client.DomainDescription
+	if rf, ok := ret.Get(0).(func(context.Context, string) *client.DomainDescription); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.DomainDescription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx
+func (_m *DomainClient) List(ctx context.Context) client.DomainIterator {
+	ret := _m.Called(ctx)
+
+	var r0 client.DomainIterator
+	if rf, ok := ret.Get(0).(func(context.Context) client.DomainIterator); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.DomainIterator)
+		}
+	}
+
+	return r0
+}
+
 // Register provides a mock function with given fields: ctx, request
 func (_m *DomainClient) Register(ctx context.Context, request *shared.RegisterDomainRequest) error {
 	ret := _m.Called(ctx, request)
@@ -67,6 +121,20 @@ func (_m *DomainClient) Register(ctx context.Context, request *shared.RegisterDo
 	return r0
 }
 
+// RegisterDomain provides a mock function with given fields: ctx, info, config, replicationConfig
+func (_m *DomainClient) RegisterDomain(ctx context.Context, info client.DomainInfo, config client.DomainConfig, replicationConfig client.DomainReplicationConfig) error {
+	ret := _m.Called(ctx, info, config, replicationConfig)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, client.DomainInfo, client.DomainConfig, client.DomainReplicationConfig) error); ok {
+		r0 = rf(ctx, info, config, replicationConfig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, request
 func (_m *DomainClient) Update(ctx context.Context, request *shared.UpdateDomainRequest) error {
 	ret := _m.Called(ctx)
@@ -80,3 +148,26 @@ func (_m *DomainClient) Update(ctx context.Context, request *shared.UpdateDomain
 
 	return r0
 }
+
+// UpdateDomain provides a mock function with given fields: ctx, name, update
+func (_m *DomainClient) UpdateDomain(ctx context.Context, name string, update *client.DomainUpdate) (*client.DomainDescription, error) {
+	ret := _m.Called(ctx, name, update)
+
+	var r0 *client.DomainDescription
+	if rf, ok := ret.Get(0).(func(context.Context, string, *client.DomainUpdate) *client.DomainDescription); ok {
+		r0 = rf(ctx, name, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.DomainDescription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *client.DomainUpdate) error); ok {
+		r1 = rf(ctx, name, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -35,12 +35,59 @@ type (
 	// Worker represents objects that can be started and stopped.
 	Worker = internal.Worker
 
-	// Options is used to configure a worker instance.
+	// Registry registers workflows and activities with a single worker. The worker returned by New implements it:
+	//  w := worker.New(service, domain, taskList, worker.Options{})
+	//  w.(worker.Registry).RegisterWorkflow(MyWorkflow)
+	// The registrations of a worker replace the global ones instead of adding to them, separately for workflows and
+	// activities: a worker without workflows (activities) of its own runs every workflow (activity) registered with
+	// the global RegisterWorkflow (RegisterActivity), and a worker with at least one only runs its own. A worker which
+	// registers one workflow or activity must therefore register all the workflows or activities it runs, so that
+	// unrelated task lists hosted in one process stay isolated.
+	Registry = internal.Registry
+
+	// Options is used to configure a worker instance. The workflows and activities run by a worker are either
+	// registered with it, see Registry, or registered globally.
 	Options = internal.WorkerOptions
 
 	// NonDeterministicWorkflowPolicy is an enum for configuring how client's decision task handler deals with
 	// mismatched history events (presumably arising from non-deterministic workflow definitions).
 	NonDeterministicWorkflowPolicy = internal.NonDeterministicWorkflowPolicy
+
+	// UnhandledSignalPolicy is an enum for configuring how client's decision task handler deals with signals which
+	// are still buffered in their signal channels when the workflow completes.
+	UnhandledSignalPolicy = internal.UnhandledSignalPolicy
+
+	// Interceptor is used to wrap workflow and activity executions of a worker. See Options.Interceptors.
+	Interceptor = internal.WorkerInterceptor
+
+	// WorkflowInterceptor intercepts the execution of a workflow and the calls it makes to the workflow API.
+	WorkflowInterceptor = internal.WorkflowInterceptor
+
+	// WorkflowInterceptorBase is a WorkflowInterceptor that forwards all calls to Next. Embed it to implement only
+	// the methods of interest.
+	WorkflowInterceptorBase = internal.WorkflowInterceptorBase
+
+	// ActivityInterceptor intercepts the execution of an activity.
+	ActivityInterceptor = internal.ActivityInterceptor
+
+	// ActivityInterceptorBase is an ActivityInterceptor that forwards all calls to Next.
+	ActivityInterceptorBase = internal.ActivityInterceptorBase
+
+	// WorkflowReplayer replays saved workflow histories against the workflow code registered with it. Use it in
+	// regression tests to detect non-deterministic changes to workflow definitions before they are deployed.
+	WorkflowReplayer = internal.WorkflowReplayer
+
+	// WorkflowReplayerOptions is used to configure a WorkflowReplayer.
+	WorkflowReplayerOptions = internal.WorkflowReplayerOptions
+
+	// WorkflowReplayResult describes the outcome of replaying a single workflow history.
+	WorkflowReplayResult = internal.WorkflowReplayResult
+
+	// WorkflowReplayReport holds the results of replaying a set of workflow histories.
+	WorkflowReplayReport = internal.WorkflowReplayReport
+
+	// ReplayHistoryIterator iterates over named workflow histories to be replayed by a WorkflowReplayer.
+	ReplayHistoryIterator = internal.ReplayHistoryIterator
 )
 
 const (
@@ -56,6 +103,14 @@ const (
 	// Whereas default does *NOT* reply anything back to the server, fail workflow replies back with a request
 	// to fail the workflow execution.
 	NonDeterministicWorkflowPolicyFailWorkflow = internal.NonDeterministicWorkflowPolicyFailWorkflow
+
+	// UnhandledSignalPolicyDrop is the default policy for handling unhandled signals. The workflow completes, the
+	// names of the unhandled signals are logged and the UnhandledSignalsCounter metric is incremented.
+	UnhandledSignalPolicyDrop = internal.UnhandledSignalPolicyDrop
+	// UnhandledSignalPolicyFailDecision fails the decision task which completes the workflow instead of dropping the
+	// signals. The workflow stays open and its decision task is retried, which gives the chance to deploy a worker
+	// that handles the signals.
+	UnhandledSignalPolicyFailDecision = internal.UnhandledSignalPolicyFailDecision
 )
 
 // New creates an instance of worker for managing workflow and activity executions.
@@ -73,6 +128,12 @@ func New(
 	return internal.NewWorker(service, domain, taskList, options)
 }
 
+// NewWorkflowReplayer creates a WorkflowReplayer. Workflows and activities that are not registered with the
+// replayer itself are looked up in the global registry.
+func NewWorkflowReplayer(options WorkflowReplayerOptions) *WorkflowReplayer {
+	return internal.NewWorkflowReplayer(options)
+}
+
 // EnableVerboseLogging enable or disable verbose logging of internal Cadence library components.
 // Most customers don't need this feature, unless advised by the Cadence team member.
 // Also there is no guarantee that this API is not going to change.
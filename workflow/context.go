@@ -32,6 +32,16 @@ import (
 // Context's methods may be called by multiple goroutines simultaneously.
 type Context = internal.Context
 
+// ContextPropagator determines what information from context to pass along to workflows and activities.
+// Register the same propagators through client.Options and worker.Options.
+type ContextPropagator = internal.ContextPropagator
+
+// HeaderReader is an interface to read information from cadence headers
+type HeaderReader = internal.HeaderReader
+
+// HeaderWriter is an interface to write information to cadence headers
+type HeaderWriter = internal.HeaderWriter
+
 // ErrCanceled is the error returned by Context.Err when the context is canceled.
 var ErrCanceled = internal.ErrCanceled
 
@@ -75,3 +85,38 @@ func WithValue(parent Context, key interface{}, val interface{}) Context {
 func NewDisconnectedContext(parent Context) (ctx Context, cancel CancelFunc) {
 	return internal.NewDisconnectedContext(parent)
 }
+
+// CancellationScopeOptions configures a CancellationScope.
+type CancellationScopeOptions = internal.CancellationScopeOptions
+
+// CancellationScope groups the activities, local activities, child workflows and timers started with its context,
+// or with a context derived from it, so they can be canceled together.
+// The following code cancels the activities started in the scope when the first one fails, waits for their
+// cancellation and then runs a compensation in a detached scope, which also runs if the workflow itself is canceled:
+//  scope := workflow.NewCancellationScope(ctx, workflow.CancellationScopeOptions{WaitForCancellation: true})
+//  f1 := workflow.ExecuteActivity(scope.Context(), ActivityFoo)
+//  f2 := workflow.ExecuteActivity(scope.Context(), ActivityBar)
+//  err := f1.Get(ctx, nil)
+//  if err == nil {
+//    err = f2.Get(ctx, nil)
+//  }
+//  if err != nil {
+//    scope.Cancel(ctx)
+//    cleanup := workflow.NewDetachedCancellationScope(ctx, workflow.CancellationScopeOptions{})
+//    workflow.ExecuteActivity(cleanup.Context(), CompensateActivity).Get(cleanup.Context(), nil)
+//    return err
+//  }
+type CancellationScope = internal.CancellationScope
+
+// NewCancellationScope returns a CancellationScope whose context is a child of parent. The scope is canceled when
+// its Cancel method is called or when parent is canceled, whichever happens first.
+func NewCancellationScope(parent Context, options CancellationScopeOptions) CancellationScope {
+	return internal.NewCancellationScope(parent, options)
+}
+
+// NewDetachedCancellationScope returns a CancellationScope which, like NewDisconnectedContext, is not canceled when
+// parent is canceled and is not canceled or waited for by the scope of parent. Use it to run cleanup code after the
+// workflow or an enclosing scope is canceled.
+func NewDetachedCancellationScope(parent Context, options CancellationScopeOptions) CancellationScope {
+	return internal.NewDetachedCancellationScope(parent, options)
+}
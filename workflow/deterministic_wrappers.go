@@ -121,3 +121,21 @@ func NewTimer(ctx Context, d time.Duration) Future {
 func Sleep(ctx Context, d time.Duration) (err error) {
 	return internal.Sleep(ctx, d)
 }
+
+// Await blocks the calling coroutine until condition() returns true. The condition is evaluated again every time the
+// workflow makes progress, for example after a signal is received, an activity completes or another coroutine runs,
+// so it may read any state of the workflow. The condition must be deterministic, must not block and must not have
+// side effects.
+// Await returns nil once the condition is true, or it returns *CanceledError if the ctx is canceled first.
+func Await(ctx Context, condition func() bool) error {
+	return internal.Await(ctx, condition)
+}
+
+// AwaitWithTimeout blocks the calling coroutine until condition() returns true or the timeout expires. It has the same
+// semantic as Await, the timeout is a durable timer created with NewTimer which is canceled if the condition becomes
+// true first. No timer is created if the condition is already true when AwaitWithTimeout is called.
+// AwaitWithTimeout returns true if the condition was met and false if the timeout expired. It returns *CanceledError
+// if the ctx is canceled first.
+func AwaitWithTimeout(ctx Context, timeout time.Duration, condition func() bool) (ok bool, err error) {
+	return internal.AwaitWithTimeout(ctx, timeout, condition)
+}
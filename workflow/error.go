@@ -100,6 +100,10 @@ type (
 	// ContinueAsNewError can be returned by a workflow implementation function and indicates that
 	// the workflow should continue as new with the same WorkflowID, but new RunID and new history.
 	ContinueAsNewError = internal.ContinueAsNewError
+
+	// CompensationError returned by Saga.Compensate when compensations failed. It holds the error of every failed
+	// compensation in the order the compensations were executed.
+	CompensationError = internal.CompensationError
 )
 
 // NewContinueAsNewError creates ContinueAsNewError instance
@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package workflow
+
+import "go.uber.org/cadence/internal"
+
+type (
+	// Saga runs compensations for the steps of a workflow which already completed when a later step fails or the
+	// workflow is canceled. Every step registers the activity which compensates it with AddCompensation.
+	// Example:
+	//  func TransferWorkflow(ctx workflow.Context, from, to string, amount int) (err error) {
+	//    ctx = workflow.WithActivityOptions(ctx, activityOptions)
+	//    saga := workflow.NewSaga(workflow.SagaOptions{})
+	//    defer func() {
+	//      if err != nil {
+	//        if compensationErr := saga.Compensate(ctx); compensationErr != nil {
+	//          workflow.GetLogger(ctx).Error("Compensation failed.", zap.Error(compensationErr))
+	//        }
+	//      }
+	//    }()
+	//
+	//    if err = workflow.ExecuteActivity(ctx, Withdraw, from, amount).Get(ctx, nil); err != nil {
+	//      return err
+	//    }
+	//    saga.AddCompensation(Deposit, from, amount)
+	//
+	//    if err = workflow.ExecuteActivity(ctx, Deposit, to, amount).Get(ctx, nil); err != nil {
+	//      return err
+	//    }
+	//    saga.AddCompensation(Withdraw, to, amount)
+	//    return nil
+	//  }
+	Saga = internal.Saga
+
+	// SagaOptions configures a Saga.
+	SagaOptions = internal.SagaOptions
+)
+
+// NewSaga creates a new Saga instance.
+func NewSaga(options SagaOptions) Saga {
+	return internal.NewSaga(options)
+}
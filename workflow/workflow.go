@@ -217,11 +217,37 @@ func SignalExternalWorkflow(ctx Context, workflowID, runID, signalName string, a
 	return internal.SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
 }
 
-// GetSignalChannel returns channel corresponding to the signal name.
+// GetSignalChannel returns channel corresponding to the signal name. Receiving from the channel yields the signal
+// argument only, use SetSignalHandler to receive the context propagated along with a signal.
 func GetSignalChannel(ctx Context, signalName string) Channel {
 	return internal.GetSignalChannel(ctx, signalName)
 }
 
+// SetSignalHandler sets the handler which is called for every signal with the given name. The handler must be a
+// function which accepts a workflow.Context and optionally one serializable signal argument, and returns nothing:
+//	func(ctx workflow.Context)
+//	func(ctx workflow.Context, arg MySignalArg)
+// Every signal is handled in its own coroutine, started in the order the signals are received, so a handler may
+// block, for example to execute an activity. Signals received before the handler is set are handled first. A signal
+// whose argument cannot be decoded is logged and dropped. The header sent along with a signal is extracted by the
+// ContextPropagators of the worker into the context passed to the handler.
+// Once the handler is set the signals are no longer sent to the signal channel of signalName. SetSignalHandler
+// returns an error if the handler is not a valid function or if a handler is already set for signalName.
+// Example:
+//  func MyWorkflow(ctx workflow.Context) error {
+//    var approvals []string
+//    err := workflow.SetSignalHandler(ctx, "approve", func(ctx workflow.Context, approver string) {
+//      approvals = append(approvals, approver)
+//    })
+//    if err != nil {
+//      return err
+//    }
+//    return workflow.Await(ctx, func() bool { return len(approvals) >= 2 })
+//  }
+func SetSignalHandler(ctx Context, signalName string, handler interface{}) error {
+	return internal.SetSignalHandler(ctx, signalName, handler)
+}
+
 // SideEffect executes the provided function once, records its result into the workflow history. The recorded result on
 // history will be returned without executing the provided function during replay. This guarantees the deterministic
 // requirement for workflow as the exact same result will be returned in replay.
@@ -354,7 +380,10 @@ func GetVersion(ctx Context, changeID string, minSupported, maxSupported Version
 // The query handler will be invoked out of the context of the workflow, meaning that the handler code must not use workflow
 // context to do things like workflow.NewChannel(), workflow.Go() or to call any workflow blocking functions like
 // Channel.Get() or Future.Get(). Trying to do so in query handler code will fail the query and client will receive
-// QueryFailedError.
+// QueryFailedError. The query handler must not change the state of the workflow either, calls like ExecuteActivity(),
+// NewTimer(), SideEffect() or Channel.Send() panic while a query handler runs and fail the query.
+// The query types a workflow supports and the argument types of their handlers are returned by the built-in
+// client.QueryTypeQueryTypes query.
 // Example of workflow code that support query type "current_state":
 //  func MyWorkflow(ctx workflow.Context, input string) error {
 //    currentState := "started" // this could be any serializable struct
@@ -387,6 +416,12 @@ func SetQueryHandler(ctx Context, queryType string, handler interface{}) error {
 	return internal.SetQueryHandler(ctx, queryType, handler)
 }
 
+// RemoveQueryHandler removes the query handler set for queryType with SetQueryHandler. Queries of queryType fail
+// afterwards as unknown query types. Removing a query type which has no handler does nothing.
+func RemoveQueryHandler(ctx Context, queryType string) {
+	internal.RemoveQueryHandler(ctx, queryType)
+}
+
 // IsReplaying returns whether the current workflow code is replaying.
 //
 // Warning! Never make decisions, like schedule activity/childWorkflow/timer or send/wait on future/channel, based on